@@ -7,8 +7,10 @@ package api
 
 import (
 	"context"
+	"time"
 
 	proto "github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 )
 
 //go:generate protoc --go_out=plugins=grpc:../api -I ../proto/ ../proto/auth.proto ../proto/request.proto ../proto/router.proto ../proto/spa.proto
@@ -28,6 +30,24 @@ func AuthFromContext(ctx context.Context) (rs *RequestAuthResp, found bool) {
 	return rs, found
 }
 
+// Elevated reports if a granted session re-authenticated recently enough
+// to use sensitive resources at the given time.
+func (rs *RequestAuthResp) Elevated(now time.Time) bool {
+	if rs == nil || rs.LoginState != LoginState_Granted || rs.ElevatedUntil == nil {
+		return false
+	}
+	until, err := ptypes.Timestamp(rs.ElevatedUntil)
+	if err != nil {
+		return false
+	}
+	return now.Before(until)
+}
+
+// Elevated reports if the request is from an elevated session.
+func (r *HTTPRequest) Elevated() bool {
+	return r.Auth.Elevated(time.Now())
+}
+
 var (
 	fetchUIActionMissingBytes = []byte("missing")
 	fetchUIActionExecuteBytes = []byte("execute")
@@ -18,13 +18,22 @@ It has these top-level messages:
 	LoginResp
 	LogoutReq
 	LogoutResp
+	AdminCaller
 	NewPasswordReq
 	NewPasswordResp
 	ChangePasswordReq
 	ChangePasswordResp
+	ElevateReq
+	ElevateResp
+	ImpersonateReq
+	ImpersonateResp
+	InvalidationsReq
+	Invalidation
 	ConfigureURL
 	HTTPRequest
 	HTTPResponse
+	HTTPStreamRequest
+	HTTPStreamResponse
 	URL
 	StringList
 	KeyValueList
@@ -32,10 +41,25 @@ It has these top-level messages:
 	NotifyReq
 	UpdateReq
 	UpdateResp
+	RotateTokenKeyReq
+	RotateTokenKeyResp
+	Binding
+	StatusReq
+	StatusResp
+	StatusService
+	StatusApp
+	StatusLoginBundle
+	StatusURL
+	StatusError
+	ResourcesReq
+	ResourcesResp
+	StatusResource
 	ServiceConfigEndpoint
 	ServiceConfig
 	Resource
+	Role
 	LoginBundle
+	ApplicationTLS
 	ApplicationBundle
 	ServiceBundle
 	FetchUIRequest
@@ -74,6 +98,10 @@ const (
 	LoginState_Granted        LoginState = 3
 	LoginState_U2F            LoginState = 4
 	LoginState_ChangePassword LoginState = 5
+	// Elevate is never the state of a session. The login bundle for it
+	// is used by granted sessions that must re-authenticate to use a
+	// sensitive resource.
+	LoginState_Elevate LoginState = 6
 )
 
 var LoginState_name = map[int32]string{
@@ -83,6 +111,7 @@ var LoginState_name = map[int32]string{
 	3: "Granted",
 	4: "U2F",
 	5: "ChangePassword",
+	6: "Elevate",
 }
 var LoginState_value = map[string]int32{
 	"Missing":        0,
@@ -91,6 +120,7 @@ var LoginState_value = map[string]int32{
 	"Granted":        3,
 	"U2F":            4,
 	"ChangePassword": 5,
+	"Elevate":        6,
 }
 
 func (x LoginState) String() string {
@@ -127,6 +157,9 @@ type ConfigureAuth struct {
 	// Environment name.
 	//   When AreaType=System, "QA" or "PROD".
 	// /  When AreaType=User, "user-1" or "bobsmith".
+	//
+	// Users, role grants, and sessions are kept apart for each area and
+	// environment. A User area environment is the identity of its owner.
 	Environment string `protobuf:"bytes,2,opt,name=Environment" json:"Environment,omitempty"`
 }
 
@@ -160,7 +193,9 @@ type RequestAuthResp struct {
 	FamilyName    string                     `protobuf:"bytes,8,opt,name=FamilyName" json:"FamilyName,omitempty"`
 	Email         string                     `protobuf:"bytes,9,opt,name=Email" json:"Email,omitempty"`
 	TokenKey      string                     `protobuf:"bytes,10,opt,name=TokenKey" json:"TokenKey,omitempty"`
-	Secondary     *RequestAuthResp           `protobuf:"bytes,11,opt,name=Secondary" json:"Secondary,omitempty"`
+	// Secondary is the user signed in to the session while the session
+	// impersonates the user above. Nil if not impersonating.
+	Secondary *RequestAuthResp `protobuf:"bytes,11,opt,name=Secondary" json:"Secondary,omitempty"`
 }
 
 func (m *RequestAuthResp) Reset()                    { *m = RequestAuthResp{} }
@@ -248,6 +283,14 @@ func (m *RequestAuthResp) GetSecondary() *RequestAuthResp {
 type RequestAuthReq struct {
 	Token         string         `protobuf:"bytes,1,opt,name=Token" json:"Token,omitempty"`
 	Configuration *ConfigureAuth `protobuf:"bytes,2,opt,name=Configuration" json:"Configuration,omitempty"`
+	// RemoteAddr is the client address, "host:port".
+	RemoteAddr string `protobuf:"bytes,3,opt,name=RemoteAddr" json:"RemoteAddr,omitempty"`
+	// Secure is true if the client connected with TLS.
+	Secure bool `protobuf:"varint,4,opt,name=Secure" json:"Secure,omitempty"`
+	// Method and URL of the request. Recorded in the audit log while the
+	// session impersonates a user.
+	Method string `protobuf:"bytes,5,opt,name=Method" json:"Method,omitempty"`
+	URL    string `protobuf:"bytes,6,opt,name=URL" json:"URL,omitempty"`
 }
 
 func (m *RequestAuthReq) Reset()                    { *m = RequestAuthReq{} }
@@ -269,9 +312,45 @@ func (m *RequestAuthReq) GetConfiguration() *ConfigureAuth {
 	return nil
 }
 
+func (m *RequestAuthReq) GetRemoteAddr() string {
+	if m != nil {
+		return m.RemoteAddr
+	}
+	return ""
+}
+
+func (m *RequestAuthReq) GetSecure() bool {
+	if m != nil {
+		return m.Secure
+	}
+	return false
+}
+
+func (m *RequestAuthReq) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *RequestAuthReq) GetURL() string {
+	if m != nil {
+		return m.URL
+	}
+	return ""
+}
+
 type LoginReq struct {
 	Identity string `protobuf:"bytes,1,opt,name=Identity" json:"Identity,omitempty"`
 	Password string `protobuf:"bytes,2,opt,name=Password" json:"Password,omitempty"`
+	// RemoteAddr is the client address, "host:port". Failed logins
+	// are throttled per identity and per client address.
+	RemoteAddr string `protobuf:"bytes,3,opt,name=RemoteAddr" json:"RemoteAddr,omitempty"`
+	// Secure is true if the client connected with TLS.
+	Secure bool `protobuf:"varint,4,opt,name=Secure" json:"Secure,omitempty"`
+	// Configuration of the application. The session may only be used
+	// by applications in the same area and environment.
+	Configuration *ConfigureAuth `protobuf:"bytes,5,opt,name=Configuration" json:"Configuration,omitempty"`
 }
 
 func (m *LoginReq) Reset()                    { *m = LoginReq{} }
@@ -293,6 +372,27 @@ func (m *LoginReq) GetPassword() string {
 	return ""
 }
 
+func (m *LoginReq) GetRemoteAddr() string {
+	if m != nil {
+		return m.RemoteAddr
+	}
+	return ""
+}
+
+func (m *LoginReq) GetSecure() bool {
+	if m != nil {
+		return m.Secure
+	}
+	return false
+}
+
+func (m *LoginReq) GetConfiguration() *ConfigureAuth {
+	if m != nil {
+		return m.Configuration
+	}
+	return nil
+}
+
 type LoginResp struct {
 	SessionTokenValue string `protobuf:"bytes,1,opt,name=SessionTokenValue" json:"SessionTokenValue,omitempty"`
 }
@@ -314,6 +414,8 @@ type LogoutReq struct {
 	//	*LogoutReq_SessionTokenValue
 	//	*LogoutReq_Identity
 	Value isLogoutReq_Value `protobuf_oneof:"Value"`
+	// Admin is the caller ending all sessions of Identity.
+	Admin *AdminCaller `protobuf:"bytes,3,opt,name=Admin" json:"Admin,omitempty"`
 }
 
 func (m *LogoutReq) Reset()                    { *m = LogoutReq{} }
@@ -354,6 +456,13 @@ func (m *LogoutReq) GetIdentity() string {
 	return ""
 }
 
+func (m *LogoutReq) GetAdmin() *AdminCaller {
+	if m != nil {
+		return m.Admin
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*LogoutReq) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _LogoutReq_OneofMarshaler, _LogoutReq_OneofUnmarshaler, _LogoutReq_OneofSizer, []interface{}{
@@ -428,14 +537,61 @@ func (m *LogoutResp) String() string            { return proto.CompactTextString
 func (*LogoutResp) ProtoMessage()               {}
 func (*LogoutResp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
 
+// AdminCaller is the session of a user managing other users. The user
+// must have the admin role of the authenticator. Each call is audited.
+type AdminCaller struct {
+	// SessionTokenValue must be a granted session.
+	SessionTokenValue string `protobuf:"bytes,1,opt,name=SessionTokenValue" json:"SessionTokenValue,omitempty"`
+	// RemoteAddr is the client address, "host:port".
+	RemoteAddr string `protobuf:"bytes,2,opt,name=RemoteAddr" json:"RemoteAddr,omitempty"`
+	// Secure is true if the client connected with TLS.
+	Secure bool `protobuf:"varint,3,opt,name=Secure" json:"Secure,omitempty"`
+	// Configuration of the application the session is used with.
+	Configuration *ConfigureAuth `protobuf:"bytes,4,opt,name=Configuration" json:"Configuration,omitempty"`
+}
+
+func (m *AdminCaller) Reset()                    { *m = AdminCaller{} }
+func (m *AdminCaller) String() string            { return proto.CompactTextString(m) }
+func (*AdminCaller) ProtoMessage()               {}
+func (*AdminCaller) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *AdminCaller) GetSessionTokenValue() string {
+	if m != nil {
+		return m.SessionTokenValue
+	}
+	return ""
+}
+
+func (m *AdminCaller) GetRemoteAddr() string {
+	if m != nil {
+		return m.RemoteAddr
+	}
+	return ""
+}
+
+func (m *AdminCaller) GetSecure() bool {
+	if m != nil {
+		return m.Secure
+	}
+	return false
+}
+
+func (m *AdminCaller) GetConfiguration() *ConfigureAuth {
+	if m != nil {
+		return m.Configuration
+	}
+	return nil
+}
+
 type NewPasswordReq struct {
-	Identity string `protobuf:"bytes,1,opt,name=Identity" json:"Identity,omitempty"`
+	Identity string       `protobuf:"bytes,1,opt,name=Identity" json:"Identity,omitempty"`
+	Admin    *AdminCaller `protobuf:"bytes,2,opt,name=Admin" json:"Admin,omitempty"`
 }
 
 func (m *NewPasswordReq) Reset()                    { *m = NewPasswordReq{} }
 func (m *NewPasswordReq) String() string            { return proto.CompactTextString(m) }
 func (*NewPasswordReq) ProtoMessage()               {}
-func (*NewPasswordReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+func (*NewPasswordReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
 
 func (m *NewPasswordReq) GetIdentity() string {
 	if m != nil {
@@ -444,13 +600,20 @@ func (m *NewPasswordReq) GetIdentity() string {
 	return ""
 }
 
+func (m *NewPasswordReq) GetAdmin() *AdminCaller {
+	if m != nil {
+		return m.Admin
+	}
+	return nil
+}
+
 type NewPasswordResp struct {
 }
 
 func (m *NewPasswordResp) Reset()                    { *m = NewPasswordResp{} }
 func (m *NewPasswordResp) String() string            { return proto.CompactTextString(m) }
 func (*NewPasswordResp) ProtoMessage()               {}
-func (*NewPasswordResp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+func (*NewPasswordResp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
 
 type ChangePasswordReq struct {
 	// SessionTokenValue must be valid for the password to be changed.
@@ -461,12 +624,18 @@ type ChangePasswordReq struct {
 	CurrentPassword string `protobuf:"bytes,2,opt,name=CurrentPassword" json:"CurrentPassword,omitempty"`
 	// NewPassword to set. If the password is too weak it may be rejected.
 	NewPassword string `protobuf:"bytes,3,opt,name=NewPassword" json:"NewPassword,omitempty"`
+	// RemoteAddr is the client address, "host:port".
+	RemoteAddr string `protobuf:"bytes,4,opt,name=RemoteAddr" json:"RemoteAddr,omitempty"`
+	// Secure is true if the client connected with TLS.
+	Secure bool `protobuf:"varint,5,opt,name=Secure" json:"Secure,omitempty"`
+	// Configuration of the application the session is used with.
+	Configuration *ConfigureAuth `protobuf:"bytes,6,opt,name=Configuration" json:"Configuration,omitempty"`
 }
 
 func (m *ChangePasswordReq) Reset()                    { *m = ChangePasswordReq{} }
 func (m *ChangePasswordReq) String() string            { return proto.CompactTextString(m) }
 func (*ChangePasswordReq) ProtoMessage()               {}
-func (*ChangePasswordReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+func (*ChangePasswordReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
 
 func (m *ChangePasswordReq) GetSessionTokenValue() string {
 	if m != nil {
@@ -489,19 +658,40 @@ func (m *ChangePasswordReq) GetNewPassword() string {
 	return ""
 }
 
+func (m *ChangePasswordReq) GetRemoteAddr() string {
+	if m != nil {
+		return m.RemoteAddr
+	}
+	return ""
+}
+
+func (m *ChangePasswordReq) GetSecure() bool {
+	if m != nil {
+		return m.Secure
+	}
+	return false
+}
+
+func (m *ChangePasswordReq) GetConfiguration() *ConfigureAuth {
+	if m != nil {
+		return m.Configuration
+	}
+	return nil
+}
+
 type ChangePasswordResp struct {
 	// Changed is true when the password was changed.
 	// If false the InvalidNewPasswordMessage text should
 	// be displayed to the user.
 	Changed bool `protobuf:"varint,1,opt,name=Changed" json:"Changed,omitempty"`
-	// InvalidNewPasswordMessage is is set when Changed is true.
+	// InvalidNewPasswordMessage is set when Changed is false.
 	InvalidNewPasswordMessage string `protobuf:"bytes,2,opt,name=InvalidNewPasswordMessage" json:"InvalidNewPasswordMessage,omitempty"`
 }
 
 func (m *ChangePasswordResp) Reset()                    { *m = ChangePasswordResp{} }
 func (m *ChangePasswordResp) String() string            { return proto.CompactTextString(m) }
 func (*ChangePasswordResp) ProtoMessage()               {}
-func (*ChangePasswordResp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+func (*ChangePasswordResp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
 
 func (m *ChangePasswordResp) GetChanged() bool {
 	if m != nil {
@@ -517,6 +707,178 @@ func (m *ChangePasswordResp) GetInvalidNewPasswordMessage() string {
 	return ""
 }
 
+type ElevateReq struct {
+	// SessionTokenValue must be a granted session.
+	SessionTokenValue string `protobuf:"bytes,1,opt,name=SessionTokenValue" json:"SessionTokenValue,omitempty"`
+	Password          string `protobuf:"bytes,2,opt,name=Password" json:"Password,omitempty"`
+	// RemoteAddr is the client address, "host:port".
+	RemoteAddr string `protobuf:"bytes,3,opt,name=RemoteAddr" json:"RemoteAddr,omitempty"`
+	// Secure is true if the client connected with TLS.
+	Secure bool `protobuf:"varint,4,opt,name=Secure" json:"Secure,omitempty"`
+	// Configuration of the application the session is used with.
+	Configuration *ConfigureAuth `protobuf:"bytes,5,opt,name=Configuration" json:"Configuration,omitempty"`
+}
+
+func (m *ElevateReq) Reset()                    { *m = ElevateReq{} }
+func (m *ElevateReq) String() string            { return proto.CompactTextString(m) }
+func (*ElevateReq) ProtoMessage()               {}
+func (*ElevateReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *ElevateReq) GetSessionTokenValue() string {
+	if m != nil {
+		return m.SessionTokenValue
+	}
+	return ""
+}
+
+func (m *ElevateReq) GetPassword() string {
+	if m != nil {
+		return m.Password
+	}
+	return ""
+}
+
+func (m *ElevateReq) GetRemoteAddr() string {
+	if m != nil {
+		return m.RemoteAddr
+	}
+	return ""
+}
+
+func (m *ElevateReq) GetSecure() bool {
+	if m != nil {
+		return m.Secure
+	}
+	return false
+}
+
+func (m *ElevateReq) GetConfiguration() *ConfigureAuth {
+	if m != nil {
+		return m.Configuration
+	}
+	return nil
+}
+
+type ElevateResp struct {
+	ElevatedUntil *google_protobuf.Timestamp `protobuf:"bytes,1,opt,name=ElevatedUntil" json:"ElevatedUntil,omitempty"`
+}
+
+func (m *ElevateResp) Reset()                    { *m = ElevateResp{} }
+func (m *ElevateResp) String() string            { return proto.CompactTextString(m) }
+func (*ElevateResp) ProtoMessage()               {}
+func (*ElevateResp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *ElevateResp) GetElevatedUntil() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.ElevatedUntil
+	}
+	return nil
+}
+
+type ImpersonateReq struct {
+	// SessionTokenValue must be a granted session.
+	SessionTokenValue string `protobuf:"bytes,1,opt,name=SessionTokenValue" json:"SessionTokenValue,omitempty"`
+	// Identity to act as. Empty ends impersonation.
+	Identity string `protobuf:"bytes,2,opt,name=Identity" json:"Identity,omitempty"`
+	// RemoteAddr is the client address, "host:port".
+	RemoteAddr string `protobuf:"bytes,3,opt,name=RemoteAddr" json:"RemoteAddr,omitempty"`
+	// Secure is true if the client connected with TLS.
+	Secure bool `protobuf:"varint,4,opt,name=Secure" json:"Secure,omitempty"`
+	// Configuration of the application the session is used with.
+	Configuration *ConfigureAuth `protobuf:"bytes,5,opt,name=Configuration" json:"Configuration,omitempty"`
+}
+
+func (m *ImpersonateReq) Reset()                    { *m = ImpersonateReq{} }
+func (m *ImpersonateReq) String() string            { return proto.CompactTextString(m) }
+func (*ImpersonateReq) ProtoMessage()               {}
+func (*ImpersonateReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *ImpersonateReq) GetSessionTokenValue() string {
+	if m != nil {
+		return m.SessionTokenValue
+	}
+	return ""
+}
+
+func (m *ImpersonateReq) GetIdentity() string {
+	if m != nil {
+		return m.Identity
+	}
+	return ""
+}
+
+func (m *ImpersonateReq) GetRemoteAddr() string {
+	if m != nil {
+		return m.RemoteAddr
+	}
+	return ""
+}
+
+func (m *ImpersonateReq) GetSecure() bool {
+	if m != nil {
+		return m.Secure
+	}
+	return false
+}
+
+func (m *ImpersonateReq) GetConfiguration() *ConfigureAuth {
+	if m != nil {
+		return m.Configuration
+	}
+	return nil
+}
+
+type ImpersonateResp struct {
+}
+
+func (m *ImpersonateResp) Reset()                    { *m = ImpersonateResp{} }
+func (m *ImpersonateResp) String() string            { return proto.CompactTextString(m) }
+func (*ImpersonateResp) ProtoMessage()               {}
+func (*ImpersonateResp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+type InvalidationsReq struct {
+}
+
+func (m *InvalidationsReq) Reset()                    { *m = InvalidationsReq{} }
+func (m *InvalidationsReq) String() string            { return proto.CompactTextString(m) }
+func (*InvalidationsReq) ProtoMessage()               {}
+func (*InvalidationsReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+type Invalidation struct {
+	// Token values of sessions that ended or changed.
+	Token []string `protobuf:"bytes,1,rep,name=Token" json:"Token,omitempty"`
+	// Identity of users whose sessions ended or changed.
+	Identity []string `protobuf:"bytes,2,rep,name=Identity" json:"Identity,omitempty"`
+	// All cached results are invalid.
+	All bool `protobuf:"varint,3,opt,name=All" json:"All,omitempty"`
+}
+
+func (m *Invalidation) Reset()                    { *m = Invalidation{} }
+func (m *Invalidation) String() string            { return proto.CompactTextString(m) }
+func (*Invalidation) ProtoMessage()               {}
+func (*Invalidation) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *Invalidation) GetToken() []string {
+	if m != nil {
+		return m.Token
+	}
+	return nil
+}
+
+func (m *Invalidation) GetIdentity() []string {
+	if m != nil {
+		return m.Identity
+	}
+	return nil
+}
+
+func (m *Invalidation) GetAll() bool {
+	if m != nil {
+		return m.All
+	}
+	return false
+}
+
 func init() {
 	proto.RegisterType((*ConfigureAuth)(nil), "api.ConfigureAuth")
 	proto.RegisterType((*RequestAuthResp)(nil), "api.RequestAuthResp")
@@ -525,10 +887,17 @@ func init() {
 	proto.RegisterType((*LoginResp)(nil), "api.LoginResp")
 	proto.RegisterType((*LogoutReq)(nil), "api.LogoutReq")
 	proto.RegisterType((*LogoutResp)(nil), "api.LogoutResp")
+	proto.RegisterType((*AdminCaller)(nil), "api.AdminCaller")
 	proto.RegisterType((*NewPasswordReq)(nil), "api.NewPasswordReq")
 	proto.RegisterType((*NewPasswordResp)(nil), "api.NewPasswordResp")
 	proto.RegisterType((*ChangePasswordReq)(nil), "api.ChangePasswordReq")
 	proto.RegisterType((*ChangePasswordResp)(nil), "api.ChangePasswordResp")
+	proto.RegisterType((*ElevateReq)(nil), "api.ElevateReq")
+	proto.RegisterType((*ElevateResp)(nil), "api.ElevateResp")
+	proto.RegisterType((*ImpersonateReq)(nil), "api.ImpersonateReq")
+	proto.RegisterType((*ImpersonateResp)(nil), "api.ImpersonateResp")
+	proto.RegisterType((*InvalidationsReq)(nil), "api.InvalidationsReq")
+	proto.RegisterType((*Invalidation)(nil), "api.Invalidation")
 	proto.RegisterEnum("api.LoginState", LoginState_name, LoginState_value)
 	proto.RegisterEnum("api.ConfigureAuth_AreaType", ConfigureAuth_AreaType_name, ConfigureAuth_AreaType_value)
 }
@@ -544,9 +913,34 @@ const _ = grpc.SupportPackageIsVersion4
 // Client API for Auth service
 
 type AuthClient interface {
-	// TODO: RequestAuth and Login should both take some additional features
-	// about where the request is coming from (HTTPS info, remote address).
 	RequestAuth(ctx context.Context, in *RequestAuthReq, opts ...grpc.CallOption) (*RequestAuthResp, error)
+	// Login checks the identity and password and returns a new session.
+	// A failed login returns a PermissionDenied error.
+	Login(ctx context.Context, in *LoginReq, opts ...grpc.CallOption) (*LoginResp, error)
+	// Logout ends a single session or all sessions of an identity.
+	// Ending the sessions of an identity requires an Admin caller.
+	Logout(ctx context.Context, in *LogoutReq, opts ...grpc.CallOption) (*LogoutResp, error)
+	// NewPassword chooses a new password for the given identity.
+	// The identity must be notified of this change. It requires an
+	// Admin caller; other callers receive a PermissionDenied error.
+	NewPassword(ctx context.Context, in *NewPasswordReq, opts ...grpc.CallOption) (*NewPasswordResp, error)
+	// ChangePassword updates the clients password. It should be used
+	// when the user is updating the password themselves.
+	// A wrong current password returns a PermissionDenied error.
+	ChangePassword(ctx context.Context, in *ChangePasswordReq, opts ...grpc.CallOption) (*ChangePasswordResp, error)
+	// Elevate checks the password of a granted session again and allows
+	// the session to use sensitive resources for a short time.
+	// A failed check returns a PermissionDenied error.
+	Elevate(ctx context.Context, in *ElevateReq, opts ...grpc.CallOption) (*ElevateResp, error)
+	// Impersonate lets a granted session act as another user until it is
+	// called again with an empty identity. The session user must have the
+	// impersonation role. Requests made while impersonating are audited.
+	Impersonate(ctx context.Context, in *ImpersonateReq, opts ...grpc.CallOption) (*ImpersonateResp, error)
+	// Invalidations streams sessions that ended or changed so clients
+	// caching RequestAuth results can drop them. The stream starts with an
+	// invalidation of All. Clients should not cache results while the
+	// stream is not connected.
+	Invalidations(ctx context.Context, in *InvalidationsReq, opts ...grpc.CallOption) (Auth_InvalidationsClient, error)
 }
 
 type authClient struct {
@@ -566,12 +960,123 @@ func (c *authClient) RequestAuth(ctx context.Context, in *RequestAuthReq, opts .
 	return out, nil
 }
 
+func (c *authClient) Login(ctx context.Context, in *LoginReq, opts ...grpc.CallOption) (*LoginResp, error) {
+	out := new(LoginResp)
+	err := grpc.Invoke(ctx, "/api.Auth/Login", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authClient) Logout(ctx context.Context, in *LogoutReq, opts ...grpc.CallOption) (*LogoutResp, error) {
+	out := new(LogoutResp)
+	err := grpc.Invoke(ctx, "/api.Auth/Logout", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authClient) NewPassword(ctx context.Context, in *NewPasswordReq, opts ...grpc.CallOption) (*NewPasswordResp, error) {
+	out := new(NewPasswordResp)
+	err := grpc.Invoke(ctx, "/api.Auth/NewPassword", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authClient) ChangePassword(ctx context.Context, in *ChangePasswordReq, opts ...grpc.CallOption) (*ChangePasswordResp, error) {
+	out := new(ChangePasswordResp)
+	err := grpc.Invoke(ctx, "/api.Auth/ChangePassword", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authClient) Elevate(ctx context.Context, in *ElevateReq, opts ...grpc.CallOption) (*ElevateResp, error) {
+	out := new(ElevateResp)
+	err := grpc.Invoke(ctx, "/api.Auth/Elevate", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authClient) Impersonate(ctx context.Context, in *ImpersonateReq, opts ...grpc.CallOption) (*ImpersonateResp, error) {
+	out := new(ImpersonateResp)
+	err := grpc.Invoke(ctx, "/api.Auth/Impersonate", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authClient) Invalidations(ctx context.Context, in *InvalidationsReq, opts ...grpc.CallOption) (Auth_InvalidationsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Auth_serviceDesc.Streams[0], c.cc, "/api.Auth/Invalidations", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &authInvalidationsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Auth_InvalidationsClient interface {
+	Recv() (*Invalidation, error)
+	grpc.ClientStream
+}
+
+type authInvalidationsClient struct {
+	grpc.ClientStream
+}
+
+func (x *authInvalidationsClient) Recv() (*Invalidation, error) {
+	m := new(Invalidation)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for Auth service
 
 type AuthServer interface {
-	// TODO: RequestAuth and Login should both take some additional features
-	// about where the request is coming from (HTTPS info, remote address).
 	RequestAuth(context.Context, *RequestAuthReq) (*RequestAuthResp, error)
+	// Login checks the identity and password and returns a new session.
+	// A failed login returns a PermissionDenied error.
+	Login(context.Context, *LoginReq) (*LoginResp, error)
+	// Logout ends a single session or all sessions of an identity.
+	// Ending the sessions of an identity requires an Admin caller.
+	Logout(context.Context, *LogoutReq) (*LogoutResp, error)
+	// NewPassword chooses a new password for the given identity.
+	// The identity must be notified of this change. It requires an
+	// Admin caller; other callers receive a PermissionDenied error.
+	NewPassword(context.Context, *NewPasswordReq) (*NewPasswordResp, error)
+	// ChangePassword updates the clients password. It should be used
+	// when the user is updating the password themselves.
+	// A wrong current password returns a PermissionDenied error.
+	ChangePassword(context.Context, *ChangePasswordReq) (*ChangePasswordResp, error)
+	// Elevate checks the password of a granted session again and allows
+	// the session to use sensitive resources for a short time.
+	// A failed check returns a PermissionDenied error.
+	Elevate(context.Context, *ElevateReq) (*ElevateResp, error)
+	// Impersonate lets a granted session act as another user until it is
+	// called again with an empty identity. The session user must have the
+	// impersonation role. Requests made while impersonating are audited.
+	Impersonate(context.Context, *ImpersonateReq) (*ImpersonateResp, error)
+	// Invalidations streams sessions that ended or changed so clients
+	// caching RequestAuth results can drop them. The stream starts with an
+	// invalidation of All. Clients should not cache results while the
+	// stream is not connected.
+	Invalidations(*InvalidationsReq, Auth_InvalidationsServer) error
 }
 
 func RegisterAuthServer(s *grpc.Server, srv AuthServer) {
@@ -596,6 +1101,135 @@ func _Auth_RequestAuth_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Auth_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).Login(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Auth/Login",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).Login(ctx, req.(*LoginReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_Logout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogoutReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).Logout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Auth/Logout",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).Logout(ctx, req.(*LogoutReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_NewPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NewPasswordReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).NewPassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Auth/NewPassword",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).NewPassword(ctx, req.(*NewPasswordReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_ChangePassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangePasswordReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).ChangePassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Auth/ChangePassword",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).ChangePassword(ctx, req.(*ChangePasswordReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_Elevate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ElevateReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).Elevate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Auth/Elevate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).Elevate(ctx, req.(*ElevateReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_Impersonate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImpersonateReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).Impersonate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Auth/Impersonate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).Impersonate(ctx, req.(*ImpersonateReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_Invalidations_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(InvalidationsReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AuthServer).Invalidations(m, &authInvalidationsServer{stream})
+}
+
+type Auth_InvalidationsServer interface {
+	Send(*Invalidation) error
+	grpc.ServerStream
+}
+
+type authInvalidationsServer struct {
+	grpc.ServerStream
+}
+
+func (x *authInvalidationsServer) Send(m *Invalidation) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Auth_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "api.Auth",
 	HandlerType: (*AuthServer)(nil),
@@ -604,55 +1238,106 @@ var _Auth_serviceDesc = grpc.ServiceDesc{
 			MethodName: "RequestAuth",
 			Handler:    _Auth_RequestAuth_Handler,
 		},
+		{
+			MethodName: "Login",
+			Handler:    _Auth_Login_Handler,
+		},
+		{
+			MethodName: "Logout",
+			Handler:    _Auth_Logout_Handler,
+		},
+		{
+			MethodName: "NewPassword",
+			Handler:    _Auth_NewPassword_Handler,
+		},
+		{
+			MethodName: "ChangePassword",
+			Handler:    _Auth_ChangePassword_Handler,
+		},
+		{
+			MethodName: "Elevate",
+			Handler:    _Auth_Elevate_Handler,
+		},
+		{
+			MethodName: "Impersonate",
+			Handler:    _Auth_Impersonate_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Invalidations",
+			Handler:       _Auth_Invalidations_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "auth.proto",
 }
 
 func init() { proto.RegisterFile("auth.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 670 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xcd, 0x4e, 0xdb, 0x40,
-	0x10, 0xc6, 0x71, 0x42, 0x92, 0x49, 0x49, 0xcc, 0x96, 0x83, 0x9b, 0xa2, 0x36, 0xf2, 0x29, 0xaa,
-	0xa8, 0x23, 0xa5, 0x17, 0x5a, 0xf5, 0xc0, 0x5f, 0xa0, 0x51, 0x0b, 0xaa, 0x1c, 0x82, 0x7a, 0xec,
-	0xd2, 0x0c, 0x66, 0x85, 0xbd, 0x6b, 0xbc, 0xeb, 0xa0, 0x3c, 0x44, 0x0f, 0x7d, 0x89, 0x3e, 0x67,
-	0xe5, 0xb5, 0x43, 0x9c, 0x00, 0x42, 0x3d, 0xce, 0x37, 0xdf, 0xcc, 0x7c, 0x3b, 0x3f, 0x0b, 0x40,
-	0x13, 0x75, 0xed, 0x46, 0xb1, 0x50, 0x82, 0x98, 0x34, 0x62, 0xed, 0xb7, 0xbe, 0x10, 0x7e, 0x80,
-	0x3d, 0x0d, 0x5d, 0x26, 0x57, 0x3d, 0xc5, 0x42, 0x94, 0x8a, 0x86, 0x51, 0xc6, 0x72, 0xfe, 0x18,
-	0xb0, 0x71, 0x28, 0xf8, 0x15, 0xf3, 0x93, 0x18, 0xf7, 0x13, 0x75, 0x4d, 0x7a, 0x50, 0xde, 0x8f,
-	0x91, 0xda, 0x46, 0xc7, 0xe8, 0x36, 0xfb, 0xaf, 0x5d, 0x1a, 0x31, 0x77, 0x89, 0xe1, 0xa6, 0xee,
-	0xf3, 0x59, 0x84, 0x9e, 0x26, 0x92, 0x0e, 0x34, 0x06, 0x7c, 0xca, 0x62, 0xc1, 0x43, 0xe4, 0xca,
-	0x2e, 0x75, 0x8c, 0x6e, 0xdd, 0x2b, 0x42, 0xce, 0x7b, 0xa8, 0xcd, 0x63, 0x48, 0x03, 0xaa, 0x63,
-	0x7e, 0xc3, 0xc5, 0x1d, 0xb7, 0xd6, 0x08, 0xc0, 0xfa, 0x68, 0x26, 0x15, 0x86, 0x96, 0x41, 0x6a,
-	0x50, 0x1e, 0x4b, 0x8c, 0xad, 0x92, 0xf3, 0xd7, 0x84, 0x96, 0x87, 0xb7, 0x09, 0x4a, 0x95, 0xd6,
-	0xf3, 0x50, 0x46, 0xa4, 0x07, 0xf0, 0x4d, 0xf8, 0x8c, 0x8f, 0x14, 0x55, 0x98, 0x6b, 0x6b, 0x69,
-	0x6d, 0x0b, 0xd8, 0x2b, 0x50, 0x48, 0x13, 0x4a, 0xc3, 0x23, 0x2d, 0xc6, 0xf4, 0x4a, 0xc3, 0x23,
-	0xd2, 0x86, 0xda, 0x70, 0x82, 0x5c, 0x31, 0x35, 0xb3, 0x4d, 0x2d, 0xf1, 0xde, 0x26, 0x5b, 0x50,
-	0xf1, 0x44, 0x80, 0xd2, 0x2e, 0x77, 0xcc, 0xae, 0xe9, 0x65, 0x06, 0xf9, 0x04, 0x70, 0x41, 0x03,
-	0x36, 0x19, 0x73, 0xc5, 0x02, 0xbb, 0xd2, 0x31, 0xba, 0x8d, 0x7e, 0xdb, 0xcd, 0x1a, 0xea, 0xce,
-	0x1b, 0xea, 0x9e, 0xcf, 0x1b, 0xea, 0x15, 0xd8, 0x64, 0x0f, 0x36, 0x06, 0x01, 0x4e, 0xa9, 0xc2,
-	0x3c, 0x7c, 0xfd, 0xd9, 0xf0, 0xe5, 0x00, 0xb2, 0x0d, 0xf5, 0x13, 0x36, 0x45, 0x7e, 0x46, 0x43,
-	0xb4, 0xab, 0x5a, 0xf0, 0x02, 0x20, 0x6f, 0x00, 0x8e, 0x69, 0xc8, 0x82, 0x99, 0x76, 0xd7, 0xb4,
-	0xbb, 0x80, 0xa4, 0x2f, 0x1a, 0x84, 0x94, 0x05, 0x76, 0x5d, 0xbb, 0x32, 0x23, 0xed, 0xc1, 0xb9,
-	0xb8, 0x41, 0xfe, 0x15, 0x67, 0x36, 0x64, 0x3d, 0x98, 0xdb, 0xa4, 0x0f, 0xf5, 0x11, 0xfe, 0x12,
-	0x7c, 0x42, 0xe3, 0x99, 0xdd, 0xd0, 0x6a, 0xb7, 0x74, 0x7f, 0x57, 0x26, 0xe1, 0x2d, 0x68, 0xce,
-	0x4f, 0x68, 0x2e, 0x79, 0x6f, 0xd3, 0xba, 0x3a, 0xa3, 0x9e, 0x50, 0xdd, 0xcb, 0x0c, 0xb2, 0xbb,
-	0xd8, 0x31, 0xaa, 0x98, 0xe0, 0x7a, 0x2c, 0x8d, 0x3e, 0x79, 0xb8, 0x5b, 0xde, 0x32, 0xd1, 0x39,
-	0x80, 0x9a, 0x9e, 0x69, 0x9a, 0xbb, 0x38, 0x41, 0x63, 0x65, 0x82, 0x6d, 0xa8, 0x7d, 0xa7, 0x52,
-	0xde, 0x89, 0x78, 0x92, 0x2f, 0xe0, 0xbd, 0xed, 0x7c, 0x84, 0x7a, 0x9e, 0x43, 0x46, 0x64, 0x07,
-	0x36, 0x47, 0x28, 0x25, 0x13, 0x5c, 0x4b, 0xbb, 0xa0, 0x41, 0x82, 0x79, 0xb6, 0x87, 0x0e, 0xe7,
-	0x52, 0x87, 0x8a, 0x44, 0xa5, 0xf5, 0xdd, 0x27, 0x43, 0xbf, 0xac, 0x3d, 0x12, 0x4c, 0xb6, 0x0b,
-	0x7a, 0x4b, 0x39, 0xed, 0x1e, 0x39, 0xa8, 0x42, 0x25, 0xab, 0xf1, 0x42, 0x6f, 0xb6, 0xae, 0x21,
-	0x23, 0x67, 0x07, 0x9a, 0x67, 0x78, 0x37, 0xd7, 0xfe, 0xcc, 0xb3, 0x9d, 0x4d, 0x68, 0x2d, 0xb1,
-	0x65, 0xe4, 0xfc, 0x36, 0x60, 0xf3, 0xf0, 0x9a, 0x72, 0x1f, 0x8b, 0x49, 0xfe, 0xeb, 0xd9, 0xa4,
-	0x0b, 0xad, 0xc3, 0x24, 0x8e, 0x91, 0xab, 0x95, 0xa6, 0xae, 0xc2, 0xe9, 0xed, 0x17, 0x04, 0xe4,
-	0x87, 0x55, 0x84, 0x9c, 0x00, 0xc8, 0xaa, 0x1c, 0x19, 0x11, 0x1b, 0xaa, 0x19, 0x3a, 0xd1, 0x2a,
-	0x6a, 0xde, 0xdc, 0x24, 0x9f, 0xe1, 0xd5, 0x90, 0x4f, 0xd3, 0x4b, 0x2a, 0x64, 0x39, 0x45, 0x29,
-	0xa9, 0x8f, 0xb9, 0x8a, 0xa7, 0x09, 0xef, 0x7e, 0x14, 0xbf, 0x89, 0xf4, 0xaf, 0x39, 0x65, 0x52,
-	0x32, 0xee, 0x5b, 0x6b, 0xa4, 0x0e, 0x95, 0x41, 0x1c, 0x8b, 0x38, 0xfb, 0x6a, 0xce, 0x04, 0x47,
-	0xab, 0x94, 0x32, 0x4e, 0x62, 0xca, 0x15, 0x4e, 0x2c, 0x93, 0x54, 0xc1, 0x1c, 0xf7, 0x8f, 0xad,
-	0x32, 0x21, 0xd0, 0x5c, 0xd6, 0x6c, 0x55, 0xfa, 0x7b, 0x50, 0xd6, 0xdf, 0xe3, 0x2e, 0x34, 0x0a,
-	0x3b, 0x4f, 0x5e, 0x3e, 0xbc, 0x91, 0xdb, 0xf6, 0xa3, 0x87, 0x73, 0xb9, 0xae, 0x8f, 0xfe, 0xc3,
-	0xbf, 0x00, 0x00, 0x00, 0xff, 0xff, 0xc9, 0x92, 0x09, 0x18, 0xa5, 0x05, 0x00, 0x00,
+	// 999 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x56, 0xcd, 0x72, 0xe3, 0x44,
+	0x10, 0x8e, 0x2c, 0xff, 0xb6, 0x37, 0xb6, 0x3c, 0x84, 0x45, 0x88, 0x2d, 0x70, 0xe9, 0x40, 0x19,
+	0x0a, 0x1c, 0xca, 0x5c, 0x96, 0x9f, 0x03, 0x26, 0x9b, 0x5d, 0x5c, 0x6c, 0x02, 0x35, 0x8e, 0xf7,
+	0xae, 0x5d, 0xf5, 0x3a, 0xaa, 0x95, 0x66, 0x14, 0xcd, 0x38, 0x29, 0xbf, 0x02, 0x37, 0x5e, 0x82,
+	0x1b, 0x37, 0x78, 0x01, 0x4e, 0x1c, 0x78, 0x27, 0x28, 0x8d, 0x64, 0x7b, 0x24, 0x67, 0xbd, 0x49,
+	0x0e, 0xd4, 0xde, 0xdc, 0x5f, 0xf7, 0x58, 0x5f, 0x7f, 0xdd, 0xd3, 0x3d, 0x00, 0xde, 0x42, 0x9e,
+	0x0f, 0xe3, 0x84, 0x4b, 0x4e, 0x4c, 0x2f, 0x0e, 0x9c, 0x8f, 0xe6, 0x9c, 0xcf, 0x43, 0x3c, 0x54,
+	0xd0, 0xf3, 0xc5, 0xcb, 0x43, 0x19, 0x44, 0x28, 0xa4, 0x17, 0xc5, 0x59, 0x94, 0xfb, 0xab, 0x01,
+	0xfb, 0x47, 0x9c, 0xbd, 0x0c, 0xe6, 0x8b, 0x04, 0xc7, 0x0b, 0x79, 0x4e, 0x0e, 0xa1, 0x3a, 0x4e,
+	0xd0, 0xb3, 0x8d, 0xbe, 0x31, 0xe8, 0x8c, 0x3e, 0x18, 0x7a, 0x71, 0x30, 0x2c, 0x44, 0x0c, 0x53,
+	0xf7, 0xd9, 0x32, 0x46, 0xaa, 0x02, 0x49, 0x1f, 0xda, 0xc7, 0xec, 0x32, 0x48, 0x38, 0x8b, 0x90,
+	0x49, 0xbb, 0xd2, 0x37, 0x06, 0x2d, 0xaa, 0x43, 0xee, 0xe7, 0xd0, 0x5c, 0x9d, 0x21, 0x6d, 0x68,
+	0xcc, 0xd8, 0x2b, 0xc6, 0xaf, 0x98, 0xb5, 0x47, 0x00, 0xea, 0xd3, 0xa5, 0x90, 0x18, 0x59, 0x06,
+	0x69, 0x42, 0x75, 0x26, 0x30, 0xb1, 0x2a, 0xee, 0x6f, 0x26, 0x74, 0x29, 0x5e, 0x2c, 0x50, 0xc8,
+	0xf4, 0x7b, 0x14, 0x45, 0x4c, 0x0e, 0x01, 0x9e, 0xf2, 0x79, 0xc0, 0xa6, 0xd2, 0x93, 0x98, 0x73,
+	0xeb, 0x2a, 0x6e, 0x1b, 0x98, 0x6a, 0x21, 0xa4, 0x03, 0x95, 0xc9, 0x23, 0x45, 0xc6, 0xa4, 0x95,
+	0xc9, 0x23, 0xe2, 0x40, 0x73, 0xe2, 0x23, 0x93, 0x81, 0x5c, 0xda, 0xa6, 0xa2, 0xb8, 0xb6, 0xc9,
+	0x01, 0xd4, 0x28, 0x0f, 0x51, 0xd8, 0xd5, 0xbe, 0x39, 0x30, 0x69, 0x66, 0x90, 0xaf, 0x01, 0x9e,
+	0x79, 0x61, 0xe0, 0xcf, 0x98, 0x0c, 0x42, 0xbb, 0xd6, 0x37, 0x06, 0xed, 0x91, 0x33, 0xcc, 0x04,
+	0x1d, 0xae, 0x04, 0x1d, 0x9e, 0xad, 0x04, 0xa5, 0x5a, 0x34, 0xf9, 0x0e, 0xf6, 0x8f, 0x43, 0xbc,
+	0xf4, 0x24, 0xe6, 0xc7, 0xeb, 0x6f, 0x3c, 0x5e, 0x3c, 0x40, 0x1e, 0x40, 0xeb, 0x49, 0x70, 0x89,
+	0xec, 0xd4, 0x8b, 0xd0, 0x6e, 0x28, 0xc2, 0x1b, 0x80, 0x7c, 0x08, 0xf0, 0xd8, 0x8b, 0x82, 0x70,
+	0xa9, 0xdc, 0x4d, 0xe5, 0xd6, 0x90, 0x34, 0xa3, 0xe3, 0xc8, 0x0b, 0x42, 0xbb, 0xa5, 0x5c, 0x99,
+	0x91, 0x6a, 0x70, 0xc6, 0x5f, 0x21, 0xfb, 0x11, 0x97, 0x36, 0x64, 0x1a, 0xac, 0x6c, 0x32, 0x82,
+	0xd6, 0x14, 0x5f, 0x70, 0xe6, 0x7b, 0xc9, 0xd2, 0x6e, 0x2b, 0xb6, 0x07, 0x4a, 0xdf, 0x52, 0x25,
+	0xe8, 0x26, 0xcc, 0xfd, 0xcb, 0x80, 0x4e, 0xc1, 0x7d, 0x91, 0x7e, 0x58, 0xfd, 0xa5, 0x2a, 0x51,
+	0x8b, 0x66, 0x06, 0x79, 0xb8, 0x69, 0x32, 0x4f, 0x06, 0x9c, 0xa9, 0xba, 0xb4, 0x47, 0x64, 0xbb,
+	0xb9, 0x68, 0x31, 0x30, 0x4d, 0x94, 0x62, 0xc4, 0x25, 0x8e, 0x7d, 0x3f, 0xc9, 0x0b, 0xa7, 0x21,
+	0xe4, 0x3e, 0xd4, 0xa7, 0xf8, 0x62, 0x91, 0xa0, 0x5d, 0xed, 0x1b, 0x83, 0x26, 0xcd, 0xad, 0x14,
+	0x3f, 0x41, 0x79, 0xce, 0x7d, 0x55, 0xb8, 0x16, 0xcd, 0x2d, 0x62, 0x81, 0x39, 0xa3, 0x4f, 0x55,
+	0x39, 0x5a, 0x34, 0xfd, 0xe9, 0xfe, 0x61, 0x40, 0x53, 0xf5, 0x4d, 0x4a, 0x5f, 0xef, 0x12, 0xa3,
+	0xd4, 0x25, 0x0e, 0x34, 0x7f, 0xf6, 0x84, 0xb8, 0xe2, 0x89, 0x9f, 0x37, 0xf9, 0xda, 0xbe, 0x33,
+	0xcd, 0x2d, 0x61, 0x6a, 0x37, 0x14, 0xc6, 0xfd, 0x0a, 0x5a, 0x39, 0x6b, 0x11, 0x93, 0xcf, 0xa0,
+	0x37, 0x45, 0x21, 0x02, 0xce, 0x94, 0xde, 0xcf, 0xbc, 0x70, 0x81, 0x39, 0xff, 0x6d, 0x87, 0xfb,
+	0x8b, 0xa1, 0xce, 0xf2, 0x85, 0x4c, 0x53, 0x1e, 0xbe, 0xf6, 0xec, 0x0f, 0x7b, 0xd7, 0x9c, 0x26,
+	0x0f, 0x34, 0x89, 0x2a, 0x79, 0xd8, 0x46, 0xa4, 0x8f, 0xa1, 0x36, 0xf6, 0xa3, 0x80, 0x29, 0x0d,
+	0xda, 0x23, 0x4b, 0x25, 0xa2, 0x90, 0x23, 0x2f, 0x0c, 0x31, 0xa1, 0x99, 0xfb, 0xfb, 0x06, 0xd4,
+	0x32, 0x32, 0xf7, 0x00, 0x56, 0x5c, 0x44, 0xec, 0xfe, 0x6e, 0x40, 0x5b, 0x8b, 0xbe, 0x5d, 0x62,
+	0xa5, 0x2a, 0x54, 0x76, 0x54, 0xc1, 0xdc, 0x5d, 0x85, 0xea, 0x4d, 0xab, 0x70, 0x06, 0x9d, 0x53,
+	0xbc, 0x5a, 0xb5, 0xc1, 0x9b, 0x3a, 0x68, 0x2d, 0x4e, 0x65, 0xa7, 0x38, 0x6e, 0x0f, 0xba, 0x85,
+	0x7f, 0x15, 0xb1, 0xfb, 0xaf, 0x01, 0xbd, 0xa3, 0x73, 0x8f, 0xcd, 0x51, 0xff, 0xd8, 0xed, 0xe4,
+	0x19, 0x40, 0xf7, 0x68, 0x91, 0x24, 0xc8, 0x64, 0xa9, 0x8f, 0xcb, 0x70, 0x3a, 0xd2, 0x35, 0x02,
+	0x79, 0x3f, 0xeb, 0x50, 0x49, 0xea, 0xea, 0x0e, 0xa9, 0x6b, 0xbb, 0xa5, 0xae, 0xdf, 0x54, 0xea,
+	0x10, 0x48, 0x59, 0x00, 0x11, 0x13, 0x1b, 0x1a, 0x19, 0xea, 0xab, 0xbc, 0x9b, 0x74, 0x65, 0x92,
+	0x6f, 0xe1, 0xfd, 0x09, 0xbb, 0x4c, 0x47, 0xb2, 0xc6, 0xfb, 0x04, 0x85, 0xf0, 0xe6, 0x98, 0xe7,
+	0xfd, 0xfa, 0x00, 0xf7, 0x6f, 0x03, 0x20, 0x1f, 0xc8, 0xb7, 0x17, 0xfa, 0xed, 0x9a, 0x14, 0x3f,
+	0x41, 0x7b, 0x9d, 0x89, 0x88, 0xb7, 0x57, 0x93, 0x71, 0xcb, 0xd5, 0xe4, 0xfe, 0x63, 0x40, 0x67,
+	0x12, 0xc5, 0x98, 0x08, 0xce, 0xee, 0xaa, 0x4f, 0x71, 0x84, 0x68, 0x77, 0xe4, 0xff, 0xd7, 0xa7,
+	0x07, 0xdd, 0x42, 0x36, 0x22, 0x76, 0x09, 0x58, 0x79, 0x6b, 0xa8, 0x10, 0x41, 0xf1, 0xc2, 0xa5,
+	0x70, 0x4f, 0xc7, 0xf4, 0x4d, 0x67, 0x6e, 0x36, 0x5d, 0x31, 0x35, 0xb3, 0x90, 0x9a, 0x05, 0xe6,
+	0x38, 0x0c, 0xf3, 0xd9, 0x93, 0xfe, 0xfc, 0x14, 0xf5, 0x57, 0x4d, 0xfa, 0x34, 0x3a, 0x09, 0x84,
+	0x08, 0xd8, 0xdc, 0xda, 0x23, 0x2d, 0xa8, 0x1d, 0x27, 0x09, 0x4f, 0xb2, 0x97, 0xd1, 0x29, 0x67,
+	0x68, 0x55, 0xd2, 0x88, 0x27, 0x89, 0xc7, 0x24, 0xfa, 0x96, 0x49, 0x1a, 0x60, 0xce, 0x46, 0x8f,
+	0xad, 0x2a, 0x21, 0xd0, 0x29, 0xde, 0x0c, 0xab, 0x96, 0x46, 0xe6, 0x45, 0xb3, 0xea, 0xa3, 0x3f,
+	0x4d, 0xa8, 0xaa, 0xb7, 0xdd, 0x43, 0x68, 0x6b, 0xfb, 0x9a, 0xbc, 0xb3, 0xbd, 0xe0, 0x2f, 0x9c,
+	0x6b, 0xb7, 0x7e, 0x3a, 0xba, 0x14, 0x53, 0xb2, 0xbf, 0x79, 0x74, 0xa5, 0xd1, 0x1d, 0xdd, 0x14,
+	0x31, 0xf9, 0x04, 0xea, 0xd9, 0x38, 0x27, 0x6b, 0x4f, 0xb6, 0x67, 0x9c, 0x6e, 0xc1, 0x16, 0x71,
+	0x4a, 0x46, 0x9f, 0x28, 0x19, 0x99, 0xe2, 0x34, 0x75, 0x0e, 0xb6, 0x41, 0x11, 0x93, 0x71, 0x39,
+	0x61, 0x72, 0x3f, 0x2b, 0x73, 0x79, 0x40, 0x3a, 0xef, 0x5d, 0x8b, 0xab, 0x8d, 0xb9, 0xd2, 0x87,
+	0x64, 0xc4, 0x36, 0x97, 0xdd, 0xb1, 0x8a, 0x40, 0x46, 0x55, 0x6b, 0x91, 0x9c, 0x6a, 0xf1, 0x0a,
+	0x38, 0x07, 0xdb, 0xa0, 0x88, 0xc9, 0x37, 0xb0, 0x5f, 0xe8, 0x24, 0xf2, 0x6e, 0x16, 0x56, 0xea,
+	0x2e, 0xa7, 0xb7, 0x05, 0x7f, 0x61, 0x3c, 0xaf, 0xab, 0xbb, 0xf8, 0xe5, 0x7f, 0x03, 0x00, 0x40,
+	0x93, 0xb6, 0xda, 0xd7, 0x0b, 0x00, 0x00,
 }
@@ -7,10 +7,34 @@ package api
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"golang.org/x/net/lex/httplex"
 )
 
+// SessionCookie returns the session cookie of the application for the
+// request. A negative lifetime removes the cookie.
+func (r *HTTPRequest) SessionCookie(token string, lifetime time.Duration) *http.Cookie {
+	c := &http.Cookie{
+		Name:     r.Auth.TokenKey,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.Secure,
+		// Lax keeps top level navigation from other sites logged in.
+		// The router rejects cross-origin state changing requests.
+		SameSite: http.SameSiteLaxMode,
+	}
+	if lifetime < 0 {
+		c.MaxAge = -1
+		c.Expires = time.Unix(0, 0)
+		return c
+	}
+	c.MaxAge = int(lifetime / time.Second)
+	c.Expires = time.Now().Add(lifetime)
+	return c
+}
+
 // The following was copied from go1.9 code.
 
 // Cookie returns the named cookie provided in the request or
@@ -0,0 +1,144 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"sync"
+)
+
+// maxPendingInvalidations is the number of invalidations kept for a slow
+// client before they are replaced with a single invalidation of All.
+const maxPendingInvalidations = 100
+
+// Invalidator sends session invalidations to the clients of the
+// Auth.Invalidations stream. The zero value is ready to use. Invalidations
+// published to a nil Invalidator are discarded.
+type Invalidator struct {
+	lk      sync.Mutex
+	clients map[*invalidationClient]bool
+	stopped chan struct{}
+}
+
+type invalidationClient struct {
+	notify chan struct{}
+
+	lk      sync.Mutex
+	pending []*Invalidation
+	all     bool
+}
+
+func (iv *Invalidator) stoppedLocked() chan struct{} {
+	if iv.stopped == nil {
+		iv.stopped = make(chan struct{})
+	}
+	return iv.stopped
+}
+
+// Token invalidates the sessions of token.
+func (iv *Invalidator) Token(token ...string) {
+	if len(token) == 0 {
+		return
+	}
+	iv.Publish(&Invalidation{Token: token})
+}
+
+// Identity invalidates all sessions of identity.
+func (iv *Invalidator) Identity(identity ...string) {
+	if len(identity) == 0 {
+		return
+	}
+	iv.Publish(&Invalidation{Identity: identity})
+}
+
+// Publish sends inv to all connected clients. Publish does not block on
+// slow clients.
+func (iv *Invalidator) Publish(inv *Invalidation) {
+	if iv == nil {
+		return
+	}
+	iv.lk.Lock()
+	defer iv.lk.Unlock()
+
+	for c := range iv.clients {
+		c.lk.Lock()
+		if !c.all {
+			if inv.All || len(c.pending) >= maxPendingInvalidations {
+				c.all = true
+				c.pending = nil
+			} else {
+				c.pending = append(c.pending, inv)
+			}
+		}
+		c.lk.Unlock()
+
+		select {
+		case c.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Serve implements AuthServer.Invalidations. The stream starts with an
+// invalidation of All, sent after the client is registered, so the client
+// may drop the results it cached before without missing any later
+// invalidation. It returns when the client disconnects or Stop is called.
+func (iv *Invalidator) Serve(stream Auth_InvalidationsServer) error {
+	c := &invalidationClient{
+		notify: make(chan struct{}, 1),
+		all:    true,
+	}
+	c.notify <- struct{}{}
+	iv.lk.Lock()
+	if iv.clients == nil {
+		iv.clients = make(map[*invalidationClient]bool, 3)
+	}
+	iv.clients[c] = true
+	stopped := iv.stoppedLocked()
+	iv.lk.Unlock()
+
+	defer func() {
+		iv.lk.Lock()
+		delete(iv.clients, c)
+		iv.lk.Unlock()
+	}()
+
+	for {
+		select {
+		case <-c.notify:
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-stopped:
+			return nil
+		}
+
+		c.lk.Lock()
+		pending, all := c.pending, c.all
+		c.pending, c.all = nil, false
+		c.lk.Unlock()
+
+		if all {
+			pending = []*Invalidation{{All: true}}
+		}
+		for _, inv := range pending {
+			err := stream.Send(inv)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Stop ends all Serve streams so the server can stop gracefully.
+func (iv *Invalidator) Stop() {
+	iv.lk.Lock()
+	defer iv.lk.Unlock()
+
+	stopped := iv.stoppedLocked()
+	select {
+	case <-stopped:
+	default:
+		close(stopped)
+	}
+}
@@ -20,6 +20,9 @@ var _ = math.Inf
 type ConfigureURL struct {
 	MapTo  string `protobuf:"bytes,1,opt,name=MapTo" json:"MapTo,omitempty"`
 	Config string `protobuf:"bytes,2,opt,name=Config" json:"Config,omitempty"`
+	// Stream the request and response bodies with ServeHTTPStream
+	// rather then reading them into memory.
+	Stream bool `protobuf:"varint,3,opt,name=Stream" json:"Stream,omitempty"`
 }
 
 func (m *ConfigureURL) Reset()                    { *m = ConfigureURL{} }
@@ -41,6 +44,13 @@ func (m *ConfigureURL) GetConfig() string {
 	return ""
 }
 
+func (m *ConfigureURL) GetStream() bool {
+	if m != nil {
+		return m.Stream
+	}
+	return false
+}
+
 type HTTPRequest struct {
 	Host string `protobuf:"bytes,1,opt,name=Host" json:"Host,omitempty"`
 	// Method specifies the HTTP method (GET, POST, PUT, etc.).
@@ -64,6 +74,12 @@ type HTTPRequest struct {
 	Auth       *RequestAuthResp `protobuf:"bytes,11,opt,name=Auth" json:"Auth,omitempty"`
 	Config     *ConfigureURL    `protobuf:"bytes,12,opt,name=Config" json:"Config,omitempty"`
 	Version    string           `protobuf:"bytes,13,opt,name=Version" json:"Version,omitempty"`
+	// Secure is true if the client connected with TLS, either to the
+	// router or to a trusted proxy in front of the router.
+	Secure bool `protobuf:"varint,14,opt,name=Secure" json:"Secure,omitempty"`
+	// AuthConfig is the authentication area and environment of the
+	// application the request is for.
+	AuthConfig *ConfigureAuth `protobuf:"bytes,15,opt,name=AuthConfig" json:"AuthConfig,omitempty"`
 }
 
 func (m *HTTPRequest) Reset()                    { *m = HTTPRequest{} }
@@ -162,6 +178,20 @@ func (m *HTTPRequest) GetVersion() string {
 	return ""
 }
 
+func (m *HTTPRequest) GetSecure() bool {
+	if m != nil {
+		return m.Secure
+	}
+	return false
+}
+
+func (m *HTTPRequest) GetAuthConfig() *ConfigureAuth {
+	if m != nil {
+		return m.AuthConfig
+	}
+	return nil
+}
+
 type HTTPResponse struct {
 	Header *KeyValueList `protobuf:"bytes,1,opt,name=Header" json:"Header,omitempty"`
 	// Content type of the body.
@@ -169,6 +199,12 @@ type HTTPResponse struct {
 	// Encoding of the response. Often a compression method like "gzip" or "br".
 	Encoding string `protobuf:"bytes,3,opt,name=Encoding" json:"Encoding,omitempty"`
 	Body     []byte `protobuf:"bytes,4,opt,name=Body,proto3" json:"Body,omitempty"`
+	// Status is the HTTP status code of the response.
+	// If zero, 200 (OK) is used, or 302 (Found) when Redirect is set.
+	Status int32 `protobuf:"varint,5,opt,name=Status" json:"Status,omitempty"`
+	// Redirect the client to this URL. The URL may be relative to the
+	// request path.
+	Redirect string `protobuf:"bytes,6,opt,name=Redirect" json:"Redirect,omitempty"`
 }
 
 func (m *HTTPResponse) Reset()                    { *m = HTTPResponse{} }
@@ -204,16 +240,268 @@ func (m *HTTPResponse) GetBody() []byte {
 	return nil
 }
 
+func (m *HTTPResponse) GetStatus() int32 {
+	if m != nil {
+		return m.Status
+	}
+	return 0
+}
+
+func (m *HTTPResponse) GetRedirect() string {
+	if m != nil {
+		return m.Redirect
+	}
+	return ""
+}
+
+type HTTPStreamRequest struct {
+	// Types that are valid to be assigned to Value:
+	//	*HTTPStreamRequest_Request
+	//	*HTTPStreamRequest_Chunk
+	Value isHTTPStreamRequest_Value `protobuf_oneof:"Value"`
+}
+
+func (m *HTTPStreamRequest) Reset()                    { *m = HTTPStreamRequest{} }
+func (m *HTTPStreamRequest) String() string            { return proto.CompactTextString(m) }
+func (*HTTPStreamRequest) ProtoMessage()               {}
+func (*HTTPStreamRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{3} }
+
+type isHTTPStreamRequest_Value interface{ isHTTPStreamRequest_Value() }
+
+type HTTPStreamRequest_Request struct {
+	Request *HTTPRequest `protobuf:"bytes,1,opt,name=Request,oneof"`
+}
+type HTTPStreamRequest_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=Chunk,proto3,oneof"`
+}
+
+func (*HTTPStreamRequest_Request) isHTTPStreamRequest_Value() {}
+func (*HTTPStreamRequest_Chunk) isHTTPStreamRequest_Value()   {}
+
+func (m *HTTPStreamRequest) GetValue() isHTTPStreamRequest_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *HTTPStreamRequest) GetRequest() *HTTPRequest {
+	if x, ok := m.GetValue().(*HTTPStreamRequest_Request); ok {
+		return x.Request
+	}
+	return nil
+}
+
+func (m *HTTPStreamRequest) GetChunk() []byte {
+	if x, ok := m.GetValue().(*HTTPStreamRequest_Chunk); ok {
+		return x.Chunk
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*HTTPStreamRequest) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _HTTPStreamRequest_OneofMarshaler, _HTTPStreamRequest_OneofUnmarshaler, _HTTPStreamRequest_OneofSizer, []interface{}{
+		(*HTTPStreamRequest_Request)(nil),
+		(*HTTPStreamRequest_Chunk)(nil),
+	}
+}
+
+func _HTTPStreamRequest_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*HTTPStreamRequest)
+	// Value
+	switch x := m.Value.(type) {
+	case *HTTPStreamRequest_Request:
+		b.EncodeVarint(1<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Request); err != nil {
+			return err
+		}
+	case *HTTPStreamRequest_Chunk:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		b.EncodeRawBytes(x.Chunk)
+	case nil:
+	default:
+		return fmt.Errorf("HTTPStreamRequest.Value has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _HTTPStreamRequest_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*HTTPStreamRequest)
+	switch tag {
+	case 1: // Value.Request
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(HTTPRequest)
+		err := b.DecodeMessage(msg)
+		m.Value = &HTTPStreamRequest_Request{msg}
+		return true, err
+	case 2: // Value.Chunk
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeRawBytes(true)
+		m.Value = &HTTPStreamRequest_Chunk{x}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _HTTPStreamRequest_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*HTTPStreamRequest)
+	// Value
+	switch x := m.Value.(type) {
+	case *HTTPStreamRequest_Request:
+		s := proto.Size(x.Request)
+		n += proto.SizeVarint(1<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *HTTPStreamRequest_Chunk:
+		n += proto.SizeVarint(2<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.Chunk)))
+		n += len(x.Chunk)
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+type HTTPStreamResponse struct {
+	// Types that are valid to be assigned to Value:
+	//	*HTTPStreamResponse_Response
+	//	*HTTPStreamResponse_Chunk
+	Value isHTTPStreamResponse_Value `protobuf_oneof:"Value"`
+}
+
+func (m *HTTPStreamResponse) Reset()                    { *m = HTTPStreamResponse{} }
+func (m *HTTPStreamResponse) String() string            { return proto.CompactTextString(m) }
+func (*HTTPStreamResponse) ProtoMessage()               {}
+func (*HTTPStreamResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{4} }
+
+type isHTTPStreamResponse_Value interface{ isHTTPStreamResponse_Value() }
+
+type HTTPStreamResponse_Response struct {
+	Response *HTTPResponse `protobuf:"bytes,1,opt,name=Response,oneof"`
+}
+type HTTPStreamResponse_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=Chunk,proto3,oneof"`
+}
+
+func (*HTTPStreamResponse_Response) isHTTPStreamResponse_Value() {}
+func (*HTTPStreamResponse_Chunk) isHTTPStreamResponse_Value()    {}
+
+func (m *HTTPStreamResponse) GetValue() isHTTPStreamResponse_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *HTTPStreamResponse) GetResponse() *HTTPResponse {
+	if x, ok := m.GetValue().(*HTTPStreamResponse_Response); ok {
+		return x.Response
+	}
+	return nil
+}
+
+func (m *HTTPStreamResponse) GetChunk() []byte {
+	if x, ok := m.GetValue().(*HTTPStreamResponse_Chunk); ok {
+		return x.Chunk
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*HTTPStreamResponse) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _HTTPStreamResponse_OneofMarshaler, _HTTPStreamResponse_OneofUnmarshaler, _HTTPStreamResponse_OneofSizer, []interface{}{
+		(*HTTPStreamResponse_Response)(nil),
+		(*HTTPStreamResponse_Chunk)(nil),
+	}
+}
+
+func _HTTPStreamResponse_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*HTTPStreamResponse)
+	// Value
+	switch x := m.Value.(type) {
+	case *HTTPStreamResponse_Response:
+		b.EncodeVarint(1<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Response); err != nil {
+			return err
+		}
+	case *HTTPStreamResponse_Chunk:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		b.EncodeRawBytes(x.Chunk)
+	case nil:
+	default:
+		return fmt.Errorf("HTTPStreamResponse.Value has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _HTTPStreamResponse_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*HTTPStreamResponse)
+	switch tag {
+	case 1: // Value.Response
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(HTTPResponse)
+		err := b.DecodeMessage(msg)
+		m.Value = &HTTPStreamResponse_Response{msg}
+		return true, err
+	case 2: // Value.Chunk
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeRawBytes(true)
+		m.Value = &HTTPStreamResponse_Chunk{x}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _HTTPStreamResponse_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*HTTPStreamResponse)
+	// Value
+	switch x := m.Value.(type) {
+	case *HTTPStreamResponse_Response:
+		s := proto.Size(x.Response)
+		n += proto.SizeVarint(1<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *HTTPStreamResponse_Chunk:
+		n += proto.SizeVarint(2<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.Chunk)))
+		n += len(x.Chunk)
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
 type URL struct {
 	Host  string        `protobuf:"bytes,1,opt,name=Host" json:"Host,omitempty"`
 	Path  string        `protobuf:"bytes,2,opt,name=Path" json:"Path,omitempty"`
 	Query *KeyValueList `protobuf:"bytes,3,opt,name=Query" json:"Query,omitempty"`
+	// Remainder is the part of the request path below a subtree mapping.
+	// If the resource is mapped to "/lib/" and "/lib/js/app.js" is requested,
+	// the Remainder is "js/app.js".
+	Remainder string `protobuf:"bytes,4,opt,name=Remainder" json:"Remainder,omitempty"`
+	// Param contains the path parameters captured by the mapping.
+	// If the resource is mapped to "/api/files/{id}" and "/api/files/42"
+	// is requested, Param["id"] is "42".
+	Param map[string]string `protobuf:"bytes,5,rep,name=Param" json:"Param,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
 func (m *URL) Reset()                    { *m = URL{} }
 func (m *URL) String() string            { return proto.CompactTextString(m) }
 func (*URL) ProtoMessage()               {}
-func (*URL) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{3} }
+func (*URL) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{5} }
 
 func (m *URL) GetHost() string {
 	if m != nil {
@@ -236,6 +524,20 @@ func (m *URL) GetQuery() *KeyValueList {
 	return nil
 }
 
+func (m *URL) GetRemainder() string {
+	if m != nil {
+		return m.Remainder
+	}
+	return ""
+}
+
+func (m *URL) GetParam() map[string]string {
+	if m != nil {
+		return m.Param
+	}
+	return nil
+}
+
 type StringList struct {
 	Value []string `protobuf:"bytes,1,rep,name=Value" json:"Value,omitempty"`
 }
@@ -243,7 +545,7 @@ type StringList struct {
 func (m *StringList) Reset()                    { *m = StringList{} }
 func (m *StringList) String() string            { return proto.CompactTextString(m) }
 func (*StringList) ProtoMessage()               {}
-func (*StringList) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{4} }
+func (*StringList) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{6} }
 
 func (m *StringList) GetValue() []string {
 	if m != nil {
@@ -259,7 +561,7 @@ type KeyValueList struct {
 func (m *KeyValueList) Reset()                    { *m = KeyValueList{} }
 func (m *KeyValueList) String() string            { return proto.CompactTextString(m) }
 func (*KeyValueList) ProtoMessage()               {}
-func (*KeyValueList) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{5} }
+func (*KeyValueList) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{7} }
 
 func (m *KeyValueList) GetValues() map[string]*StringList {
 	if m != nil {
@@ -269,17 +571,18 @@ func (m *KeyValueList) GetValues() map[string]*StringList {
 }
 
 type TLSState struct {
-	Version           uint32 `protobuf:"varint,1,opt,name=Version" json:"Version,omitempty"`
-	HandshakeComplete bool   `protobuf:"varint,2,opt,name=HandshakeComplete" json:"HandshakeComplete,omitempty"`
-	DidResume         bool   `protobuf:"varint,3,opt,name=DidResume" json:"DidResume,omitempty"`
-	CipherSuite       uint32 `protobuf:"varint,4,opt,name=CipherSuite" json:"CipherSuite,omitempty"`
-	ServerName        string `protobuf:"bytes,5,opt,name=ServerName" json:"ServerName,omitempty"`
+	Version            uint32 `protobuf:"varint,1,opt,name=Version" json:"Version,omitempty"`
+	HandshakeComplete  bool   `protobuf:"varint,2,opt,name=HandshakeComplete" json:"HandshakeComplete,omitempty"`
+	DidResume          bool   `protobuf:"varint,3,opt,name=DidResume" json:"DidResume,omitempty"`
+	CipherSuite        uint32 `protobuf:"varint,4,opt,name=CipherSuite" json:"CipherSuite,omitempty"`
+	ServerName         string `protobuf:"bytes,5,opt,name=ServerName" json:"ServerName,omitempty"`
+	NegotiatedProtocol string `protobuf:"bytes,6,opt,name=NegotiatedProtocol" json:"NegotiatedProtocol,omitempty"`
 }
 
 func (m *TLSState) Reset()                    { *m = TLSState{} }
 func (m *TLSState) String() string            { return proto.CompactTextString(m) }
 func (*TLSState) ProtoMessage()               {}
-func (*TLSState) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{6} }
+func (*TLSState) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{8} }
 
 func (m *TLSState) GetVersion() uint32 {
 	if m != nil {
@@ -316,10 +619,19 @@ func (m *TLSState) GetServerName() string {
 	return ""
 }
 
+func (m *TLSState) GetNegotiatedProtocol() string {
+	if m != nil {
+		return m.NegotiatedProtocol
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*ConfigureURL)(nil), "api.ConfigureURL")
 	proto.RegisterType((*HTTPRequest)(nil), "api.HTTPRequest")
 	proto.RegisterType((*HTTPResponse)(nil), "api.HTTPResponse")
+	proto.RegisterType((*HTTPStreamRequest)(nil), "api.HTTPStreamRequest")
+	proto.RegisterType((*HTTPStreamResponse)(nil), "api.HTTPStreamResponse")
 	proto.RegisterType((*URL)(nil), "api.URL")
 	proto.RegisterType((*StringList)(nil), "api.StringList")
 	proto.RegisterType((*KeyValueList)(nil), "api.KeyValueList")
@@ -338,6 +650,12 @@ const _ = grpc.SupportPackageIsVersion4
 
 type HTTPClient interface {
 	ServeHTTP(ctx context.Context, in *HTTPRequest, opts ...grpc.CallOption) (*HTTPResponse, error)
+	// ServeHTTPStream is used for URL resources configured to stream.
+	// The first request message contains the request without a body, the
+	// following messages contain the request body in chunks.
+	// The first response message contains the response headers without a body,
+	// the following messages contain the response body in chunks.
+	ServeHTTPStream(ctx context.Context, opts ...grpc.CallOption) (HTTP_ServeHTTPStreamClient, error)
 }
 
 type hTTPClient struct {
@@ -357,10 +675,47 @@ func (c *hTTPClient) ServeHTTP(ctx context.Context, in *HTTPRequest, opts ...grp
 	return out, nil
 }
 
+func (c *hTTPClient) ServeHTTPStream(ctx context.Context, opts ...grpc.CallOption) (HTTP_ServeHTTPStreamClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_HTTP_serviceDesc.Streams[0], c.cc, "/api.HTTP/ServeHTTPStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &hTTPServeHTTPStreamClient{stream}
+	return x, nil
+}
+
+type HTTP_ServeHTTPStreamClient interface {
+	Send(*HTTPStreamRequest) error
+	Recv() (*HTTPStreamResponse, error)
+	grpc.ClientStream
+}
+
+type hTTPServeHTTPStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *hTTPServeHTTPStreamClient) Send(m *HTTPStreamRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *hTTPServeHTTPStreamClient) Recv() (*HTTPStreamResponse, error) {
+	m := new(HTTPStreamResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for HTTP service
 
 type HTTPServer interface {
 	ServeHTTP(context.Context, *HTTPRequest) (*HTTPResponse, error)
+	// ServeHTTPStream is used for URL resources configured to stream.
+	// The first request message contains the request without a body, the
+	// following messages contain the request body in chunks.
+	// The first response message contains the response headers without a body,
+	// the following messages contain the response body in chunks.
+	ServeHTTPStream(HTTP_ServeHTTPStreamServer) error
 }
 
 func RegisterHTTPServer(s *grpc.Server, srv HTTPServer) {
@@ -385,6 +740,32 @@ func _HTTP_ServeHTTP_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _HTTP_ServeHTTPStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(HTTPServer).ServeHTTPStream(&hTTPServeHTTPStreamServer{stream})
+}
+
+type HTTP_ServeHTTPStreamServer interface {
+	Send(*HTTPStreamResponse) error
+	Recv() (*HTTPStreamRequest, error)
+	grpc.ServerStream
+}
+
+type hTTPServeHTTPStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *hTTPServeHTTPStreamServer) Send(m *HTTPStreamResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *hTTPServeHTTPStreamServer) Recv() (*HTTPStreamRequest, error) {
+	m := new(HTTPStreamRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 var _HTTP_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "api.HTTP",
 	HandlerType: (*HTTPServer)(nil),
@@ -394,50 +775,70 @@ var _HTTP_serviceDesc = grpc.ServiceDesc{
 			Handler:    _HTTP_ServeHTTP_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ServeHTTPStream",
+			Handler:       _HTTP_ServeHTTPStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "request.proto",
 }
 
 func init() { proto.RegisterFile("request.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 599 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x54, 0xdd, 0x6a, 0xdb, 0x4c,
-	0x10, 0x45, 0x96, 0xed, 0xd8, 0x63, 0x9b, 0x2f, 0x59, 0xc2, 0xc7, 0x62, 0xfa, 0x23, 0x04, 0xa5,
-	0x2e, 0x14, 0x53, 0x52, 0x0a, 0xa1, 0xf4, 0x26, 0x4d, 0x03, 0xa6, 0x55, 0x4a, 0xba, 0x52, 0x72,
-	0xbf, 0xad, 0xa6, 0x91, 0x9a, 0x58, 0xab, 0xae, 0x56, 0x01, 0x3d, 0x43, 0xaf, 0xfa, 0x12, 0x7d,
-	0x80, 0x3e, 0x61, 0xd9, 0x91, 0x1c, 0x2f, 0x4d, 0xa0, 0x77, 0x3b, 0x67, 0xce, 0xce, 0xcc, 0x9e,
-	0x33, 0x12, 0xcc, 0x34, 0x7e, 0xaf, 0xb1, 0x32, 0xcb, 0x52, 0x2b, 0xa3, 0x98, 0x2f, 0xcb, 0x7c,
-	0x0e, 0xb2, 0x36, 0x59, 0x0b, 0x84, 0x6f, 0x60, 0x7a, 0xac, 0x8a, 0xaf, 0xf9, 0x65, 0xad, 0xf1,
-	0x5c, 0x44, 0x6c, 0x1f, 0x06, 0xa7, 0xb2, 0x4c, 0x14, 0xf7, 0x02, 0x6f, 0x31, 0x16, 0x6d, 0xc0,
-	0xfe, 0x87, 0x61, 0xcb, 0xe2, 0x3d, 0x82, 0xbb, 0x28, 0xfc, 0xe5, 0xc3, 0x64, 0x95, 0x24, 0x67,
-	0xa2, 0x6d, 0xc2, 0x18, 0xf4, 0x57, 0xaa, 0x32, 0xdd, 0x65, 0x3a, 0xdb, 0xbb, 0xa7, 0x68, 0x32,
-	0x95, 0x6e, 0xee, 0xb6, 0x11, 0x9b, 0x83, 0x7f, 0x2e, 0x22, 0xee, 0x07, 0xde, 0x62, 0x72, 0x30,
-	0x5a, 0xca, 0x32, 0x5f, 0x9e, 0x8b, 0x48, 0x58, 0x90, 0x3d, 0x02, 0x38, 0xb3, 0xe3, 0x9d, 0xca,
-	0x6f, 0x4a, 0xf3, 0x7e, 0xe0, 0x2d, 0x06, 0xc2, 0x41, 0xb6, 0xf9, 0xbc, 0x50, 0x9a, 0x0f, 0xdc,
-	0xbc, 0x45, 0xd8, 0x33, 0x18, 0xae, 0x50, 0xa6, 0xa8, 0xf9, 0x90, 0xca, 0xef, 0x51, 0xf9, 0x0f,
-	0xd8, 0x5c, 0xc8, 0xeb, 0x1a, 0xa3, 0xbc, 0x32, 0xa2, 0x23, 0xd8, 0x91, 0xdf, 0xaa, 0xb4, 0xe1,
-	0x3b, 0x81, 0xb7, 0x98, 0x0a, 0x3a, 0xb3, 0x00, 0x26, 0xc7, 0xaa, 0x30, 0x58, 0x98, 0xa4, 0x29,
-	0x91, 0x8f, 0x68, 0x6e, 0x17, 0xb2, 0x03, 0x08, 0x5c, 0x2b, 0x83, 0x47, 0x69, 0xaa, 0xf9, 0x98,
-	0x08, 0x0e, 0xc2, 0x1e, 0x83, 0x9f, 0x44, 0x31, 0x07, 0xea, 0x3e, 0xa3, 0xee, 0x49, 0x14, 0xc7,
-	0x46, 0x1a, 0x14, 0x36, 0xc3, 0x16, 0xd0, 0x3f, 0xaa, 0x4d, 0xc6, 0x27, 0xc4, 0xd8, 0x27, 0x46,
-	0xa7, 0xa2, 0xc5, 0x05, 0x56, 0xa5, 0x20, 0x86, 0x7d, 0x4b, 0xa7, 0xfd, 0xd4, 0x79, 0x8b, 0x6b,
-	0xda, 0xc6, 0x0e, 0xc6, 0x61, 0xe7, 0x02, 0x75, 0x95, 0xab, 0x82, 0xcf, 0x68, 0xa4, 0x4d, 0x18,
-	0xfe, 0xf0, 0x60, 0xda, 0x1a, 0x55, 0x95, 0xaa, 0xa8, 0xd0, 0x51, 0xc8, 0xfb, 0x97, 0x42, 0x7f,
-	0xa9, 0xd1, 0xbb, 0xab, 0xc6, 0x1c, 0x46, 0x27, 0xc5, 0x17, 0x95, 0xe6, 0xc5, 0x25, 0xf9, 0x39,
-	0x16, 0xb7, 0xf1, 0xad, 0xbe, 0xfd, 0xad, 0xbe, 0xe1, 0x05, 0x59, 0x7f, 0xef, 0xb6, 0x30, 0xe8,
-	0x9f, 0x49, 0x93, 0x75, 0x5d, 0xe8, 0xcc, 0x9e, 0xc2, 0xe0, 0x53, 0x8d, 0xba, 0xe9, 0x76, 0xe5,
-	0x9e, 0x51, 0xdb, 0x7c, 0x18, 0x02, 0xc4, 0x46, 0xe7, 0xc5, 0xa5, 0x05, 0xed, 0x2a, 0x13, 0x83,
-	0x7b, 0x81, 0x6f, 0x57, 0x99, 0x82, 0xf0, 0xa7, 0x07, 0x53, 0xf7, 0x2e, 0x7b, 0x05, 0x43, 0x0a,
-	0x2a, 0xe2, 0x4d, 0x0e, 0x1e, 0xde, 0x29, 0xbf, 0x6c, 0xf3, 0x27, 0x85, 0xd1, 0x8d, 0xe8, 0xc8,
-	0xf3, 0xf7, 0x30, 0x71, 0x60, 0xb6, 0x0b, 0xfe, 0x15, 0x36, 0xdd, 0x53, 0xec, 0x91, 0x3d, 0x81,
-	0xc1, 0x0d, 0xb5, 0xef, 0xd1, 0xd4, 0xff, 0x51, 0xd9, 0xed, 0x78, 0xa2, 0xcd, 0xbe, 0xee, 0x1d,
-	0x7a, 0xe1, 0x6f, 0x0f, 0x46, 0x9b, 0xf5, 0x70, 0x4d, 0xb4, 0xd5, 0x66, 0xb7, 0x26, 0xb2, 0xe7,
-	0xb0, 0xb7, 0x92, 0x45, 0x5a, 0x65, 0xf2, 0x0a, 0x8f, 0xd5, 0xba, 0xbc, 0x46, 0xd3, 0x56, 0x1f,
-	0x89, 0xbb, 0x09, 0xf6, 0x00, 0xc6, 0xef, 0xf2, 0x54, 0x60, 0x55, 0xaf, 0x91, 0x94, 0x1b, 0x89,
-	0x2d, 0x40, 0xa6, 0xe6, 0x65, 0x86, 0x3a, 0xae, 0x73, 0x83, 0xe4, 0xce, 0x4c, 0xb8, 0x90, 0x5d,
-	0xf1, 0x18, 0xf5, 0x0d, 0xea, 0x8f, 0x72, 0x8d, 0xf4, 0x8d, 0x8d, 0x85, 0x83, 0x1c, 0x1c, 0x42,
-	0xdf, 0x6e, 0x14, 0x7b, 0x01, 0x63, 0x42, 0x29, 0xd8, 0xa5, 0x57, 0x3a, 0xbf, 0x84, 0xf9, 0x9e,
-	0x83, 0xb4, 0xbb, 0xf7, 0x79, 0x48, 0xbf, 0x9e, 0x97, 0x7f, 0x02, 0x00, 0x00, 0xff, 0xff, 0x3e,
-	0x3c, 0x3a, 0x61, 0x9c, 0x04, 0x00, 0x00,
+	// 808 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x55, 0xdd, 0x6e, 0x1a, 0x47,
+	0x14, 0xf6, 0x7a, 0x59, 0x0c, 0x67, 0xa1, 0x8e, 0xa7, 0x91, 0x3b, 0x42, 0xfd, 0x41, 0x48, 0x55,
+	0x89, 0x14, 0xd1, 0x88, 0xaa, 0x52, 0xd4, 0xbb, 0xc4, 0x4d, 0x85, 0x5a, 0x1c, 0xb9, 0x03, 0xce,
+	0xfd, 0x84, 0x3d, 0x81, 0xad, 0xcd, 0xce, 0x76, 0x76, 0x36, 0x12, 0x77, 0x7d, 0x85, 0x3e, 0x51,
+	0x9f, 0xa3, 0xb7, 0x7d, 0x84, 0x3e, 0x41, 0x35, 0x67, 0x06, 0x18, 0x8a, 0x25, 0xdf, 0xcd, 0xf9,
+	0xce, 0xc7, 0x39, 0xdf, 0xf9, 0x5b, 0xa0, 0xab, 0xf1, 0xf7, 0x1a, 0x2b, 0x33, 0x2a, 0xb5, 0x32,
+	0x8a, 0xc5, 0xb2, 0xcc, 0x7b, 0x20, 0x6b, 0xb3, 0x72, 0xc0, 0x60, 0x0e, 0x9d, 0x2b, 0x55, 0x7c,
+	0xc8, 0x97, 0xb5, 0xc6, 0x5b, 0x31, 0x65, 0x4f, 0x21, 0xb9, 0x96, 0xe5, 0x5c, 0xf1, 0xa8, 0x1f,
+	0x0d, 0xdb, 0xc2, 0x19, 0xec, 0x12, 0x9a, 0x8e, 0xc5, 0x4f, 0x09, 0xf6, 0x96, 0xc5, 0x67, 0x46,
+	0xa3, 0x5c, 0xf3, 0xb8, 0x1f, 0x0d, 0x5b, 0xc2, 0x5b, 0x83, 0x7f, 0x63, 0x48, 0x27, 0xf3, 0xf9,
+	0x8d, 0x70, 0xc9, 0x19, 0x83, 0xc6, 0x44, 0x55, 0xc6, 0x07, 0xa5, 0xb7, 0xfd, 0xed, 0x35, 0x9a,
+	0x95, 0xca, 0xb6, 0x31, 0x9d, 0xc5, 0x7a, 0x10, 0xdf, 0x8a, 0x29, 0x05, 0x4c, 0xc7, 0xad, 0x91,
+	0x2c, 0xf3, 0xd1, 0xad, 0x98, 0x0a, 0x0b, 0xb2, 0x2f, 0x01, 0x6e, 0xac, 0xec, 0x6b, 0xf9, 0x9b,
+	0xd2, 0xbc, 0xd1, 0x8f, 0x86, 0x89, 0x08, 0x90, 0xbd, 0x3f, 0x2f, 0x94, 0xe6, 0x49, 0xe8, 0xb7,
+	0x08, 0x7b, 0x06, 0xcd, 0x09, 0xca, 0x0c, 0x35, 0x6f, 0x52, 0xf8, 0x0b, 0x0a, 0xff, 0x0b, 0x6e,
+	0xde, 0xc9, 0xfb, 0x1a, 0xa7, 0x79, 0x65, 0x84, 0x27, 0x58, 0xc9, 0xaf, 0x55, 0xb6, 0xe1, 0x67,
+	0xfd, 0x68, 0xd8, 0x11, 0xf4, 0x66, 0x7d, 0x48, 0xaf, 0x54, 0x61, 0xb0, 0x30, 0xf3, 0x4d, 0x89,
+	0xbc, 0x45, 0xba, 0x43, 0xc8, 0x0a, 0x10, 0xb8, 0x56, 0x06, 0x5f, 0x65, 0x99, 0xe6, 0x6d, 0x22,
+	0x04, 0x08, 0xfb, 0x0a, 0xe2, 0xf9, 0x74, 0xc6, 0x81, 0xb2, 0x77, 0x29, 0xfb, 0x7c, 0x3a, 0x9b,
+	0x19, 0x69, 0x50, 0x58, 0x0f, 0x1b, 0x42, 0xe3, 0x55, 0x6d, 0x56, 0x3c, 0x25, 0xc6, 0x53, 0x62,
+	0xf8, 0x2e, 0x5a, 0x5c, 0x60, 0x55, 0x0a, 0x62, 0xd8, 0x5a, 0xfc, 0x4c, 0x3a, 0x41, 0x2d, 0xe1,
+	0x30, 0x77, 0x63, 0xe2, 0x70, 0xf6, 0x0e, 0x75, 0x95, 0xab, 0x82, 0x77, 0x49, 0xd2, 0xd6, 0xa4,
+	0x01, 0xe2, 0xa2, 0xd6, 0xc8, 0x3f, 0xf1, 0x03, 0x24, 0x8b, 0x8d, 0x01, 0x6c, 0x12, 0x9f, 0xe0,
+	0x9c, 0x12, 0xb0, 0xc3, 0x04, 0x24, 0x27, 0x60, 0x0d, 0xfe, 0x8a, 0xa0, 0xe3, 0x86, 0x5e, 0x95,
+	0xaa, 0xa8, 0x30, 0xe8, 0x76, 0xf4, 0x58, 0xb7, 0xff, 0xd7, 0xd9, 0xd3, 0xe3, 0xce, 0xf6, 0xa0,
+	0xf5, 0xa6, 0x58, 0xa8, 0x2c, 0x2f, 0x96, 0xb4, 0x1b, 0x6d, 0xb1, 0xb3, 0x77, 0xb3, 0x6a, 0x04,
+	0xb3, 0xa2, 0xd5, 0x94, 0xa6, 0xae, 0xfc, 0x1a, 0x78, 0xcb, 0xc6, 0x11, 0x98, 0xe5, 0x1a, 0x17,
+	0x86, 0x96, 0xa0, 0x2d, 0x76, 0xf6, 0xe0, 0x3d, 0x5c, 0xd8, 0x02, 0xdc, 0x12, 0x6f, 0x77, 0xf7,
+	0x39, 0x9c, 0xf9, 0xa7, 0x2f, 0xe3, 0x09, 0x95, 0x11, 0xac, 0xf7, 0xe4, 0x44, 0x6c, 0x29, 0xec,
+	0x12, 0x92, 0xab, 0x55, 0x5d, 0xdc, 0x51, 0x09, 0x9d, 0xc9, 0x89, 0x70, 0xe6, 0xeb, 0x33, 0x48,
+	0xa8, 0xea, 0xc1, 0x07, 0x60, 0x61, 0x0e, 0xdf, 0xaa, 0x6f, 0xa1, 0xb5, 0x7d, 0x1f, 0x34, 0x2b,
+	0xec, 0xe7, 0xe4, 0x44, 0xec, 0x48, 0x8f, 0xe7, 0xf9, 0x3b, 0xa2, 0x3b, 0x7a, 0xf0, 0xf4, 0x18,
+	0x34, 0x6e, 0xa4, 0x59, 0xf9, 0x36, 0xd3, 0x9b, 0x7d, 0x03, 0xc9, 0xaf, 0x35, 0xea, 0x0d, 0x8f,
+	0x83, 0xf4, 0x07, 0xb3, 0x72, 0x7e, 0xf6, 0x39, 0xb4, 0x05, 0xae, 0x65, 0x5e, 0xd8, 0xc1, 0x36,
+	0x28, 0xc2, 0x1e, 0x60, 0xcf, 0x20, 0xb9, 0x91, 0x5a, 0xae, 0x79, 0xd2, 0x8f, 0x87, 0xe9, 0xf8,
+	0xd3, 0xed, 0xfd, 0x8e, 0x08, 0x7d, 0x53, 0x18, 0xbd, 0x11, 0x8e, 0xd1, 0x7b, 0x09, 0xb0, 0x07,
+	0xd9, 0x13, 0x88, 0xef, 0x70, 0xe3, 0x65, 0xda, 0xa7, 0xfd, 0x14, 0x7d, 0xb4, 0xc9, 0xbd, 0x4c,
+	0x67, 0xfc, 0x70, 0xfa, 0x32, 0x1a, 0x0c, 0x00, 0x66, 0x46, 0xe7, 0xc5, 0xd2, 0xea, 0xb2, 0x3c,
+	0x12, 0xc9, 0xa3, 0x7e, 0x6c, 0x79, 0xae, 0xfe, 0x3f, 0x23, 0xe8, 0x84, 0xf2, 0xd9, 0xf7, 0xd0,
+	0x24, 0xa3, 0x22, 0x5e, 0x3a, 0xfe, 0xe2, 0xa8, 0xc2, 0x91, 0xf3, 0x3b, 0x91, 0x9e, 0xdc, 0xfb,
+	0x19, 0xd2, 0x00, 0x7e, 0x40, 0xe6, 0xd7, 0xa1, 0xcc, 0x74, 0x7c, 0x4e, 0x61, 0xf7, 0xf2, 0x42,
+	0xdd, 0xff, 0x44, 0xd0, 0xda, 0x9e, 0x7b, 0x78, 0x94, 0x36, 0x5a, 0x77, 0x7f, 0x94, 0xcf, 0xe1,
+	0x62, 0x22, 0x8b, 0xac, 0x5a, 0xc9, 0x3b, 0xbc, 0x52, 0xeb, 0xf2, 0x1e, 0x8d, 0x8b, 0xde, 0x12,
+	0xc7, 0x0e, 0x3b, 0x8f, 0x1f, 0xf3, 0x4c, 0x60, 0x55, 0xaf, 0xd1, 0x7f, 0x86, 0xf7, 0x00, 0x1d,
+	0x56, 0x5e, 0xae, 0x50, 0xcf, 0xea, 0xdc, 0x20, 0xcd, 0xab, 0x2b, 0x42, 0xc8, 0x7e, 0xb2, 0x66,
+	0xa8, 0x3f, 0xa2, 0x7e, 0x2b, 0xd7, 0x48, 0xc7, 0xd2, 0x16, 0x01, 0xc2, 0x46, 0xc0, 0xde, 0xe2,
+	0x52, 0x99, 0x5c, 0x1a, 0xcc, 0xe8, 0x5b, 0xba, 0x50, 0xf7, 0xfe, 0x74, 0x1e, 0xf0, 0x8c, 0xff,
+	0x88, 0xa0, 0x61, 0xd7, 0x96, 0xbd, 0x80, 0x36, 0x85, 0x21, 0xe3, 0xe8, 0x68, 0x7a, 0xc7, 0x0b,
+	0xce, 0x7e, 0x82, 0xf3, 0xdd, 0x2f, 0xdc, 0x81, 0xb0, 0xcb, 0x1d, 0xeb, 0xe0, 0x2a, 0x7b, 0x9f,
+	0x1d, 0xe1, 0x2e, 0xc6, 0x30, 0x7a, 0x11, 0xbd, 0x6f, 0xd2, 0x7f, 0xdb, 0x77, 0xff, 0x0d, 0x00,
+	0xb2, 0xce, 0xfd, 0x2e, 0xfd, 0x06, 0x00, 0x00,
 }
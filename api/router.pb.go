@@ -7,6 +7,7 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 import google_protobuf1 "github.com/golang/protobuf/ptypes/empty"
+import google_protobuf "github.com/golang/protobuf/ptypes/timestamp"
 
 import (
 	context "golang.org/x/net/context"
@@ -68,6 +69,10 @@ func (ServiceConfigAction) EnumDescriptor() ([]byte, []int) { return fileDescrip
 
 type NotifyReq struct {
 	ServiceAddress string `protobuf:"bytes,1,opt,name=ServiceAddress" json:"ServiceAddress,omitempty"`
+	// Remove the service at ServiceAddress from the router, such as before
+	// the service shuts down. Returns after the router stops sending
+	// new requests to the service.
+	Remove bool `protobuf:"varint,2,opt,name=Remove" json:"Remove,omitempty"`
 }
 
 func (m *NotifyReq) Reset()                    { *m = NotifyReq{} }
@@ -82,11 +87,25 @@ func (m *NotifyReq) GetServiceAddress() string {
 	return ""
 }
 
+func (m *NotifyReq) GetRemove() bool {
+	if m != nil {
+		return m.Remove
+	}
+	return false
+}
+
+// UpdateReq changes an operator managed binding. A binding serves the
+// application that uses the named bundles on the given hosts, in place of
+// the hosts the service provides. Bindings are kept by the router across
+// restarts. UpdateNOOP only returns the current bindings.
 type UpdateReq struct {
 	Action UpdateAction `protobuf:"varint,1,opt,name=Action,enum=api.UpdateAction" json:"Action,omitempty"`
-	Bind   string       `protobuf:"bytes,2,opt,name=Bind" json:"Bind,omitempty"`
-	Host   []string     `protobuf:"bytes,3,rep,name=Host" json:"Host,omitempty"`
-	Bundle []string     `protobuf:"bytes,4,rep,name=Bundle" json:"Bundle,omitempty"`
+	// Bind is the unique name of the binding.
+	Bind string   `protobuf:"bytes,2,opt,name=Bind" json:"Bind,omitempty"`
+	Host []string `protobuf:"bytes,3,rep,name=Host" json:"Host,omitempty"`
+	// Bundle lists the full names of the login bundle resources that
+	// identify the application.
+	Bundle []string `protobuf:"bytes,4,rep,name=Bundle" json:"Bundle,omitempty"`
 }
 
 func (m *UpdateReq) Reset()                    { *m = UpdateReq{} }
@@ -123,6 +142,8 @@ func (m *UpdateReq) GetBundle() []string {
 }
 
 type UpdateResp struct {
+	// Binding lists all bindings after the update.
+	Binding []*Binding `protobuf:"bytes,1,rep,name=Binding" json:"Binding,omitempty"`
 }
 
 func (m *UpdateResp) Reset()                    { *m = UpdateResp{} }
@@ -130,6 +151,476 @@ func (m *UpdateResp) String() string            { return proto.CompactTextString
 func (*UpdateResp) ProtoMessage()               {}
 func (*UpdateResp) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{2} }
 
+func (m *UpdateResp) GetBinding() []*Binding {
+	if m != nil {
+		return m.Binding
+	}
+	return nil
+}
+
+type RotateTokenKeyReq struct {
+}
+
+func (m *RotateTokenKeyReq) Reset()                    { *m = RotateTokenKeyReq{} }
+func (m *RotateTokenKeyReq) String() string            { return proto.CompactTextString(m) }
+func (*RotateTokenKeyReq) ProtoMessage()               {}
+func (*RotateTokenKeyReq) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{3} }
+
+type RotateTokenKeyResp struct {
+	// PreviousUntil is the end of the grace period of the previous key.
+	PreviousUntil *google_protobuf.Timestamp `protobuf:"bytes,1,opt,name=PreviousUntil" json:"PreviousUntil,omitempty"`
+}
+
+func (m *RotateTokenKeyResp) Reset()                    { *m = RotateTokenKeyResp{} }
+func (m *RotateTokenKeyResp) String() string            { return proto.CompactTextString(m) }
+func (*RotateTokenKeyResp) ProtoMessage()               {}
+func (*RotateTokenKeyResp) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{4} }
+
+func (m *RotateTokenKeyResp) GetPreviousUntil() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.PreviousUntil
+	}
+	return nil
+}
+
+type Binding struct {
+	Bind   string   `protobuf:"bytes,1,opt,name=Bind" json:"Bind,omitempty"`
+	Host   []string `protobuf:"bytes,2,rep,name=Host" json:"Host,omitempty"`
+	Bundle []string `protobuf:"bytes,3,rep,name=Bundle" json:"Bundle,omitempty"`
+}
+
+func (m *Binding) Reset()                    { *m = Binding{} }
+func (m *Binding) String() string            { return proto.CompactTextString(m) }
+func (*Binding) ProtoMessage()               {}
+func (*Binding) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{5} }
+
+func (m *Binding) GetBind() string {
+	if m != nil {
+		return m.Bind
+	}
+	return ""
+}
+
+func (m *Binding) GetHost() []string {
+	if m != nil {
+		return m.Host
+	}
+	return nil
+}
+
+func (m *Binding) GetBundle() []string {
+	if m != nil {
+		return m.Bundle
+	}
+	return nil
+}
+
+type StatusReq struct {
+}
+
+func (m *StatusReq) Reset()                    { *m = StatusReq{} }
+func (m *StatusReq) String() string            { return proto.CompactTextString(m) }
+func (*StatusReq) ProtoMessage()               {}
+func (*StatusReq) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{6} }
+
+type StatusResp struct {
+	// Version of the current router run. Empty if not yet configured.
+	Version string       `protobuf:"bytes,1,opt,name=Version" json:"Version,omitempty"`
+	App     []*StatusApp `protobuf:"bytes,2,rep,name=App" json:"App,omitempty"`
+	// Errors found when composing the current router run.
+	// The components in error are disabled.
+	Error []*StatusError `protobuf:"bytes,3,rep,name=Error" json:"Error,omitempty"`
+	// Service lists the connected services, including services not
+	// in the current router run.
+	Service []*StatusService `protobuf:"bytes,4,rep,name=Service" json:"Service,omitempty"`
+}
+
+func (m *StatusResp) Reset()                    { *m = StatusResp{} }
+func (m *StatusResp) String() string            { return proto.CompactTextString(m) }
+func (*StatusResp) ProtoMessage()               {}
+func (*StatusResp) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{7} }
+
+func (m *StatusResp) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *StatusResp) GetApp() []*StatusApp {
+	if m != nil {
+		return m.App
+	}
+	return nil
+}
+
+func (m *StatusResp) GetError() []*StatusError {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+func (m *StatusResp) GetService() []*StatusService {
+	if m != nil {
+		return m.Service
+	}
+	return nil
+}
+
+type StatusService struct {
+	Name    string `protobuf:"bytes,1,opt,name=Name" json:"Name,omitempty"`
+	Address string `protobuf:"bytes,2,opt,name=Address" json:"Address,omitempty"`
+	// State of the connection to the service, such as "READY".
+	State string `protobuf:"bytes,3,opt,name=State" json:"State,omitempty"`
+}
+
+func (m *StatusService) Reset()                    { *m = StatusService{} }
+func (m *StatusService) String() string            { return proto.CompactTextString(m) }
+func (*StatusService) ProtoMessage()               {}
+func (*StatusService) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{8} }
+
+func (m *StatusService) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *StatusService) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *StatusService) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+type StatusApp struct {
+	Service     string               `protobuf:"bytes,1,opt,name=Service" json:"Service,omitempty"`
+	Host        []string             `protobuf:"bytes,2,rep,name=Host" json:"Host,omitempty"`
+	Auth        string               `protobuf:"bytes,3,opt,name=Auth" json:"Auth,omitempty"`
+	LoginBundle []*StatusLoginBundle `protobuf:"bytes,4,rep,name=LoginBundle" json:"LoginBundle,omitempty"`
+	// Bind is the operator binding that serves the application, if any.
+	Bind string `protobuf:"bytes,5,opt,name=Bind" json:"Bind,omitempty"`
+}
+
+func (m *StatusApp) Reset()                    { *m = StatusApp{} }
+func (m *StatusApp) String() string            { return proto.CompactTextString(m) }
+func (*StatusApp) ProtoMessage()               {}
+func (*StatusApp) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{9} }
+
+func (m *StatusApp) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *StatusApp) GetHost() []string {
+	if m != nil {
+		return m.Host
+	}
+	return nil
+}
+
+func (m *StatusApp) GetAuth() string {
+	if m != nil {
+		return m.Auth
+	}
+	return ""
+}
+
+func (m *StatusApp) GetLoginBundle() []*StatusLoginBundle {
+	if m != nil {
+		return m.LoginBundle
+	}
+	return nil
+}
+
+func (m *StatusApp) GetBind() string {
+	if m != nil {
+		return m.Bind
+	}
+	return ""
+}
+
+type StatusLoginBundle struct {
+	LoginState      LoginState   `protobuf:"varint,1,opt,name=LoginState,enum=api.LoginState" json:"LoginState,omitempty"`
+	Prefix          string       `protobuf:"bytes,2,opt,name=Prefix" json:"Prefix,omitempty"`
+	ConsumeRedirect bool         `protobuf:"varint,3,opt,name=ConsumeRedirect" json:"ConsumeRedirect,omitempty"`
+	Bundle          string       `protobuf:"bytes,4,opt,name=Bundle" json:"Bundle,omitempty"`
+	URL             []*StatusURL `protobuf:"bytes,5,rep,name=URL" json:"URL,omitempty"`
+}
+
+func (m *StatusLoginBundle) Reset()                    { *m = StatusLoginBundle{} }
+func (m *StatusLoginBundle) String() string            { return proto.CompactTextString(m) }
+func (*StatusLoginBundle) ProtoMessage()               {}
+func (*StatusLoginBundle) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{10} }
+
+func (m *StatusLoginBundle) GetLoginState() LoginState {
+	if m != nil {
+		return m.LoginState
+	}
+	return LoginState_Missing
+}
+
+func (m *StatusLoginBundle) GetPrefix() string {
+	if m != nil {
+		return m.Prefix
+	}
+	return ""
+}
+
+func (m *StatusLoginBundle) GetConsumeRedirect() bool {
+	if m != nil {
+		return m.ConsumeRedirect
+	}
+	return false
+}
+
+func (m *StatusLoginBundle) GetBundle() string {
+	if m != nil {
+		return m.Bundle
+	}
+	return ""
+}
+
+func (m *StatusLoginBundle) GetURL() []*StatusURL {
+	if m != nil {
+		return m.URL
+	}
+	return nil
+}
+
+type StatusURL struct {
+	Pattern string `protobuf:"bytes,1,opt,name=Pattern" json:"Pattern,omitempty"`
+	// Resource is the URL resource the pattern routes to.
+	Resource string `protobuf:"bytes,2,opt,name=Resource" json:"Resource,omitempty"`
+	Stream   bool   `protobuf:"varint,3,opt,name=Stream" json:"Stream,omitempty"`
+	// Role lists the role names required, one of each set separated by "|".
+	Role []string `protobuf:"bytes,4,rep,name=Role" json:"Role,omitempty"`
+	// Elevate is true if the URL requires an elevated session.
+	Elevate bool `protobuf:"varint,5,opt,name=Elevate" json:"Elevate,omitempty"`
+}
+
+func (m *StatusURL) Reset()                    { *m = StatusURL{} }
+func (m *StatusURL) String() string            { return proto.CompactTextString(m) }
+func (*StatusURL) ProtoMessage()               {}
+func (*StatusURL) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{11} }
+
+func (m *StatusURL) GetPattern() string {
+	if m != nil {
+		return m.Pattern
+	}
+	return ""
+}
+
+func (m *StatusURL) GetResource() string {
+	if m != nil {
+		return m.Resource
+	}
+	return ""
+}
+
+func (m *StatusURL) GetStream() bool {
+	if m != nil {
+		return m.Stream
+	}
+	return false
+}
+
+func (m *StatusURL) GetRole() []string {
+	if m != nil {
+		return m.Role
+	}
+	return nil
+}
+
+func (m *StatusURL) GetElevate() bool {
+	if m != nil {
+		return m.Elevate
+	}
+	return false
+}
+
+type StatusError struct {
+	Kind     string   `protobuf:"bytes,1,opt,name=Kind" json:"Kind,omitempty"`
+	Service  []string `protobuf:"bytes,2,rep,name=Service" json:"Service,omitempty"`
+	Host     string   `protobuf:"bytes,3,opt,name=Host" json:"Host,omitempty"`
+	Resource []string `protobuf:"bytes,4,rep,name=Resource" json:"Resource,omitempty"`
+	URL      string   `protobuf:"bytes,5,opt,name=URL" json:"URL,omitempty"`
+	Message  string   `protobuf:"bytes,6,opt,name=Message" json:"Message,omitempty"`
+	Bind     string   `protobuf:"bytes,7,opt,name=Bind" json:"Bind,omitempty"`
+}
+
+func (m *StatusError) Reset()                    { *m = StatusError{} }
+func (m *StatusError) String() string            { return proto.CompactTextString(m) }
+func (*StatusError) ProtoMessage()               {}
+func (*StatusError) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{12} }
+
+func (m *StatusError) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *StatusError) GetService() []string {
+	if m != nil {
+		return m.Service
+	}
+	return nil
+}
+
+func (m *StatusError) GetHost() string {
+	if m != nil {
+		return m.Host
+	}
+	return ""
+}
+
+func (m *StatusError) GetResource() []string {
+	if m != nil {
+		return m.Resource
+	}
+	return nil
+}
+
+func (m *StatusError) GetURL() string {
+	if m != nil {
+		return m.URL
+	}
+	return ""
+}
+
+func (m *StatusError) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *StatusError) GetBind() string {
+	if m != nil {
+		return m.Bind
+	}
+	return ""
+}
+
+type ResourcesReq struct {
+}
+
+func (m *ResourcesReq) Reset()                    { *m = ResourcesReq{} }
+func (m *ResourcesReq) String() string            { return proto.CompactTextString(m) }
+func (*ResourcesReq) ProtoMessage()               {}
+func (*ResourcesReq) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{13} }
+
+type ResourcesResp struct {
+	Version  string            `protobuf:"bytes,1,opt,name=Version" json:"Version,omitempty"`
+	Resource []*StatusResource `protobuf:"bytes,2,rep,name=Resource" json:"Resource,omitempty"`
+}
+
+func (m *ResourcesResp) Reset()                    { *m = ResourcesResp{} }
+func (m *ResourcesResp) String() string            { return proto.CompactTextString(m) }
+func (*ResourcesResp) ProtoMessage()               {}
+func (*ResourcesResp) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{14} }
+
+func (m *ResourcesResp) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *ResourcesResp) GetResource() []*StatusResource {
+	if m != nil {
+		return m.Resource
+	}
+	return nil
+}
+
+type StatusResource struct {
+	// Name is the full resource name, including the service name.
+	Name    string `protobuf:"bytes,1,opt,name=Name" json:"Name,omitempty"`
+	Service string `protobuf:"bytes,2,opt,name=Service" json:"Service,omitempty"`
+	Type    string `protobuf:"bytes,3,opt,name=Type" json:"Type,omitempty"`
+	Consume string `protobuf:"bytes,4,opt,name=Consume" json:"Consume,omitempty"`
+	Parent  string `protobuf:"bytes,5,opt,name=Parent" json:"Parent,omitempty"`
+	// Include lists the resolved included resources.
+	Include []string `protobuf:"bytes,6,rep,name=Include" json:"Include,omitempty"`
+	// Role names that may use the resource.
+	Role []string `protobuf:"bytes,7,rep,name=Role" json:"Role,omitempty"`
+	// Elevate is true if the resource requires an elevated session.
+	Elevate bool `protobuf:"varint,8,opt,name=Elevate" json:"Elevate,omitempty"`
+}
+
+func (m *StatusResource) Reset()                    { *m = StatusResource{} }
+func (m *StatusResource) String() string            { return proto.CompactTextString(m) }
+func (*StatusResource) ProtoMessage()               {}
+func (*StatusResource) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{15} }
+
+func (m *StatusResource) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *StatusResource) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *StatusResource) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *StatusResource) GetConsume() string {
+	if m != nil {
+		return m.Consume
+	}
+	return ""
+}
+
+func (m *StatusResource) GetParent() string {
+	if m != nil {
+		return m.Parent
+	}
+	return ""
+}
+
+func (m *StatusResource) GetInclude() []string {
+	if m != nil {
+		return m.Include
+	}
+	return nil
+}
+
+func (m *StatusResource) GetRole() []string {
+	if m != nil {
+		return m.Role
+	}
+	return nil
+}
+
+func (m *StatusResource) GetElevate() bool {
+	if m != nil {
+		return m.Elevate
+	}
+	return false
+}
+
 type ServiceConfigEndpoint struct {
 	Name     string      `protobuf:"bytes,1,opt,name=Name" json:"Name,omitempty"`
 	Endpoint string      `protobuf:"bytes,2,opt,name=Endpoint" json:"Endpoint,omitempty"`
@@ -139,7 +630,7 @@ type ServiceConfigEndpoint struct {
 func (m *ServiceConfigEndpoint) Reset()                    { *m = ServiceConfigEndpoint{} }
 func (m *ServiceConfigEndpoint) String() string            { return proto.CompactTextString(m) }
 func (*ServiceConfigEndpoint) ProtoMessage()               {}
-func (*ServiceConfigEndpoint) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{3} }
+func (*ServiceConfigEndpoint) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{16} }
 
 func (m *ServiceConfigEndpoint) GetName() string {
 	if m != nil {
@@ -171,7 +662,7 @@ type ServiceConfig struct {
 func (m *ServiceConfig) Reset()                    { *m = ServiceConfig{} }
 func (m *ServiceConfig) String() string            { return proto.CompactTextString(m) }
 func (*ServiceConfig) ProtoMessage()               {}
-func (*ServiceConfig) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{4} }
+func (*ServiceConfig) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{17} }
 
 func (m *ServiceConfig) GetVersion() string {
 	if m != nil {
@@ -202,12 +693,21 @@ type Resource struct {
 	Configuration []byte `protobuf:"bytes,5,opt,name=Configuration,proto3" json:"Configuration,omitempty"`
 	// Include list of other resources this bundle should include.
 	Include []string `protobuf:"bytes,6,rep,name=Include" json:"Include,omitempty"`
+	// Role names that may use the resource. A user must have at least
+	// one of the roles. Names are resolved with the role catalog of the
+	// application authenticator. Roles of the parent resource must also
+	// be satisfied.
+	Role []string `protobuf:"bytes,7,rep,name=Role" json:"Role,omitempty"`
+	// Elevate marks a sensitive resource. It may only be used by a session
+	// that re-authenticated recently, see Auth.Elevate. A resource with an
+	// elevated parent also requires elevation.
+	Elevate bool `protobuf:"varint,8,opt,name=Elevate" json:"Elevate,omitempty"`
 }
 
 func (m *Resource) Reset()                    { *m = Resource{} }
 func (m *Resource) String() string            { return proto.CompactTextString(m) }
 func (*Resource) ProtoMessage()               {}
-func (*Resource) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{5} }
+func (*Resource) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{18} }
 
 func (m *Resource) GetName() string {
 	if m != nil {
@@ -251,6 +751,45 @@ func (m *Resource) GetInclude() []string {
 	return nil
 }
 
+func (m *Resource) GetRole() []string {
+	if m != nil {
+		return m.Role
+	}
+	return nil
+}
+
+func (m *Resource) GetElevate() bool {
+	if m != nil {
+		return m.Elevate
+	}
+	return false
+}
+
+// Role maps a role name to the role ID returned by the authenticator.
+type Role struct {
+	ID   int64  `protobuf:"varint,1,opt,name=ID" json:"ID,omitempty"`
+	Name string `protobuf:"bytes,2,opt,name=Name" json:"Name,omitempty"`
+}
+
+func (m *Role) Reset()                    { *m = Role{} }
+func (m *Role) String() string            { return proto.CompactTextString(m) }
+func (*Role) ProtoMessage()               {}
+func (*Role) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{19} }
+
+func (m *Role) GetID() int64 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *Role) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
 type LoginBundle struct {
 	LoginState      LoginState `protobuf:"varint,1,opt,name=LoginState,enum=api.LoginState" json:"LoginState,omitempty"`
 	Prefix          string     `protobuf:"bytes,2,opt,name=Prefix" json:"Prefix,omitempty"`
@@ -261,7 +800,7 @@ type LoginBundle struct {
 func (m *LoginBundle) Reset()                    { *m = LoginBundle{} }
 func (m *LoginBundle) String() string            { return proto.CompactTextString(m) }
 func (*LoginBundle) ProtoMessage()               {}
-func (*LoginBundle) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{6} }
+func (*LoginBundle) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{20} }
 
 func (m *LoginBundle) GetLoginState() LoginState {
 	if m != nil {
@@ -291,6 +830,70 @@ func (m *LoginBundle) GetResource() string {
 	return ""
 }
 
+type ApplicationTLS struct {
+	// CertFile and KeyFile are PEM encoded files on the router host.
+	// They are reloaded when changed.
+	CertFile string `protobuf:"bytes,1,opt,name=CertFile" json:"CertFile,omitempty"`
+	KeyFile  string `protobuf:"bytes,2,opt,name=KeyFile" json:"KeyFile,omitempty"`
+	// ACME requests certificates for the application hosts automatically.
+	// Used when CertFile is not set.
+	ACME bool `protobuf:"varint,3,opt,name=ACME" json:"ACME,omitempty"`
+	// ACMEDirectory is the directory URL of the ACME server.
+	// If empty Let's Encrypt is used.
+	ACMEDirectory string `protobuf:"bytes,4,opt,name=ACMEDirectory" json:"ACMEDirectory,omitempty"`
+	// ACMEEmail is the contact address sent to the ACME server.
+	ACMEEmail string `protobuf:"bytes,5,opt,name=ACMEEmail" json:"ACMEEmail,omitempty"`
+	// RedirectHTTP redirects plain HTTP requests to HTTPS.
+	RedirectHTTP bool `protobuf:"varint,6,opt,name=RedirectHTTP" json:"RedirectHTTP,omitempty"`
+}
+
+func (m *ApplicationTLS) Reset()                    { *m = ApplicationTLS{} }
+func (m *ApplicationTLS) String() string            { return proto.CompactTextString(m) }
+func (*ApplicationTLS) ProtoMessage()               {}
+func (*ApplicationTLS) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{21} }
+
+func (m *ApplicationTLS) GetCertFile() string {
+	if m != nil {
+		return m.CertFile
+	}
+	return ""
+}
+
+func (m *ApplicationTLS) GetKeyFile() string {
+	if m != nil {
+		return m.KeyFile
+	}
+	return ""
+}
+
+func (m *ApplicationTLS) GetACME() bool {
+	if m != nil {
+		return m.ACME
+	}
+	return false
+}
+
+func (m *ApplicationTLS) GetACMEDirectory() string {
+	if m != nil {
+		return m.ACMEDirectory
+	}
+	return ""
+}
+
+func (m *ApplicationTLS) GetACMEEmail() string {
+	if m != nil {
+		return m.ACMEEmail
+	}
+	return ""
+}
+
+func (m *ApplicationTLS) GetRedirectHTTP() bool {
+	if m != nil {
+		return m.RedirectHTTP
+	}
+	return false
+}
+
 type ApplicationBundle struct {
 	// Associate a login state with a single bundle.
 	LoginBundle []*LoginBundle `protobuf:"bytes,5,rep,name=LoginBundle" json:"LoginBundle,omitempty"`
@@ -298,12 +901,14 @@ type ApplicationBundle struct {
 	AuthConfiguredResource string `protobuf:"bytes,6,opt,name=AuthConfiguredResource" json:"AuthConfiguredResource,omitempty"`
 	// Setup the host names to bind to.
 	Host []string `protobuf:"bytes,7,rep,name=Host" json:"Host,omitempty"`
+	// TLS configures HTTPS for the application hosts.
+	TLS *ApplicationTLS `protobuf:"bytes,8,opt,name=TLS" json:"TLS,omitempty"`
 }
 
 func (m *ApplicationBundle) Reset()                    { *m = ApplicationBundle{} }
 func (m *ApplicationBundle) String() string            { return proto.CompactTextString(m) }
 func (*ApplicationBundle) ProtoMessage()               {}
-func (*ApplicationBundle) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{7} }
+func (*ApplicationBundle) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{22} }
 
 func (m *ApplicationBundle) GetLoginBundle() []*LoginBundle {
 	if m != nil {
@@ -326,6 +931,13 @@ func (m *ApplicationBundle) GetHost() []string {
 	return nil
 }
 
+func (m *ApplicationBundle) GetTLS() *ApplicationTLS {
+	if m != nil {
+		return m.TLS
+	}
+	return nil
+}
+
 type ServiceBundle struct {
 	// Name is the base name for this service.
 	// If something references  "solidcoredata.org/example-1/app" and the base
@@ -338,12 +950,15 @@ type ServiceBundle struct {
 	// Bundle Login bundles together with an auth configured resource and
 	// host name list to define an application that can be served.
 	Application []*ApplicationBundle `protobuf:"bytes,5,rep,name=Application" json:"Application,omitempty"`
+	// Role catalog of an authentication service. Resources of applications
+	// that use the service refer to the roles by name.
+	Role []*Role `protobuf:"bytes,6,rep,name=Role" json:"Role,omitempty"`
 }
 
 func (m *ServiceBundle) Reset()                    { *m = ServiceBundle{} }
 func (m *ServiceBundle) String() string            { return proto.CompactTextString(m) }
 func (*ServiceBundle) ProtoMessage()               {}
-func (*ServiceBundle) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{8} }
+func (*ServiceBundle) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{23} }
 
 func (m *ServiceBundle) GetName() string {
 	if m != nil {
@@ -366,14 +981,36 @@ func (m *ServiceBundle) GetApplication() []*ApplicationBundle {
 	return nil
 }
 
+func (m *ServiceBundle) GetRole() []*Role {
+	if m != nil {
+		return m.Role
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*NotifyReq)(nil), "api.NotifyReq")
 	proto.RegisterType((*UpdateReq)(nil), "api.UpdateReq")
 	proto.RegisterType((*UpdateResp)(nil), "api.UpdateResp")
+	proto.RegisterType((*RotateTokenKeyReq)(nil), "api.RotateTokenKeyReq")
+	proto.RegisterType((*RotateTokenKeyResp)(nil), "api.RotateTokenKeyResp")
+	proto.RegisterType((*Binding)(nil), "api.Binding")
+	proto.RegisterType((*StatusReq)(nil), "api.StatusReq")
+	proto.RegisterType((*StatusResp)(nil), "api.StatusResp")
+	proto.RegisterType((*StatusService)(nil), "api.StatusService")
+	proto.RegisterType((*StatusApp)(nil), "api.StatusApp")
+	proto.RegisterType((*StatusLoginBundle)(nil), "api.StatusLoginBundle")
+	proto.RegisterType((*StatusURL)(nil), "api.StatusURL")
+	proto.RegisterType((*StatusError)(nil), "api.StatusError")
+	proto.RegisterType((*ResourcesReq)(nil), "api.ResourcesReq")
+	proto.RegisterType((*ResourcesResp)(nil), "api.ResourcesResp")
+	proto.RegisterType((*StatusResource)(nil), "api.StatusResource")
 	proto.RegisterType((*ServiceConfigEndpoint)(nil), "api.ServiceConfigEndpoint")
 	proto.RegisterType((*ServiceConfig)(nil), "api.ServiceConfig")
 	proto.RegisterType((*Resource)(nil), "api.Resource")
+	proto.RegisterType((*Role)(nil), "api.Role")
 	proto.RegisterType((*LoginBundle)(nil), "api.LoginBundle")
+	proto.RegisterType((*ApplicationTLS)(nil), "api.ApplicationTLS")
 	proto.RegisterType((*ApplicationBundle)(nil), "api.ApplicationBundle")
 	proto.RegisterType((*ServiceBundle)(nil), "api.ServiceBundle")
 	proto.RegisterEnum("api.UpdateAction", UpdateAction_name, UpdateAction_value)
@@ -520,6 +1157,14 @@ var _Routes_serviceDesc = grpc.ServiceDesc{
 type RouterConfigurationClient interface {
 	Notify(ctx context.Context, in *NotifyReq, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
 	Update(ctx context.Context, in *UpdateReq, opts ...grpc.CallOption) (*UpdateResp, error)
+	// RotateTokenKey replaces the secret key session cookie names are
+	// derived from. Cookies named with the previous key are accepted until
+	// the grace period ends.
+	RotateTokenKey(ctx context.Context, in *RotateTokenKeyReq, opts ...grpc.CallOption) (*RotateTokenKeyResp, error)
+	// Status of the current router run.
+	Status(ctx context.Context, in *StatusReq, opts ...grpc.CallOption) (*StatusResp, error)
+	// Resources resolved in the current router run.
+	Resources(ctx context.Context, in *ResourcesReq, opts ...grpc.CallOption) (*ResourcesResp, error)
 }
 
 type routerConfigurationClient struct {
@@ -548,11 +1193,46 @@ func (c *routerConfigurationClient) Update(ctx context.Context, in *UpdateReq, o
 	return out, nil
 }
 
+func (c *routerConfigurationClient) RotateTokenKey(ctx context.Context, in *RotateTokenKeyReq, opts ...grpc.CallOption) (*RotateTokenKeyResp, error) {
+	out := new(RotateTokenKeyResp)
+	err := grpc.Invoke(ctx, "/api.RouterConfiguration/RotateTokenKey", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerConfigurationClient) Status(ctx context.Context, in *StatusReq, opts ...grpc.CallOption) (*StatusResp, error) {
+	out := new(StatusResp)
+	err := grpc.Invoke(ctx, "/api.RouterConfiguration/Status", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerConfigurationClient) Resources(ctx context.Context, in *ResourcesReq, opts ...grpc.CallOption) (*ResourcesResp, error) {
+	out := new(ResourcesResp)
+	err := grpc.Invoke(ctx, "/api.RouterConfiguration/Resources", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for RouterConfiguration service
 
 type RouterConfigurationServer interface {
 	Notify(context.Context, *NotifyReq) (*google_protobuf1.Empty, error)
 	Update(context.Context, *UpdateReq) (*UpdateResp, error)
+	// RotateTokenKey replaces the secret key session cookie names are
+	// derived from. Cookies named with the previous key are accepted until
+	// the grace period ends.
+	RotateTokenKey(context.Context, *RotateTokenKeyReq) (*RotateTokenKeyResp, error)
+	// Status of the current router run.
+	Status(context.Context, *StatusReq) (*StatusResp, error)
+	// Resources resolved in the current router run.
+	Resources(context.Context, *ResourcesReq) (*ResourcesResp, error)
 }
 
 func RegisterRouterConfigurationServer(s *grpc.Server, srv RouterConfigurationServer) {
@@ -595,6 +1275,60 @@ func _RouterConfiguration_Update_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RouterConfiguration_RotateTokenKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RotateTokenKeyReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterConfigurationServer).RotateTokenKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.RouterConfiguration/RotateTokenKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterConfigurationServer).RotateTokenKey(ctx, req.(*RotateTokenKeyReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RouterConfiguration_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterConfigurationServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.RouterConfiguration/Status",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterConfigurationServer).Status(ctx, req.(*StatusReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RouterConfiguration_Resources_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourcesReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterConfigurationServer).Resources(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.RouterConfiguration/Resources",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterConfigurationServer).Resources(ctx, req.(*ResourcesReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _RouterConfiguration_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "api.RouterConfiguration",
 	HandlerType: (*RouterConfigurationServer)(nil),
@@ -607,6 +1341,18 @@ var _RouterConfiguration_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Update",
 			Handler:    _RouterConfiguration_Update_Handler,
 		},
+		{
+			MethodName: "RotateTokenKey",
+			Handler:    _RouterConfiguration_RotateTokenKey_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _RouterConfiguration_Status_Handler,
+		},
+		{
+			MethodName: "Resources",
+			Handler:    _RouterConfiguration_Resources_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "router.proto",
@@ -615,47 +1361,85 @@ var _RouterConfiguration_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("router.proto", fileDescriptor2) }
 
 var fileDescriptor2 = []byte{
-	// 666 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x54, 0x4d, 0x4f, 0xdb, 0x4c,
-	0x10, 0xc6, 0x09, 0x18, 0x32, 0x09, 0x89, 0x59, 0xf4, 0x46, 0x96, 0xdf, 0x0b, 0xb2, 0xaa, 0x0a,
-	0x38, 0x18, 0x14, 0xa4, 0xaa, 0xd7, 0x40, 0x91, 0x8a, 0x84, 0x00, 0x2d, 0x6d, 0xef, 0x26, 0x9e,
-	0x04, 0x4b, 0x89, 0xd7, 0x5d, 0xaf, 0x51, 0xf9, 0x07, 0x95, 0x7a, 0xa9, 0xd4, 0x6b, 0x8f, 0xfd,
-	0xa1, 0xd5, 0x8e, 0xd7, 0x76, 0x1c, 0x48, 0x6f, 0x3b, 0x33, 0xcf, 0x7c, 0x3c, 0x33, 0x3b, 0x03,
-	0x3d, 0x29, 0x72, 0x85, 0x32, 0x48, 0xa5, 0x50, 0x82, 0xb5, 0xc3, 0x34, 0xf6, 0xfe, 0x9f, 0x09,
-	0x31, 0x9b, 0xe3, 0x09, 0xa9, 0x1e, 0xf2, 0xe9, 0x09, 0x2e, 0x52, 0xf5, 0x5c, 0x20, 0x3c, 0x08,
-	0x73, 0xf5, 0x58, 0xbc, 0xfd, 0x33, 0xe8, 0xdc, 0x08, 0x15, 0x4f, 0x9f, 0x39, 0x7e, 0x65, 0x6f,
-	0xa1, 0x7f, 0x8f, 0xf2, 0x29, 0x9e, 0xe0, 0x38, 0x8a, 0x24, 0x66, 0x99, 0x6b, 0x1d, 0x58, 0x87,
-	0x1d, 0xbe, 0xa2, 0xf5, 0x9f, 0xa0, 0xf3, 0x39, 0x8d, 0x42, 0x85, 0xda, 0xe9, 0x08, 0xec, 0xf1,
-	0x44, 0xc5, 0x22, 0x21, 0x70, 0x7f, 0xb4, 0x17, 0x84, 0x69, 0x1c, 0x14, 0xf6, 0xc2, 0xc0, 0x0d,
-	0x80, 0x31, 0xd8, 0x3c, 0x8f, 0x93, 0xc8, 0x6d, 0x51, 0x54, 0x7a, 0x6b, 0xdd, 0x47, 0x91, 0x29,
-	0xb7, 0x7d, 0xd0, 0xd6, 0x3a, 0xfd, 0x66, 0x43, 0xb0, 0xcf, 0xf3, 0x24, 0x9a, 0xa3, 0xbb, 0x49,
-	0x5a, 0x23, 0xf9, 0x3d, 0x80, 0x32, 0x6f, 0x96, 0xfa, 0x12, 0xfe, 0x33, 0x75, 0x5d, 0x88, 0x64,
-	0x1a, 0xcf, 0x2e, 0x93, 0x28, 0x15, 0x71, 0xa2, 0x74, 0xc8, 0x9b, 0x70, 0x81, 0xa6, 0x78, 0x7a,
-	0x33, 0x0f, 0x76, 0x4a, 0xbb, 0x49, 0x5f, 0xc9, 0xec, 0x08, 0x76, 0x38, 0x66, 0x22, 0x97, 0x13,
-	0xa4, 0x32, 0xba, 0xa3, 0x5d, 0xe2, 0x50, 0x2a, 0x79, 0x65, 0xf6, 0x7f, 0x58, 0xb0, 0xdb, 0x48,
-	0xca, 0x5c, 0xd8, 0xfe, 0x82, 0x32, 0x2b, 0xf9, 0x77, 0x78, 0x29, 0xb2, 0xd3, 0xaa, 0x31, 0x2d,
-	0x6a, 0x8c, 0x4b, 0x41, 0x1b, 0xde, 0x2b, 0xfd, 0x09, 0x60, 0xf3, 0x3a, 0x36, 0xbd, 0xe8, 0x8e,
-	0xbc, 0x97, 0xf8, 0xb2, 0x64, 0x4e, 0x38, 0xff, 0x8f, 0x55, 0x57, 0xfe, 0x2a, 0xeb, 0x21, 0xd8,
-	0x77, 0xa1, 0xc4, 0x8a, 0xb3, 0x91, 0x34, 0xf6, 0xd3, 0x73, 0xaa, 0xd9, 0x12, 0x56, 0xbf, 0x35,
-	0x91, 0x0b, 0x91, 0x64, 0xf9, 0x42, 0x77, 0x9d, 0x88, 0x18, 0x91, 0xbd, 0x81, 0xdd, 0x22, 0x7d,
-	0x2e, 0x43, 0xe2, 0xb3, 0x75, 0x60, 0x1d, 0xf6, 0x78, 0x53, 0xa9, 0xfd, 0xaf, 0x92, 0xc9, 0x3c,
-	0x8f, 0xd0, 0xb5, 0x69, 0x6a, 0xa5, 0xe8, 0xff, 0xb6, 0xa0, 0x7b, 0x2d, 0x66, 0x71, 0x52, 0x8c,
-	0x91, 0x9d, 0x00, 0x90, 0x78, 0xaf, 0x42, 0x85, 0xe6, 0xd7, 0x0c, 0x88, 0x6c, 0xad, 0xe6, 0x4b,
-	0x10, 0xa2, 0x21, 0x71, 0x1a, 0x7f, 0xab, 0x68, 0x90, 0xc4, 0x0e, 0x61, 0x60, 0x6a, 0xe4, 0x18,
-	0xc5, 0x12, 0x27, 0x8a, 0x18, 0xed, 0xf0, 0x55, 0xb5, 0x1e, 0x7f, 0x35, 0xe2, 0x82, 0x5d, 0x3d,
-	0xd3, 0x5f, 0x16, 0xec, 0x8d, 0xd3, 0x74, 0x1e, 0x4f, 0x88, 0x88, 0x29, 0x72, 0xd4, 0xa8, 0xd9,
-	0xdd, 0xa2, 0x91, 0x38, 0x75, 0x95, 0x85, 0x9e, 0x37, 0x88, 0xbd, 0x83, 0xe1, 0x38, 0x57, 0x8f,
-	0x65, 0x5f, 0x30, 0xaa, 0x72, 0xda, 0x94, 0x73, 0x8d, 0xb5, 0xda, 0x81, 0xed, 0x7a, 0x07, 0xfc,
-	0xef, 0xf5, 0x4f, 0x33, 0xd1, 0x5f, 0x1b, 0xf0, 0xf2, 0xd7, 0x6d, 0xfd, 0xf3, 0xeb, 0xb2, 0xf7,
-	0xd0, 0x5d, 0x62, 0x69, 0x08, 0x0d, 0x09, 0xfd, 0x82, 0x3d, 0x5f, 0x86, 0x1e, 0xdf, 0x43, 0x6f,
-	0x79, 0x9d, 0x59, 0xbf, 0x5c, 0xc3, 0x9b, 0xdb, 0xdb, 0x3b, 0x67, 0x83, 0x39, 0xa5, 0xfd, 0x2a,
-	0xc9, 0x50, 0x2a, 0xc7, 0x62, 0x03, 0xe8, 0x1a, 0x8f, 0xb9, 0x42, 0xe9, 0xb4, 0x6a, 0xc8, 0x07,
-	0x9c, 0xa3, 0x42, 0xa7, 0x7d, 0x7c, 0x0c, 0xfb, 0xaf, 0xac, 0x02, 0xdb, 0x86, 0xf6, 0x38, 0x8a,
-	0x9c, 0x0d, 0x06, 0x60, 0x73, 0x5c, 0x88, 0x27, 0x74, 0xac, 0xd1, 0x4f, 0x0b, 0x6c, 0xae, 0x6f,
-	0x5c, 0xc6, 0x2e, 0x60, 0xbf, 0x08, 0xd4, 0xec, 0xcd, 0x30, 0x28, 0x0e, 0x5e, 0x50, 0x1e, 0xbc,
-	0xe0, 0x52, 0x1f, 0x3c, 0x8f, 0x2d, 0xef, 0x50, 0x81, 0x3d, 0xb5, 0xd8, 0x78, 0x25, 0x88, 0x59,
-	0x65, 0xf6, 0x72, 0xe1, 0xbc, 0x35, 0x81, 0x47, 0x12, 0xf6, 0xa9, 0x22, 0xd9, 0x5c, 0x82, 0x53,
-	0xb0, 0x8b, 0x73, 0xca, 0xfa, 0x14, 0xac, 0xba, 0xad, 0xeb, 0x02, 0xe9, 0xf3, 0x59, 0xd4, 0x62,
-	0x3c, 0xaa, 0xc3, 0xea, 0x0d, 0x1a, 0x72, 0x96, 0x3e, 0xd8, 0xe4, 0x7a, 0xf6, 0x37, 0x00, 0x00,
-	0xff, 0xff, 0x1d, 0xbb, 0x58, 0x3c, 0xf0, 0x05, 0x00, 0x00,
+	// 1267 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x57, 0x4b, 0x6f, 0x23, 0x45,
+	0x10, 0xde, 0xb1, 0xe3, 0x57, 0xf9, 0x11, 0xa7, 0x03, 0xc1, 0x32, 0x20, 0xa2, 0x11, 0x44, 0x49,
+	0x84, 0x9c, 0xc8, 0x20, 0xc4, 0x11, 0x6f, 0x62, 0xb4, 0x51, 0xbc, 0xd9, 0xa8, 0xed, 0xec, 0x81,
+	0xdb, 0xac, 0xdd, 0xf1, 0x8e, 0xb0, 0x67, 0x86, 0x99, 0x9e, 0x88, 0xfc, 0x02, 0x0e, 0x5c, 0x38,
+	0x70, 0x03, 0x71, 0xe5, 0xc0, 0x3f, 0xe0, 0x84, 0x38, 0x73, 0xe2, 0x17, 0xa1, 0xea, 0xc7, 0x4c,
+	0xb7, 0x1f, 0x2b, 0x21, 0x21, 0x71, 0x72, 0xd7, 0xa3, 0xbb, 0xaa, 0xbe, 0x7a, 0x8d, 0xa1, 0x11,
+	0x87, 0x29, 0x67, 0x71, 0x2f, 0x8a, 0x43, 0x1e, 0x92, 0xa2, 0x17, 0xf9, 0xdd, 0x77, 0xe7, 0x61,
+	0x38, 0x5f, 0xb0, 0x33, 0xc1, 0x7a, 0x95, 0xde, 0x9f, 0xb1, 0x65, 0xc4, 0x1f, 0xa5, 0x46, 0xf7,
+	0x83, 0x55, 0x21, 0xf7, 0x97, 0x2c, 0xe1, 0xde, 0x32, 0x52, 0x0a, 0xe0, 0xa5, 0xfc, 0xb5, 0x3c,
+	0xbb, 0xd7, 0x50, 0xbb, 0x09, 0xb9, 0x7f, 0xff, 0x48, 0xd9, 0x37, 0xe4, 0x08, 0x5a, 0x63, 0x16,
+	0x3f, 0xf8, 0x53, 0x36, 0x98, 0xcd, 0x62, 0x96, 0x24, 0x1d, 0xe7, 0xd0, 0x39, 0xae, 0xd1, 0x15,
+	0x2e, 0x39, 0x80, 0x32, 0x65, 0xcb, 0xf0, 0x81, 0x75, 0x0a, 0x87, 0xce, 0x71, 0x95, 0x2a, 0xca,
+	0x7d, 0x80, 0xda, 0x5d, 0x34, 0xf3, 0x38, 0xc3, 0xc7, 0x4e, 0xa0, 0x3c, 0x98, 0x72, 0x3f, 0x0c,
+	0xc4, 0x23, 0xad, 0xfe, 0x5e, 0xcf, 0x8b, 0xfc, 0x9e, 0x94, 0x4b, 0x01, 0x55, 0x0a, 0x84, 0xc0,
+	0xce, 0x53, 0x3f, 0x98, 0x89, 0xd7, 0x6a, 0x54, 0x9c, 0x91, 0xf7, 0x2c, 0x4c, 0x78, 0xa7, 0x78,
+	0x58, 0x44, 0x1e, 0x9e, 0xd1, 0xee, 0xd3, 0x34, 0x98, 0x2d, 0x58, 0x67, 0x47, 0x70, 0x15, 0xe5,
+	0x7e, 0x0a, 0xa0, 0xed, 0x26, 0x11, 0x39, 0x82, 0x0a, 0xbe, 0xe0, 0x07, 0xf3, 0x8e, 0x73, 0x58,
+	0x3c, 0xae, 0xf7, 0x1b, 0xc2, 0xb2, 0xe2, 0x51, 0x2d, 0x74, 0xf7, 0x61, 0x8f, 0x86, 0xdc, 0xe3,
+	0x6c, 0x12, 0x7e, 0xcd, 0x82, 0x6b, 0x86, 0x10, 0xb8, 0x2f, 0x81, 0xac, 0x32, 0x93, 0x88, 0x7c,
+	0x01, 0xcd, 0xdb, 0x98, 0x3d, 0xf8, 0x61, 0x9a, 0xdc, 0x05, 0xdc, 0x5f, 0x88, 0x90, 0xea, 0xfd,
+	0x6e, 0x4f, 0x42, 0xdd, 0xd3, 0x50, 0xf7, 0x26, 0x1a, 0x6a, 0x6a, 0x5f, 0x70, 0xaf, 0x32, 0xa7,
+	0xb2, 0x68, 0x9d, 0x0d, 0xd1, 0x16, 0x36, 0x46, 0x5b, 0xb4, 0xa2, 0xad, 0x43, 0x6d, 0xcc, 0x3d,
+	0x9e, 0x26, 0xe8, 0xef, 0x2f, 0x0e, 0x80, 0xa6, 0x92, 0x88, 0x74, 0xa0, 0xf2, 0x92, 0xc5, 0x89,
+	0x46, 0xbd, 0x46, 0x35, 0x49, 0x0e, 0xa1, 0x38, 0x88, 0x22, 0x61, 0xa0, 0xde, 0x6f, 0x09, 0x44,
+	0xe4, 0xbd, 0x41, 0x14, 0x51, 0x14, 0x91, 0x23, 0x28, 0x0d, 0xe3, 0x38, 0x8c, 0x85, 0xb9, 0x7a,
+	0xbf, 0x6d, 0xe8, 0x08, 0x3e, 0x95, 0x62, 0xf2, 0x31, 0x54, 0x54, 0x3d, 0x88, 0x34, 0xd4, 0xfb,
+	0xc4, 0xd0, 0x54, 0x12, 0xaa, 0x55, 0xdc, 0x31, 0x34, 0x2d, 0x09, 0x86, 0x7a, 0xe3, 0x2d, 0x99,
+	0x0e, 0x1f, 0xcf, 0xe8, 0xb6, 0xae, 0x38, 0x59, 0x03, 0x9a, 0x24, 0x6f, 0x41, 0x09, 0xaf, 0x23,
+	0x06, 0xc8, 0x97, 0x84, 0xfb, 0x93, 0xa3, 0x31, 0x40, 0xc7, 0x3b, 0xb9, 0x43, 0x2a, 0x68, 0xc3,
+	0xd6, 0x1a, 0xac, 0x04, 0x76, 0x06, 0x29, 0x7f, 0xad, 0x1e, 0x14, 0x67, 0xf2, 0x39, 0xd4, 0x47,
+	0xe1, 0xdc, 0x0f, 0x8c, 0xea, 0xaa, 0xf7, 0x0f, 0x8c, 0xb0, 0x0c, 0x29, 0x35, 0x55, 0xb3, 0x64,
+	0x96, 0xf2, 0x64, 0xba, 0x7f, 0x38, 0xb0, 0xb7, 0x76, 0x8d, 0x9c, 0x01, 0x08, 0x52, 0x86, 0x23,
+	0x7b, 0x62, 0x57, 0x98, 0xc8, 0xd9, 0xd4, 0x50, 0xc1, 0xfc, 0xdf, 0xc6, 0xec, 0xde, 0xff, 0x56,
+	0x61, 0xa2, 0x28, 0x72, 0x0c, 0xbb, 0x17, 0x61, 0x90, 0xa4, 0x4b, 0x46, 0xd9, 0xcc, 0x8f, 0xd9,
+	0x94, 0x8b, 0x58, 0xaa, 0x74, 0x95, 0x6d, 0xf5, 0x8b, 0x93, 0x57, 0x10, 0xd6, 0xc2, 0x1d, 0x1d,
+	0x75, 0x4a, 0x6b, 0xb5, 0x70, 0x47, 0x47, 0x14, 0x45, 0xee, 0x77, 0x19, 0xc0, 0x77, 0x74, 0x84,
+	0x00, 0xdf, 0x7a, 0x9c, 0xb3, 0x38, 0xab, 0x2a, 0x45, 0x92, 0x2e, 0x54, 0x29, 0x4b, 0xc2, 0x34,
+	0x9e, 0x32, 0xe5, 0x65, 0x46, 0xa3, 0xf5, 0x31, 0x8f, 0x99, 0xb7, 0x54, 0xee, 0x29, 0x0a, 0x21,
+	0xa3, 0x61, 0xd6, 0xc3, 0xe2, 0x8c, 0x16, 0x86, 0x0b, 0xf6, 0x80, 0xc8, 0x94, 0x84, 0xb2, 0x26,
+	0xdd, 0xdf, 0x1c, 0xa8, 0x1b, 0x45, 0x88, 0xb7, 0xaf, 0x8d, 0xee, 0xc1, 0xb3, 0x59, 0x00, 0x32,
+	0xd3, 0x6b, 0x05, 0xa0, 0x92, 0x8d, 0x67, 0xcb, 0x67, 0xe9, 0x43, 0xee, 0x73, 0x5b, 0x23, 0x83,
+	0xea, 0x45, 0x15, 0xfb, 0x73, 0x96, 0x24, 0xde, 0x9c, 0x75, 0xca, 0x32, 0x76, 0x45, 0x66, 0xa9,
+	0xaf, 0x18, 0xa9, 0x6f, 0x41, 0x43, 0xbf, 0x25, 0xda, 0xf3, 0x2b, 0x68, 0x1a, 0xf4, 0x1b, 0x1b,
+	0xf4, 0xcc, 0x82, 0x12, 0x33, 0xb3, 0x6f, 0x64, 0x46, 0x8b, 0x72, 0x5f, 0xdd, 0xbf, 0x1c, 0x68,
+	0xd9, 0xc2, 0x6d, 0xbd, 0x95, 0x83, 0xb3, 0xda, 0x1d, 0x93, 0xc7, 0x48, 0xb7, 0x96, 0x38, 0xa3,
+	0xb6, 0xaa, 0x22, 0x55, 0x33, 0x9a, 0x14, 0xe5, 0xe8, 0xc5, 0x2c, 0xe0, 0x0a, 0x1d, 0x45, 0xe1,
+	0x8d, 0xab, 0x60, 0xba, 0x48, 0x67, 0x08, 0x90, 0x00, 0x5f, 0x91, 0x59, 0xa2, 0x2b, 0x9b, 0x13,
+	0x5d, 0xb5, 0x13, 0x1d, 0xc3, 0xdb, 0xca, 0xb1, 0x8b, 0x30, 0xb8, 0xf7, 0xe7, 0xc3, 0x60, 0x16,
+	0x85, 0x7e, 0xc0, 0x37, 0x06, 0xd5, 0x85, 0xaa, 0x96, 0xeb, 0xba, 0xcb, 0xf4, 0x4f, 0x0c, 0x20,
+	0xe5, 0x28, 0x6b, 0x0a, 0x20, 0x37, 0x40, 0xf8, 0xbd, 0x03, 0x4d, 0xcb, 0xe8, 0x1b, 0xf2, 0x73,
+	0x9e, 0xed, 0xb3, 0x82, 0xe8, 0xdd, 0x8e, 0xcc, 0x8e, 0x79, 0x7b, 0x65, 0xad, 0xf5, 0x60, 0x67,
+	0xe4, 0xab, 0x15, 0x86, 0xcb, 0x62, 0x4d, 0x5f, 0xbb, 0x4c, 0x85, 0x9e, 0xfb, 0xb7, 0x93, 0x7b,
+	0xbe, 0x31, 0xea, 0x3c, 0x05, 0x05, 0x2b, 0x05, 0xff, 0x2e, 0x91, 0x1f, 0x42, 0x53, 0x9a, 0x4f,
+	0x63, 0x4f, 0xc4, 0x83, 0xf9, 0x6c, 0x50, 0x9b, 0xf9, 0x9f, 0xa5, 0xf5, 0x54, 0x6a, 0x93, 0x16,
+	0x14, 0xae, 0x2e, 0x45, 0x34, 0x45, 0x5a, 0xb8, 0xba, 0xcc, 0xe2, 0x2b, 0xe4, 0xf1, 0xb9, 0x3f,
+	0x3b, 0x50, 0xff, 0x9f, 0x47, 0xa6, 0x3d, 0x1c, 0xac, 0x81, 0xe6, 0xfe, 0xe9, 0x40, 0x6b, 0x10,
+	0x45, 0x0b, 0x7f, 0x2a, 0x20, 0x9a, 0x8c, 0xc6, 0xa8, 0x7e, 0xc1, 0x62, 0xfe, 0xa5, 0xbf, 0xd0,
+	0x99, 0xca, 0x68, 0xc4, 0xe4, 0x9a, 0x3d, 0x0a, 0x91, 0x6a, 0x3c, 0x45, 0x8a, 0x15, 0x74, 0xf1,
+	0x7c, 0xa8, 0x7c, 0x10, 0x67, 0xcc, 0x0a, 0xfe, 0x5e, 0x0a, 0x37, 0xc2, 0xf8, 0x51, 0x59, 0xb7,
+	0x99, 0xe4, 0x3d, 0xa8, 0x21, 0x63, 0xb8, 0xf4, 0xfc, 0x85, 0xea, 0xc3, 0x9c, 0x41, 0x5c, 0x68,
+	0xe8, 0x40, 0x9e, 0x4d, 0x26, 0xb7, 0x62, 0x60, 0x55, 0xa9, 0xc5, 0x73, 0x7f, 0x77, 0x60, 0xcf,
+	0x08, 0x42, 0x21, 0xdd, 0xb7, 0x17, 0x60, 0xc9, 0xf8, 0x02, 0xd8, 0xba, 0xfa, 0x3e, 0x83, 0x03,
+	0x5c, 0x9e, 0xba, 0x6c, 0xd8, 0x2c, 0x03, 0x4e, 0x0e, 0xca, 0x2d, 0xd2, 0x6c, 0x26, 0x57, 0x8c,
+	0xa5, 0xfc, 0x11, 0x14, 0x27, 0xa3, 0xb1, 0xa8, 0x1d, 0x3d, 0xf7, 0x6c, 0xa4, 0x29, 0xca, 0xdd,
+	0x5f, 0xf3, 0x7e, 0xcd, 0xf7, 0xef, 0x5a, 0x9b, 0x9c, 0xac, 0x4d, 0xd2, 0x6d, 0x03, 0x00, 0x17,
+	0xbf, 0x61, 0xa7, 0x53, 0x32, 0x16, 0xff, 0x1a, 0x48, 0xd4, 0x54, 0x25, 0xef, 0xab, 0x2e, 0x28,
+	0x8b, 0x2b, 0x35, 0x69, 0x20, 0x5c, 0x30, 0xd9, 0x10, 0xa7, 0x63, 0x68, 0x98, 0x9f, 0xba, 0xa4,
+	0xa5, 0x3f, 0x51, 0x6f, 0x5e, 0xbc, 0xb8, 0x6d, 0x3f, 0x21, 0x6d, 0x2d, 0xbf, 0x0a, 0x12, 0x16,
+	0xf3, 0xb6, 0x43, 0x76, 0xa1, 0xae, 0x6e, 0x2c, 0x38, 0x8b, 0xdb, 0x85, 0x5c, 0xe5, 0x92, 0x2d,
+	0x18, 0x67, 0xed, 0xe2, 0xe9, 0x29, 0xec, 0x6f, 0x98, 0x37, 0xa4, 0x02, 0xc5, 0xc1, 0x6c, 0xd6,
+	0x7e, 0x42, 0x40, 0x7f, 0x97, 0xb7, 0x9d, 0xfe, 0x0f, 0x0e, 0x94, 0x69, 0x98, 0x72, 0x96, 0x90,
+	0x0b, 0xd8, 0x97, 0x0f, 0xd9, 0xd0, 0x1d, 0xac, 0x7d, 0xbd, 0x0e, 0xf1, 0x5f, 0x44, 0x97, 0x98,
+	0x83, 0x4a, 0xea, 0x9e, 0x3b, 0x64, 0xb0, 0xf2, 0x88, 0x9a, 0x97, 0x64, 0x7d, 0xaa, 0x75, 0xb7,
+	0x3c, 0xdc, 0xff, 0xb1, 0x00, 0xfb, 0xc2, 0xa5, 0xd8, 0x1e, 0x35, 0xe7, 0x50, 0x96, 0xff, 0x41,
+	0x88, 0xfc, 0x16, 0xc9, 0xfe, 0x90, 0x6c, 0x7b, 0x09, 0xff, 0x5b, 0x48, 0x67, 0xd4, 0x8d, 0xec,
+	0x5f, 0x47, 0x77, 0xd7, 0xa2, 0x93, 0x88, 0x0c, 0xa0, 0x65, 0x7f, 0xd0, 0x93, 0x03, 0x95, 0xab,
+	0x95, 0x4f, 0xff, 0xee, 0x3b, 0x1b, 0xf9, 0x49, 0x84, 0xd6, 0xe4, 0x9e, 0x25, 0x2d, 0x6b, 0x23,
+	0x6b, 0x6b, 0xc6, 0xf7, 0x77, 0x1f, 0x6a, 0xd9, 0xbe, 0x27, 0x7b, 0x56, 0xd5, 0x89, 0x0b, 0x64,
+	0x95, 0x95, 0x44, 0xaf, 0xca, 0x22, 0xb8, 0x4f, 0xfe, 0x19, 0x00, 0x7a, 0x6d, 0x9b, 0xa3, 0xe8,
+	0x0d, 0x00, 0x00,
 }
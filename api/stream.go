@@ -0,0 +1,151 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// StreamChunkSize is the largest body chunk sent in a single stream message.
+const StreamChunkSize = 32 * 1024
+
+// HTTPStreamFunc handles a streaming HTTP request.
+// The request body is read from body. The response headers are set on w.Response
+// before the first call to w.Write.
+type HTTPStreamFunc func(ctx context.Context, r *HTTPRequest, body io.Reader, w *HTTPResponseWriter) error
+
+// ServeStream implements HTTPServer.ServeHTTPStream with f.
+func ServeStream(stream HTTP_ServeHTTPStreamServer, f HTTPStreamFunc) error {
+	msg, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	r := msg.GetRequest()
+	if r == nil {
+		return grpc.Errorf(codes.InvalidArgument, "first stream message must be a request")
+	}
+	body := &httpStreamBody{stream: stream}
+	w := &HTTPResponseWriter{
+		Response: &HTTPResponse{},
+		stream:   stream,
+	}
+	err = f(stream.Context(), r, body, w)
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// ServeStreamUnary implements HTTPServer.ServeHTTPStream with the unary
+// ServeHTTP method of h. The request body is read into memory.
+func ServeStreamUnary(stream HTTP_ServeHTTPStreamServer, h HTTPServer) error {
+	return ServeStream(stream, func(ctx context.Context, r *HTTPRequest, body io.Reader, w *HTTPResponseWriter) error {
+		var err error
+		r.Body, err = ioutil.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		resp, err := h.ServeHTTP(ctx, r)
+		if err != nil {
+			return err
+		}
+		respBody := resp.Body
+		resp.Body = nil
+		w.Response = resp
+		_, err = w.Write(respBody)
+		return err
+	})
+}
+
+type httpStreamBody struct {
+	stream HTTP_ServeHTTPStreamServer
+	buf    []byte
+	err    error
+}
+
+func (b *httpStreamBody) Read(p []byte) (int, error) {
+	for len(b.buf) == 0 {
+		if b.err != nil {
+			return 0, b.err
+		}
+		msg, err := b.stream.Recv()
+		if err != nil {
+			b.err = err
+			continue
+		}
+		b.buf = msg.GetChunk()
+	}
+	n := copy(p, b.buf)
+	b.buf = b.buf[n:]
+	return n, nil
+}
+
+var errStreamClosed = errors.New("api: write to closed response stream")
+
+// HTTPResponseWriter writes a streaming HTTP response.
+type HTTPResponseWriter struct {
+	// Response headers. The Body field is not used.
+	// Changes after the first call to Write are ignored.
+	Response *HTTPResponse
+
+	stream      HTTP_ServeHTTPStreamServer
+	wroteHeader bool
+	closed      bool
+}
+
+func (w *HTTPResponseWriter) writeHeader() error {
+	if w.wroteHeader {
+		return nil
+	}
+	w.wroteHeader = true
+	w.Response.Body = nil
+	return w.stream.Send(&HTTPStreamResponse{
+		Value: &HTTPStreamResponse_Response{Response: w.Response},
+	})
+}
+
+// Write sends p as part of the response body, sending the response headers first
+// if they have not been sent yet.
+func (w *HTTPResponseWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errStreamClosed
+	}
+	if err := w.writeHeader(); err != nil {
+		return 0, err
+	}
+	n := 0
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > StreamChunkSize {
+			chunk = chunk[:StreamChunkSize]
+		}
+		err := w.stream.Send(&HTTPStreamResponse{
+			Value: &HTTPStreamResponse_Chunk{Chunk: chunk},
+		})
+		if err != nil {
+			return n, err
+		}
+		n += len(chunk)
+		p = p[len(chunk):]
+	}
+	return n, nil
+}
+
+// Close sends the response headers if no body was written.
+// It is called by ServeStream after the handler returns.
+func (w *HTTPResponseWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	err := w.writeHeader()
+	w.closed = true
+	return err
+}
@@ -0,0 +1,67 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+
+	"github.com/solidcoredata/scd/api"
+)
+
+// mayAdmin reports if a user with roles may manage other users.
+func (s *ServiceConfig) mayAdmin(roles []int64) bool {
+	s.rlk.RLock()
+	role := s.adminRole
+	s.rlk.RUnlock()
+
+	return role != 0 && hasRole(roles, role)
+}
+
+// admin runs action for the caller if the caller session is granted and
+// its user has the admin role. Each call is recorded in the audit log as
+// event with the target identity.
+func (s *ServiceConfig) admin(ctx context.Context, caller *api.AdminCaller, event, target string, action func() error) error {
+	if caller == nil || len(caller.SessionTokenValue) == 0 {
+		return errSessionNotFound
+	}
+	ra, err := s.am.RequestAuth(ctx, caller.SessionTokenValue, scopeOf(caller.Configuration))
+	if err != nil {
+		return err
+	}
+	switch ra.LoginState {
+	case api.LoginState_Granted:
+	case api.LoginState_None:
+		return errSessionNotFound
+	default:
+		return errNotGranted
+	}
+	// An impersonating session acts with the roles of its own user.
+	user := ra
+	if ra.Secondary != nil {
+		user = ra.Secondary
+	}
+	ev := &auditEvent{
+		Event:      event,
+		Identity:   user.Identity,
+		RemoteAddr: caller.RemoteAddr,
+		Secure:     caller.Secure,
+		Target:     normalizeIdentity(target),
+	}
+	if s.mayAdmin(user.Roles) {
+		err = action()
+	} else {
+		err = errNotAdmin
+	}
+	switch err {
+	case nil:
+		ev.Result = auditGranted
+	case errNotAdmin, errIdentityNotFound:
+		ev.Result = auditFailed
+	default:
+		ev.Result = auditError
+	}
+	s.audit.record(ev, err)
+	return err
+}
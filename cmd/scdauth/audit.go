@@ -0,0 +1,84 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Audit results.
+const (
+	auditGranted   = "granted"
+	auditFailed    = "failed"
+	auditThrottled = "throttled"
+	auditError     = "error"
+)
+
+// auditEvent is a single login attempt, password change, user management
+// call, or a request made while impersonating. Passwords are never recorded.
+type auditEvent struct {
+	Time       time.Time
+	Event      string // "login", "webauthn", "elevate", "change-password", "impersonate", "request", "new-password", or "logout-identity".
+	Identity   string
+	RemoteAddr string
+	Secure     bool
+	Result     string
+	Error      string `json:",omitempty"`
+
+	// Impersonate is the identity the session acts as.
+	Impersonate string `json:",omitempty"`
+
+	// Target is the identity managed by an admin event.
+	Target string `json:",omitempty"`
+
+	// Method and URL of a request made while impersonating.
+	Method string `json:",omitempty"`
+	URL    string `json:",omitempty"`
+}
+
+// auditLog writes login attempts as JSON lines.
+type auditLog struct {
+	lk  sync.Mutex
+	enc *json.Encoder
+}
+
+func newAuditLog(w io.Writer) *auditLog {
+	return &auditLog{enc: json.NewEncoder(w)}
+}
+
+// openAuditLog appends to the file name, or writes to standard error
+// if name is empty.
+func openAuditLog(name string) (*auditLog, error) {
+	if len(name) == 0 {
+		return newAuditLog(os.Stderr), nil
+	}
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open audit log: %v", err)
+	}
+	return newAuditLog(f), nil
+}
+
+// record writes the event. A failed write is reported on standard output
+// and does not stop the login.
+func (al *auditLog) record(ev *auditEvent, err error) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	if err != nil {
+		ev.Error = err.Error()
+	}
+	al.lk.Lock()
+	werr := al.enc.Encode(ev)
+	al.lk.Unlock()
+	if werr != nil {
+		fmt.Printf("audit: %v\n", werr)
+	}
+}
@@ -13,11 +13,64 @@ import (
 	"time"
 
 	"github.com/solidcoredata/scd/api"
+
+	"github.com/golang/protobuf/ptypes"
 )
 
-// BUG(kardianos): normalize identity and passwords.
-// BUG(kardianos): hash and salt passwords.
-// BUG(kardianos): compute or set next login state.
+// Authenticator stores users and sessions for the Auth service.
+type Authenticator interface {
+	// RequestAuth returns the session of token if it was created in scope.
+	RequestAuth(ctx context.Context, token string, scope authScope) (*api.RequestAuthResp, error)
+
+	// Login returns a new session in scope if the user may use it.
+	Login(ctx context.Context, identity, password string, scope authScope) (tokenValue string, err error)
+	Logout(ctx context.Context, sessionToken string) error
+	LogoutIdentity(ctx context.Context, identity string) error
+	NewPassword(ctx context.Context, identity string) error
+	ChangePassword(ctx context.Context, sessionToken, currentPassword, newPassword string) (changed bool, message string, err error)
+
+	// Elevate checks the password of a granted session again and returns
+	// the time until the session may use sensitive resources.
+	Elevate(ctx context.Context, sessionToken, password string) (elevatedUntil time.Time, err error)
+
+	// Impersonate sets the user the session acts as. The user must be
+	// admitted in the scope of the session and allowed by allow, which is
+	// passed the roles of the user in that scope. An empty identity ends
+	// impersonation.
+	Impersonate(ctx context.Context, sessionToken, identity string, allow func(roles []int64) bool) error
+
+	// RunExpire removes expired sessions every interval until ctx is canceled.
+	RunExpire(ctx context.Context, interval time.Duration)
+
+	// SwapChallenge sets the security key challenge of the session, valid
+	// until the given time, and returns the session user with the previous
+	// challenge if it is still valid. A nil challenge clears it, so each
+	// challenge may only be used once.
+	SwapChallenge(ctx context.Context, sessionToken string, challenge []byte, until time.Time) (*DeviceSession, error)
+
+	// AddDevice registers a security key for identity.
+	AddDevice(ctx context.Context, identity string, d *MemoryDevices) error
+
+	// UseDevice updates the signature counter of the security key after a
+	// verified assertion and grants a session waiting for the security key.
+	UseDevice(ctx context.Context, sessionToken string, credentialID []byte, counter int64) error
+}
+
+// DeviceSession is a session with the security keys of its user.
+type DeviceSession struct {
+	User       *MemoryUser
+	LoginState api.LoginState
+	Devices    []*MemoryDevices
+
+	// Challenge is the previous challenge of the session, nil if
+	// none was set or it has expired.
+	Challenge []byte
+}
+
+var (
+	_ Authenticator = &AuthenticateMemory{}
+	_ Authenticator = &AuthenticateSQL{}
+)
 
 // MemoryUser holds information in an easy way to setup.
 //
@@ -29,19 +82,40 @@ type MemoryUser struct {
 	GivenName  string
 	FamilyName string
 
+	// Password hash for PasswordMethod. Test users may be setup with
+	// a PasswordPlain password, which is hashed on the first login.
+	// A user with PasswordNone can not login.
 	Password           string
 	PasswordSalt       []byte
 	PasswordMethod     int64
 	ForceResetPassword bool
 
+	// PasswordChanged is when the user last chose the password,
+	// zero if not known.
+	PasswordChanged time.Time
+
+	// Roles of the user in every environment it may use.
 	Roles []int64
+
+	// Grants limit the user to these System area environments, each with
+	// its own roles. A user without grants may use every environment.
+	Grants []Grant
+
+	// RevokedBefore invalidates all sessions created before this time.
+	RevokedBefore time.Time
 }
 
+// MemoryDevices is a security key registered to a user.
 type MemoryDevices struct {
 	Identity     string
 	Name         string
+	CredentialID []byte
+
+	// Registration is the COSE public key of the credential.
 	Registration []byte
-	Counter      int64
+
+	// Counter is the last signature counter of the device.
+	Counter int64
 }
 
 type MemorySession struct {
@@ -50,9 +124,34 @@ type MemorySession struct {
 	Token      string
 	LoginState api.LoginState
 
+	// UserID is the ID of the session user in the SQL store.
+	UserID int64
+
+	// Scope the session was created in.
+	Scope authScope
+
+	// Impersonate is the identity the session acts as, empty if none.
+	Impersonate string
+
 	ElevatedUntil time.Time
+	TimeCreated   time.Time
 	TimeExpire    time.Time
 	TimeLastHit   time.Time
+
+	// Challenge sent to the security key, valid until ChallengeUntil.
+	Challenge      []byte
+	ChallengeUntil time.Time
+}
+
+// validUntil returns the time the session expires if not used again.
+func (t *MemorySession) validUntil(idle time.Duration) time.Time {
+	until := t.TimeExpire
+	if idle > 0 {
+		if idleUntil := t.TimeLastHit.Add(idle); idleUntil.Before(until) {
+			until = idleUntil
+		}
+	}
+	return until
 }
 
 // AuthenticateMemory provides an in-memory authenticator for request authentication.
@@ -64,45 +163,140 @@ type AuthenticateMemory struct {
 
 	// Tokens maps a token string to a session.
 	Tokens map[string]*MemorySession
+
+	// Devices maps Identity to the registered security keys.
+	Devices map[string][]*MemoryDevices
+
+	// SessionLifetime is the absolute lifetime of a session.
+	SessionLifetime time.Duration
+
+	// SessionIdle ends a session that is not used for this long.
+	// Each request extends the session up to SessionLifetime.
+	SessionIdle time.Duration
+
+	// PasswordMaxAge requires the password to be changed on login once it
+	// is older than this. Zero passwords do not expire.
+	PasswordMaxAge time.Duration
+
+	// ElevateLifetime is how long a session may use sensitive resources
+	// after Elevate.
+	ElevateLifetime time.Duration
+
+	// NotifyNewPassword sends the password chosen by NewPassword to the user.
+	// If nil NewPassword fails.
+	NotifyNewPassword func(ctx context.Context, u *MemoryUser, password string) error
+	// Invalidate is sent the sessions that end or change so cached
+	// request authentications are dropped. May be nil.
+	Invalidate *api.Invalidator
 }
 
 func NewAuthenticateMemory(users ...*MemoryUser) *AuthenticateMemory {
 	am := &AuthenticateMemory{
 		Tokens:    make(map[string]*MemorySession, 50),
 		UserSetup: make(map[string]*MemoryUser, len(users)),
+		Devices:   make(map[string][]*MemoryDevices),
+
+		SessionLifetime: 28 * 24 * time.Hour,
+		SessionIdle:     12 * time.Hour,
+		ElevateLifetime: 10 * time.Minute,
 	}
 	for _, u := range users {
+		u.Identity = normalizeIdentity(u.Identity)
 		am.UserSetup[u.Identity] = u
 	}
 	return am
 }
 
+// loginState returns the state of a new session for u after the password is
+// verified. A user with security keys must use one first, then a forced or
+// expired password must be changed before the session is granted.
+func loginState(u *MemoryUser, hasDevices bool, passwordMaxAge time.Duration, now time.Time) api.LoginState {
+	if hasDevices {
+		return api.LoginState_U2F
+	}
+	return deviceLoginState(u, passwordMaxAge, now)
+}
+
+// deviceLoginState returns the state of a session for u after the
+// security key is used.
+func deviceLoginState(u *MemoryUser, passwordMaxAge time.Duration, now time.Time) api.LoginState {
+	if u.ForceResetPassword {
+		return api.LoginState_ChangePassword
+	}
+	if passwordMaxAge > 0 && !u.PasswordChanged.IsZero() && !now.Before(u.PasswordChanged.Add(passwordMaxAge)) {
+		return api.LoginState_ChangePassword
+	}
+	return api.LoginState_Granted
+}
+
+// sessionValidLocked reports if the session t of user u may still be used at now.
+func (am *AuthenticateMemory) sessionValidLocked(t *MemorySession, u *MemoryUser, now time.Time) bool {
+	if !now.Before(t.validUntil(am.SessionIdle)) {
+		return false
+	}
+	if u != nil && t.TimeCreated.Before(u.RevokedBefore) {
+		return false
+	}
+	return true
+}
+
 // RequestAuth implements the scdhandler.Authenticator.
-func (am *AuthenticateMemory) RequestAuth(ctx context.Context, token string) (*api.RequestAuthResp, error) {
+// Each request slides the idle expiry of the session forward.
+func (am *AuthenticateMemory) RequestAuth(ctx context.Context, token string, scope authScope) (*api.RequestAuthResp, error) {
 	ra := &api.RequestAuthResp{}
-	am.lk.RLock()
-	defer am.lk.RUnlock()
+	am.lk.Lock()
+	defer am.lk.Unlock()
 
+	now := time.Now()
 	t, ok := am.Tokens[token]
 	if !ok {
 		ra.LoginState = api.LoginState_None
 		return ra, nil
 	}
 	u, ok := am.UserSetup[t.Identity]
-	if !ok {
+	if !ok || !am.sessionValidLocked(t, u, now) {
+		delete(am.Tokens, token)
+		ra.LoginState = api.LoginState_None
+		return ra, nil
+	}
+	roles, admitted := u.admit(scope)
+	if t.Scope != scope || !admitted {
+		// The session belongs to another environment.
+		ra.LoginState = api.LoginState_None
 		return ra, nil
 	}
+	t.TimeLastHit = now
+
+	validUntil, err := ptypes.TimestampProto(t.validUntil(am.SessionIdle))
+	if err != nil {
+		return nil, err
+	}
+	ra.ValidUntil = validUntil
+	if t.ElevatedUntil.After(now) {
+		ra.ElevatedUntil, err = ptypes.TimestampProto(t.ElevatedUntil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	ra.ID = u.ID
 	ra.Identity = t.Identity
 	ra.Email = u.Email
 	ra.LoginState = t.LoginState
-	// ra.ElevatedUntil.Seconds = t.ElevatedUntil.Unix()
 	ra.GivenName = u.GivenName
 	ra.FamilyName = u.FamilyName
-	ra.Roles = u.Roles
+	ra.Roles = roles
+	if len(t.Impersonate) > 0 {
+		if iu, ok := am.UserSetup[t.Impersonate]; ok {
+			if iroles, ok := iu.admit(scope); ok {
+				return impersonatedAuth(ra, iu, iroles), nil
+			}
+		}
+	}
 	return ra, nil
 }
 
-func (am *AuthenticateMemory) random(length int) (tokenValue []byte, err error) {
+// randomBytes returns length bytes from the crypto random source.
+func randomBytes(length int) (tokenValue []byte, err error) {
 	tokenValue = make([]byte, length)
 	n, err := crand.Read(tokenValue)
 	if err != nil {
@@ -114,33 +308,240 @@ func (am *AuthenticateMemory) random(length int) (tokenValue []byte, err error)
 	return tokenValue, nil
 }
 
+var (
+	errLoginFailed      = errors.New("auth: failed to login")
+	errIdentityNotFound = errors.New("auth: identity not found")
+	errSessionNotFound  = errors.New("auth: session not found")
+	errNotGranted       = errors.New("auth: session is not granted")
+	errNotImpersonator  = errors.New("auth: session may not impersonate users")
+	errNotAdmin         = errors.New("auth: session may not manage users")
+	errDeviceNotFound   = errors.New("auth: security key not registered")
+	errDeviceExists     = errors.New("auth: security key already registered")
+	errDeviceCounter    = errors.New("auth: security key counter did not increase, it may be cloned")
+
+	errNoNotifyNewPassword = errors.New("auth: no NotifyNewPassword to send the new password with")
+)
+
+// NewPassword chooses a new password for identity and sends it to the user.
+// Existing sessions are logged out and the password must be changed on
+// the next login.
 func (am *AuthenticateMemory) NewPassword(ctx context.Context, identity string) error {
-	return nil
+	if am.NotifyNewPassword == nil {
+		return errNoNotifyNewPassword
+	}
+	identity = normalizeIdentity(identity)
+
+	b, err := randomBytes(12)
+	if err != nil {
+		return err
+	}
+	password := base64.RawURLEncoding.EncodeToString(b)
+
+	am.lk.Lock()
+	u, exists := am.UserSetup[identity]
+	if !exists {
+		am.lk.Unlock()
+		return errIdentityNotFound
+	}
+	err = am.setPasswordLocked(u, password)
+	if err != nil {
+		am.lk.Unlock()
+		return err
+	}
+	u.ForceResetPassword = true
+	am.logoutIdentityLocked(identity, "")
+	am.lk.Unlock()
+	am.Invalidate.Identity(identity)
+
+	return am.NotifyNewPassword(ctx, u, password)
 }
 
-func (am *AuthenticateMemory) ResetPassword(ctx context.Context, sessionToken, oldpassword, newpassword string) error {
-	return nil
+// ChangePassword sets a new password for the user of the session. The current
+// password is not checked if the session is in the ChangePassword state,
+// otherwise a wrong current password returns errLoginFailed. If the new
+// password is not changed the returned message should be shown to the user.
+// Other sessions of the user are logged out.
+func (am *AuthenticateMemory) ChangePassword(ctx context.Context, sessionToken, currentPassword, newPassword string) (changed bool, message string, err error) {
+	am.lk.RLock()
+	t, _, err := am.validSessionLocked(sessionToken, time.Now())
+	var identity string
+	var state api.LoginState
+	if err == nil {
+		identity, state = t.Identity, t.LoginState
+	}
+	am.lk.RUnlock()
+	if err != nil {
+		return false, "", err
+	}
+
+	if state == api.LoginState_U2F {
+		return false, "The security key must be used before changing the password.", nil
+	}
+	if state != api.LoginState_ChangePassword {
+		if _, ok := am.verifyPassword(identity, currentPassword); !ok {
+			return false, "", errLoginFailed
+		}
+	}
+	if msg := checkNewPassword(identity, newPassword); len(msg) > 0 {
+		return false, msg, nil
+	}
+	if _, same := am.verifyPassword(identity, newPassword); same {
+		return false, "The new password must be different from the current password.", nil
+	}
+
+	am.lk.Lock()
+	defer am.lk.Unlock()
+
+	u, exists := am.UserSetup[identity]
+	if !exists {
+		return false, "", errIdentityNotFound
+	}
+	err = am.setPasswordLocked(u, newPassword)
+	if err != nil {
+		return false, "", err
+	}
+	u.ForceResetPassword = false
+	u.PasswordChanged = time.Now()
+	if t.LoginState == api.LoginState_ChangePassword {
+		t.LoginState = api.LoginState_Granted
+	}
+	am.logoutIdentityLocked(identity, sessionToken)
+	am.Invalidate.Identity(identity)
+	return true, "", nil
 }
 
-var errLoginFailed = errors.New("auth: failed to login")
+func (am *AuthenticateMemory) Elevate(ctx context.Context, sessionToken, password string) (elevatedUntil time.Time, err error) {
+	now := time.Now()
+	am.lk.RLock()
+	t, _, err := am.validSessionLocked(sessionToken, now)
+	var identity string
+	var state api.LoginState
+	if err == nil {
+		identity, state = t.Identity, t.LoginState
+	}
+	am.lk.RUnlock()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if state != api.LoginState_Granted {
+		return time.Time{}, errNotGranted
+	}
+	if _, ok := am.verifyPassword(identity, password); !ok {
+		return time.Time{}, errLoginFailed
+	}
 
-func (am *AuthenticateMemory) Login(ctx context.Context, identity, password string) (tokenValue string, err error) {
 	am.lk.Lock()
 	defer am.lk.Unlock()
 
+	// The session may have ended while the password was checked.
+	if am.Tokens[sessionToken] != t {
+		return time.Time{}, errSessionNotFound
+	}
+	t.ElevatedUntil = elevateUntil(t, am.ElevateLifetime, now)
+	am.Invalidate.Token(sessionToken)
+	return t.ElevatedUntil, nil
+}
+
+// elevateUntil returns the end of an elevation starting at now. It does not
+// extend past the end of the session.
+func elevateUntil(t *MemorySession, lifetime time.Duration, now time.Time) time.Time {
+	until := now.Add(lifetime)
+	if t.TimeExpire.Before(until) {
+		until = t.TimeExpire
+	}
+	return until
+}
+
+func (am *AuthenticateMemory) Impersonate(ctx context.Context, sessionToken, identity string, allow func(roles []int64) bool) error {
+	identity = normalizeIdentity(identity)
+	am.lk.Lock()
+	defer am.lk.Unlock()
+
+	t, _, err := am.validSessionLocked(sessionToken, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(identity) > 0 {
+		u, ok := am.UserSetup[identity]
+		if !ok {
+			return errIdentityNotFound
+		}
+		roles, admitted := u.admit(t.Scope)
+		if !admitted {
+			return errIdentityNotFound
+		}
+		if !allow(roles) {
+			return errNotImpersonator
+		}
+	}
+	t.Impersonate = identity
+	am.Invalidate.Token(sessionToken)
+	return nil
+}
+
+// setPasswordLocked hashes and sets the password of u with the
+// DefaultPasswordMethod.
+func (am *AuthenticateMemory) setPasswordLocked(u *MemoryUser, password string) error {
+	hash, salt, err := hashPassword(DefaultPasswordMethod, password, randomBytes)
+	if err != nil {
+		return err
+	}
+	u.Password = hash
+	u.PasswordSalt = salt
+	u.PasswordMethod = DefaultPasswordMethod
+	return nil
+}
+
+// verifyPassword reports if password is the password of identity.
+// The password hash is verified without holding the lock.
+func (am *AuthenticateMemory) verifyPassword(identity, password string) (*MemoryUser, bool) {
+	am.lk.RLock()
 	u, exists := am.UserSetup[identity]
+	var method int64
+	var salt []byte
+	var hash string
+	if exists {
+		method, salt, hash = u.PasswordMethod, u.PasswordSalt, u.Password
+	}
+	am.lk.RUnlock()
+
 	if !exists {
+		// Take the same time as a real verify.
+		verifyPassword(DefaultPasswordMethod, password, dummyPasswordSalt, dummyPasswordHash)
+		return nil, false
+	}
+	if !verifyPassword(method, password, salt, hash) {
+		return nil, false
+	}
+	return u, true
+}
+
+func (am *AuthenticateMemory) Login(ctx context.Context, identity, password string, scope authScope) (tokenValue string, err error) {
+	identity = normalizeIdentity(identity)
+	u, ok := am.verifyPassword(identity, password)
+	if !ok {
 		return "", errLoginFailed
 	}
-	// TODO(kardianos): yeah, this is bad. But we just need the mechanics to function, not work.
-	if u.Password != password {
+	if _, admitted := u.admit(scope); !admitted {
 		return "", errLoginFailed
 	}
 
+	am.lk.Lock()
+	defer am.lk.Unlock()
+
+	if u.PasswordMethod != DefaultPasswordMethod {
+		// Upgrade the stored password to the current method.
+		err = am.setPasswordLocked(u, password)
+		if err != nil {
+			return "", err
+		}
+	}
+	var exists bool
+
 	var t *MemorySession
 
 	for i := 0; i < 5; i++ {
-		tokenBytes, err := am.random(160)
+		tokenBytes, err := randomBytes(160)
 		if err != nil {
 			return "", err
 		}
@@ -150,12 +551,15 @@ func (am *AuthenticateMemory) Login(ctx context.Context, identity, password stri
 		if exists {
 			continue
 		}
+		now := time.Now()
 		t = &MemorySession{
 			Identity:    identity,
 			Token:       tokenValue,
-			LoginState:  api.LoginState_Granted, // TODO(kardianos): compute as required.
-			TimeExpire:  time.Now().Add(28 * 24 * time.Hour),
-			TimeLastHit: time.Now(),
+			Scope:       scope,
+			LoginState:  loginState(u, len(am.Devices[identity]) > 0, am.PasswordMaxAge, now),
+			TimeCreated: now,
+			TimeExpire:  now.Add(am.SessionLifetime),
+			TimeLastHit: now,
 		}
 		am.Tokens[tokenValue] = t
 
@@ -169,21 +573,145 @@ func (am *AuthenticateMemory) Logout(ctx context.Context, sessionToken string) e
 	defer am.lk.Unlock()
 
 	delete(am.Tokens, sessionToken)
+	am.Invalidate.Token(sessionToken)
 	return nil
 }
+
+// LogoutIdentity revokes all sessions of identity, including sessions
+// being created at the same time.
 func (am *AuthenticateMemory) LogoutIdentity(ctx context.Context, identity string) error {
+	identity = normalizeIdentity(identity)
+	am.lk.Lock()
+	defer am.lk.Unlock()
+
+	if u, exists := am.UserSetup[identity]; exists {
+		u.RevokedBefore = time.Now()
+	}
+	am.logoutIdentityLocked(identity, "")
+	am.Invalidate.Identity(identity)
+	return nil
+}
+
+// removeExpired deletes all sessions that may no longer be used.
+func (am *AuthenticateMemory) removeExpired(now time.Time) int {
 	am.lk.Lock()
 	defer am.lk.Unlock()
 
+	n := 0
+	for tokenValue, t := range am.Tokens {
+		if am.sessionValidLocked(t, am.UserSetup[t.Identity], now) {
+			continue
+		}
+		delete(am.Tokens, tokenValue)
+		n++
+	}
+	return n
+}
+
+// RunExpire removes expired sessions every interval until ctx is canceled.
+func (am *AuthenticateMemory) RunExpire(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			am.removeExpired(now)
+		}
+	}
+}
+
+// logoutIdentityLocked removes all sessions of identity except keepToken.
+func (am *AuthenticateMemory) logoutIdentityLocked(identity, keepToken string) {
 	tokens := make([]string, 0, 3)
 	for tokenValue, t := range am.Tokens {
-		if t.Identity == identity {
+		if t.Identity == identity && tokenValue != keepToken {
 			tokens = append(tokens, tokenValue)
 		}
 	}
 	for _, tokenValue := range tokens {
 		delete(am.Tokens, tokenValue)
 	}
+}
+
+// validSessionLocked returns the session for sessionToken if it may still be used.
+func (am *AuthenticateMemory) validSessionLocked(sessionToken string, now time.Time) (*MemorySession, *MemoryUser, error) {
+	t, ok := am.Tokens[sessionToken]
+	if !ok {
+		return nil, nil, errSessionNotFound
+	}
+	u, ok := am.UserSetup[t.Identity]
+	if !ok || !am.sessionValidLocked(t, u, now) {
+		return nil, nil, errSessionNotFound
+	}
+	return t, u, nil
+}
+
+func (am *AuthenticateMemory) SwapChallenge(ctx context.Context, sessionToken string, challenge []byte, until time.Time) (*DeviceSession, error) {
+	am.lk.Lock()
+	defer am.lk.Unlock()
+
+	now := time.Now()
+	t, u, err := am.validSessionLocked(sessionToken, now)
+	if err != nil {
+		return nil, err
+	}
+	ds := &DeviceSession{
+		User:       u,
+		LoginState: t.LoginState,
+		Devices:    am.Devices[t.Identity],
+	}
+	if now.Before(t.ChallengeUntil) {
+		ds.Challenge = t.Challenge
+	}
+	t.Challenge, t.ChallengeUntil = challenge, until
+	return ds, nil
+}
 
+func (am *AuthenticateMemory) AddDevice(ctx context.Context, identity string, d *MemoryDevices) error {
+	identity = normalizeIdentity(identity)
+	am.lk.Lock()
+	defer am.lk.Unlock()
+
+	if _, exists := am.UserSetup[identity]; !exists {
+		return errIdentityNotFound
+	}
+	for _, list := range am.Devices {
+		if findDevice(list, d.CredentialID) != nil {
+			return errDeviceExists
+		}
+	}
+	for _, e := range am.Devices[identity] {
+		if e.Name == d.Name {
+			return errDeviceExists
+		}
+	}
+	d.Identity = identity
+	am.Devices[identity] = append(am.Devices[identity], d)
+	return nil
+}
+
+func (am *AuthenticateMemory) UseDevice(ctx context.Context, sessionToken string, credentialID []byte, counter int64) error {
+	am.lk.Lock()
+	defer am.lk.Unlock()
+
+	now := time.Now()
+	t, u, err := am.validSessionLocked(sessionToken, now)
+	if err != nil {
+		return err
+	}
+	d := findDevice(am.Devices[t.Identity], credentialID)
+	if d == nil {
+		return errDeviceNotFound
+	}
+	if !checkDeviceCounter(d.Counter, counter) {
+		return errDeviceCounter
+	}
+	d.Counter = counter
+	if t.LoginState == api.LoginState_U2F {
+		t.LoginState = deviceLoginState(u, am.PasswordMaxAge, now)
+		am.Invalidate.Token(sessionToken)
+	}
 	return nil
 }
@@ -0,0 +1,1003 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/solidcoredata/scd/api"
+
+	"github.com/golang/protobuf/ptypes"
+)
+
+// AuthenticateSQL stores users, roles, sessions and devices in a database.
+// The queries are written for SQLite; other drivers must accept the
+// same SQL and "?" placeholders.
+type AuthenticateSQL struct {
+	db *sql.DB
+
+	// SessionLifetime is the absolute lifetime of a session.
+	SessionLifetime time.Duration
+
+	// SessionIdle ends a session that is not used for this long.
+	// Each request extends the session up to SessionLifetime.
+	SessionIdle time.Duration
+
+	// PasswordMaxAge requires the password to be changed on login once it
+	// is older than this. Zero passwords do not expire.
+	PasswordMaxAge time.Duration
+
+	// ElevateLifetime is how long a session may use sensitive resources
+	// after Elevate.
+	ElevateLifetime time.Duration
+
+	// NotifyNewPassword sends the password chosen by NewPassword to the user.
+	// If nil NewPassword fails.
+	NotifyNewPassword func(ctx context.Context, u *MemoryUser, password string) error
+	// Invalidate is sent the sessions that end or change so cached
+	// request authentications are dropped. May be nil.
+	Invalidate *api.Invalidator
+}
+
+// OpenAuthenticateSQL opens the database and migrates it to the current schema.
+func OpenAuthenticateSQL(ctx context.Context, driver, dsn string) (*AuthenticateSQL, error) {
+	if driver == "sqlite3" {
+		var err error
+		dsn, err = sqliteDSN(dsn)
+		if err != nil {
+			return nil, err
+		}
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	as := NewAuthenticateSQL(db)
+	err = as.Migrate(ctx)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return as, nil
+}
+
+// sqliteDSN adds the connection options the store relies on to a sqlite3
+// data source name: foreign keys, which sqlite enables per connection, and
+// a busy timeout. Each connection to a private in-memory database is a new
+// empty database, so those are rejected.
+func sqliteDSN(dsn string) (string, error) {
+	name, query := dsn, ""
+	if i := strings.IndexByte(dsn, '?'); i >= 0 {
+		name, query = dsn[:i], dsn[i+1:]
+	}
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return "", fmt.Errorf("auth: sqlite3 dsn %q: %v", dsn, err)
+	}
+	memory := name == ":memory:" || name == "file::memory:" || params.Get("mode") == "memory"
+	if memory && params.Get("cache") != "shared" {
+		return "", errors.New("auth: a private in-memory sqlite3 database is not shared between connections, use a file or cache=shared")
+	}
+	if len(params.Get("_foreign_keys")) == 0 && len(params.Get("_fk")) == 0 {
+		params.Set("_foreign_keys", "1")
+	}
+	if len(params.Get("_busy_timeout")) == 0 && len(params.Get("_timeout")) == 0 {
+		params.Set("_busy_timeout", "5000")
+	}
+	return name + "?" + params.Encode(), nil
+}
+
+// NewAuthenticateSQL uses an open database. Migrate must be called before use.
+func NewAuthenticateSQL(db *sql.DB) *AuthenticateSQL {
+	return &AuthenticateSQL{
+		db: db,
+
+		SessionLifetime: 28 * 24 * time.Hour,
+		SessionIdle:     12 * time.Hour,
+		ElevateLifetime: 10 * time.Minute,
+	}
+}
+
+// Close the database.
+func (as *AuthenticateSQL) Close() error {
+	return as.db.Close()
+}
+
+// sqlMigrations are applied in order, each within a transaction.
+// A released migration must not be changed; append a new one instead.
+//
+// Times are stored as unix nanoseconds, zero for an unset time.
+var sqlMigrations = [][]string{
+	// 1: Initial schema.
+	{
+		`create table scdauth_user (
+	id integer primary key,
+	identity varchar(400) not null unique,
+	email varchar(400) not null default '',
+	given_name varchar(400) not null default '',
+	family_name varchar(400) not null default '',
+	password varchar(400) not null default '',
+	password_salt varchar(100) not null default '',
+	password_method integer not null default 3, -- PasswordArgon2id, the DefaultPasswordMethod.
+	force_reset_password integer not null default 0,
+	revoked_before bigint not null default 0
+)`,
+		`create table scdauth_user_role (
+	user_id integer not null references scdauth_user(id) on delete cascade,
+	role bigint not null,
+	primary key (user_id, role)
+)`,
+		`create table scdauth_session (
+	token varchar(300) primary key,
+	user_id integer not null references scdauth_user(id) on delete cascade,
+	login_state integer not null,
+	elevated_until bigint not null default 0,
+	time_created bigint not null,
+	time_expire bigint not null,
+	time_last_hit bigint not null
+)`,
+		`create index scdauth_session_user on scdauth_session(user_id)`,
+		`create table scdauth_device (
+	id integer primary key,
+	user_id integer not null references scdauth_user(id) on delete cascade,
+	name varchar(400) not null,
+	registration varchar(4000) not null,
+	counter bigint not null default 0,
+	unique (user_id, name)
+)`,
+	},
+	// 2: Security key credentials and challenges.
+	{
+		`alter table scdauth_device add column credential_id varchar(400) not null default ''`,
+		`create unique index scdauth_device_credential on scdauth_device(credential_id)`,
+		`alter table scdauth_session add column challenge varchar(100) not null default ''`,
+		`alter table scdauth_session add column challenge_until bigint not null default 0`,
+	},
+	// 3: Password age.
+	{
+		`alter table scdauth_user add column password_changed bigint not null default 0`,
+	},
+	// 4: Area and environment scopes.
+	{
+		`alter table scdauth_session add column area integer not null default 0`,
+		`alter table scdauth_session add column environment varchar(400) not null default ''`,
+		`create table scdauth_user_grant (
+	user_id integer not null references scdauth_user(id) on delete cascade,
+	environment varchar(400) not null,
+	primary key (user_id, environment)
+)`,
+		`create table scdauth_user_grant_role (
+	user_id integer not null,
+	environment varchar(400) not null,
+	role bigint not null,
+	primary key (user_id, environment, role),
+	foreign key (user_id, environment) references scdauth_user_grant(user_id, environment) on delete cascade
+)`,
+	},
+	// 5: Impersonation.
+	{
+		`alter table scdauth_session add column impersonate_user_id integer null references scdauth_user(id) on delete set null`,
+	},
+}
+
+// Migrate creates or updates the schema to the latest version.
+func (as *AuthenticateSQL) Migrate(ctx context.Context) error {
+	_, err := as.db.ExecContext(ctx, `create table if not exists scdauth_migration (
+	version integer primary key,
+	time_applied bigint not null
+)`)
+	if err != nil {
+		return fmt.Errorf("auth: create migration table: %v", err)
+	}
+	var version int
+	err = as.db.QueryRowContext(ctx, `select coalesce(max(version), 0) from scdauth_migration`).Scan(&version)
+	if err != nil {
+		return fmt.Errorf("auth: read schema version: %v", err)
+	}
+	if version > len(sqlMigrations) {
+		return fmt.Errorf("auth: schema version %d is newer than known version %d", version, len(sqlMigrations))
+	}
+	for i := version; i < len(sqlMigrations); i++ {
+		err = as.inTx(ctx, func(tx *sql.Tx) error {
+			for _, stmt := range sqlMigrations[i] {
+				_, err := tx.ExecContext(ctx, stmt)
+				if err != nil {
+					return err
+				}
+			}
+			_, err := tx.ExecContext(ctx, `insert into scdauth_migration (version, time_applied) values (?, ?)`, i+1, sqlTime(time.Now()))
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("auth: migrate to version %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// inTx runs f in a transaction, committing it if f returns no error.
+func (as *AuthenticateSQL) inTx(ctx context.Context, f func(tx *sql.Tx) error) error {
+	tx, err := as.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	err = f(tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func sqlTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromSQLTime(v int64) time.Time {
+	if v == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, v)
+}
+
+// sqlQueryer is either a *sql.DB or a *sql.Tx.
+type sqlQueryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// loadUser returns the user and roles of the normalized identity.
+func (as *AuthenticateSQL) loadUser(ctx context.Context, q sqlQueryer, identity string) (*MemoryUser, error) {
+	u := &MemoryUser{}
+	var salt string
+	var forceReset, passwordChanged, revokedBefore int64
+	err := q.QueryRowContext(ctx, `
+select id, identity, email, given_name, family_name, password, password_salt, password_method, force_reset_password, password_changed, revoked_before
+from scdauth_user
+where identity = ?`, identity).Scan(
+		&u.ID, &u.Identity, &u.Email, &u.GivenName, &u.FamilyName,
+		&u.Password, &salt, &u.PasswordMethod, &forceReset, &passwordChanged, &revokedBefore,
+	)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, errIdentityNotFound
+	case err != nil:
+		return nil, err
+	}
+	u.PasswordSalt, err = base64.StdEncoding.DecodeString(salt)
+	if err != nil {
+		return nil, fmt.Errorf("auth: invalid password salt for %q: %v", identity, err)
+	}
+	u.ForceResetPassword = forceReset != 0
+	u.PasswordChanged = fromSQLTime(passwordChanged)
+	u.RevokedBefore = fromSQLTime(revokedBefore)
+
+	rows, err := q.QueryContext(ctx, `select role from scdauth_user_role where user_id = ? order by role`, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var role int64
+		err = rows.Scan(&role)
+		if err != nil {
+			return nil, err
+		}
+		u.Roles = append(u.Roles, role)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	rows, err = q.QueryContext(ctx, `
+select g.environment, r.role
+from scdauth_user_grant g
+left join scdauth_user_grant_role r on r.user_id = g.user_id and r.environment = g.environment
+where g.user_id = ?
+order by g.environment, r.role`, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var environment string
+		var role sql.NullInt64
+		err = rows.Scan(&environment, &role)
+		if err != nil {
+			return nil, err
+		}
+		if n := len(u.Grants); n == 0 || u.Grants[n-1].Environment != environment {
+			u.Grants = append(u.Grants, Grant{Environment: environment})
+		}
+		if role.Valid {
+			g := &u.Grants[len(u.Grants)-1]
+			g.Roles = append(g.Roles, role.Int64)
+		}
+	}
+	return u, rows.Err()
+}
+
+// loadSession returns a valid session and the identity of its user.
+// An unknown session returns errSessionNotFound. An expired or revoked
+// session is deleted and a nil session returned without an error, so the
+// delete is not rolled back when q is a transaction.
+func (as *AuthenticateSQL) loadSession(ctx context.Context, q sqlQueryer, token string, now time.Time) (*MemorySession, error) {
+	t := &MemorySession{Token: token}
+	var userID, elevatedUntil, created, expire, lastHit, revokedBefore int64
+	err := q.QueryRowContext(ctx, `
+select s.user_id, u.identity, s.login_state, s.area, s.environment, coalesce(iu.identity, ''), s.elevated_until, s.time_created, s.time_expire, s.time_last_hit, u.revoked_before
+from scdauth_session s
+join scdauth_user u on u.id = s.user_id
+left join scdauth_user iu on iu.id = s.impersonate_user_id
+where s.token = ?`, token).Scan(
+		&userID, &t.Identity, &t.LoginState, &t.Scope.Area, &t.Scope.Environment, &t.Impersonate, &elevatedUntil, &created, &expire, &lastHit, &revokedBefore,
+	)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, errSessionNotFound
+	case err != nil:
+		return nil, err
+	}
+	t.UserID = userID
+	t.ElevatedUntil = fromSQLTime(elevatedUntil)
+	t.TimeCreated = fromSQLTime(created)
+	t.TimeExpire = fromSQLTime(expire)
+	t.TimeLastHit = fromSQLTime(lastHit)
+
+	if !now.Before(t.validUntil(as.SessionIdle)) || t.TimeCreated.Before(fromSQLTime(revokedBefore)) {
+		_, err = q.ExecContext(ctx, `delete from scdauth_session where token = ?`, token)
+		return nil, err
+	}
+	return t, nil
+}
+
+// RequestAuth implements the scdhandler.Authenticator.
+// Each request slides the idle expiry of the session forward.
+func (as *AuthenticateSQL) RequestAuth(ctx context.Context, token string, scope authScope) (*api.RequestAuthResp, error) {
+	ra := &api.RequestAuthResp{}
+	now := time.Now()
+	var t *MemorySession
+	var u, iu *MemoryUser
+	var roles, iroles []int64
+	err := as.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		t, err = as.loadSession(ctx, tx, token, now)
+		if err != nil || t == nil {
+			return err
+		}
+		if t.Scope != scope {
+			// The session belongs to another environment.
+			return errSessionNotFound
+		}
+		u, err = as.loadUser(ctx, tx, t.Identity)
+		if err != nil {
+			return err
+		}
+		var admitted bool
+		roles, admitted = u.admit(scope)
+		if !admitted {
+			return errSessionNotFound
+		}
+		if len(t.Impersonate) > 0 {
+			iu, err = as.loadUser(ctx, tx, t.Impersonate)
+			if err != nil {
+				return err
+			}
+			iroles, admitted = iu.admit(scope)
+			if !admitted {
+				iu = nil
+			}
+		}
+		_, err = tx.ExecContext(ctx, `update scdauth_session set time_last_hit = ? where token = ?`, sqlTime(now), token)
+		if err != nil {
+			return err
+		}
+		t.TimeLastHit = now
+		return nil
+	})
+	if err == nil && t == nil {
+		err = errSessionNotFound
+	}
+	switch err {
+	default:
+		return nil, err
+	case errSessionNotFound:
+		ra.LoginState = api.LoginState_None
+		return ra, nil
+	case nil:
+	}
+
+	ra.ValidUntil, err = ptypes.TimestampProto(t.validUntil(as.SessionIdle))
+	if err != nil {
+		return nil, err
+	}
+	if t.ElevatedUntil.After(now) {
+		ra.ElevatedUntil, err = ptypes.TimestampProto(t.ElevatedUntil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	ra.ID = u.ID
+	ra.Identity = u.Identity
+	ra.Email = u.Email
+	ra.LoginState = t.LoginState
+	ra.GivenName = u.GivenName
+	ra.FamilyName = u.FamilyName
+	ra.Roles = roles
+	if iu != nil {
+		return impersonatedAuth(ra, iu, iroles), nil
+	}
+	return ra, nil
+}
+
+// AddUser inserts a new user with its roles. A plain text password is
+// hashed with the DefaultPasswordMethod. A user without a password can
+// not login until NewPassword is called.
+func (as *AuthenticateSQL) AddUser(ctx context.Context, u *MemoryUser) error {
+	identity := normalizeIdentity(u.Identity)
+	if len(identity) == 0 {
+		return fmt.Errorf("auth: missing identity")
+	}
+	hash, salt, method := u.Password, u.PasswordSalt, u.PasswordMethod
+	if method == PasswordPlain && len(hash) > 0 {
+		var err error
+		hash, salt, err = hashPassword(DefaultPasswordMethod, hash, randomBytes)
+		if err != nil {
+			return err
+		}
+		method = DefaultPasswordMethod
+	}
+	if len(hash) == 0 {
+		hash, method = "", PasswordNone
+	}
+	var forceReset, passwordChanged int64
+	if u.ForceResetPassword {
+		forceReset = 1
+	}
+	if len(hash) > 0 {
+		passwordChanged = sqlTime(time.Now())
+		if !u.PasswordChanged.IsZero() {
+			passwordChanged = sqlTime(u.PasswordChanged)
+		}
+	}
+	return as.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := as.loadUser(ctx, tx, identity)
+		switch err {
+		default:
+			return err
+		case nil:
+			return fmt.Errorf("auth: identity %q already exists", identity)
+		case errIdentityNotFound:
+		}
+		res, err := tx.ExecContext(ctx, `
+insert into scdauth_user (identity, email, given_name, family_name, password, password_salt, password_method, force_reset_password, password_changed)
+values (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			identity, u.Email, u.GivenName, u.FamilyName,
+			hash, base64.StdEncoding.EncodeToString(salt), method, forceReset, passwordChanged,
+		)
+		if err != nil {
+			return err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		for _, role := range u.Roles {
+			_, err = tx.ExecContext(ctx, `insert into scdauth_user_role (user_id, role) values (?, ?)`, id, role)
+			if err != nil {
+				return err
+			}
+		}
+		for _, g := range u.Grants {
+			_, err = tx.ExecContext(ctx, `insert into scdauth_user_grant (user_id, environment) values (?, ?)`, id, g.Environment)
+			if err != nil {
+				return err
+			}
+			for _, role := range g.Roles {
+				_, err = tx.ExecContext(ctx, `insert into scdauth_user_grant_role (user_id, environment, role) values (?, ?, ?)`, id, g.Environment, role)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// setPassword hashes and sets the password of the user with the
+// DefaultPasswordMethod.
+func (as *AuthenticateSQL) setPassword(ctx context.Context, q sqlQueryer, userID int64, password string) error {
+	hash, salt, err := hashPassword(DefaultPasswordMethod, password, randomBytes)
+	if err != nil {
+		return err
+	}
+	_, err = q.ExecContext(ctx, `update scdauth_user set password = ?, password_salt = ?, password_method = ? where id = ?`,
+		hash, base64.StdEncoding.EncodeToString(salt), DefaultPasswordMethod, userID,
+	)
+	return err
+}
+
+// verifyPassword reports if password is the password of identity.
+func (as *AuthenticateSQL) verifyPassword(ctx context.Context, identity, password string) (*MemoryUser, bool, error) {
+	u, err := as.loadUser(ctx, as.db, identity)
+	switch err {
+	default:
+		return nil, false, err
+	case errIdentityNotFound:
+		// Take the same time as a real verify.
+		verifyPassword(DefaultPasswordMethod, password, dummyPasswordSalt, dummyPasswordHash)
+		return nil, false, nil
+	case nil:
+	}
+	if len(u.Password) == 0 || !verifyPassword(u.PasswordMethod, password, u.PasswordSalt, u.Password) {
+		return nil, false, nil
+	}
+	return u, true, nil
+}
+
+func (as *AuthenticateSQL) Login(ctx context.Context, identity, password string, scope authScope) (tokenValue string, err error) {
+	identity = normalizeIdentity(identity)
+	u, ok, err := as.verifyPassword(ctx, identity, password)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", errLoginFailed
+	}
+	if _, admitted := u.admit(scope); !admitted {
+		return "", errLoginFailed
+	}
+
+	if u.PasswordMethod != DefaultPasswordMethod {
+		// Upgrade the stored password to the current method.
+		err = as.setPassword(ctx, as.db, u.ID, password)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	tokenBytes, err := randomBytes(160)
+	if err != nil {
+		return "", err
+	}
+	tokenValue = base64.RawURLEncoding.EncodeToString(tokenBytes)
+
+	// A device added during login must not be skipped by the login state.
+	err = as.inTx(ctx, func(tx *sql.Tx) error {
+		var devices int
+		err := tx.QueryRowContext(ctx, `select count(*) from scdauth_device where user_id = ?`, u.ID).Scan(&devices)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		state := loginState(u, devices > 0, as.PasswordMaxAge, now)
+		_, err = tx.ExecContext(ctx, `
+insert into scdauth_session (token, user_id, login_state, area, environment, time_created, time_expire, time_last_hit)
+values (?, ?, ?, ?, ?, ?, ?, ?)`,
+			tokenValue, u.ID, state, scope.Area, scope.Environment,
+			sqlTime(now), sqlTime(now.Add(as.SessionLifetime)), sqlTime(now),
+		)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return tokenValue, nil
+}
+
+func (as *AuthenticateSQL) Logout(ctx context.Context, sessionToken string) error {
+	_, err := as.db.ExecContext(ctx, `delete from scdauth_session where token = ?`, sessionToken)
+	if err != nil {
+		return err
+	}
+	as.Invalidate.Token(sessionToken)
+	return nil
+}
+
+// LogoutIdentity revokes all sessions of identity, including sessions
+// being created at the same time.
+func (as *AuthenticateSQL) LogoutIdentity(ctx context.Context, identity string) error {
+	identity = normalizeIdentity(identity)
+	err := as.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `update scdauth_user set revoked_before = ? where identity = ?`, sqlTime(time.Now()), identity)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `delete from scdauth_session where user_id in (select id from scdauth_user where identity = ?)`, identity)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	as.Invalidate.Identity(identity)
+	return nil
+}
+
+// NewPassword chooses a new password for identity and sends it to the user.
+// Existing sessions are logged out and the password must be changed on
+// the next login.
+func (as *AuthenticateSQL) NewPassword(ctx context.Context, identity string) error {
+	if as.NotifyNewPassword == nil {
+		return errNoNotifyNewPassword
+	}
+	identity = normalizeIdentity(identity)
+
+	b, err := randomBytes(12)
+	if err != nil {
+		return err
+	}
+	password := base64.RawURLEncoding.EncodeToString(b)
+
+	var u *MemoryUser
+	err = as.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		u, err = as.loadUser(ctx, tx, identity)
+		if err != nil {
+			return err
+		}
+		err = as.setPassword(ctx, tx, u.ID, password)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `update scdauth_user set force_reset_password = 1 where id = ?`, u.ID)
+		if err != nil {
+			return err
+		}
+		u.ForceResetPassword = true
+		_, err = tx.ExecContext(ctx, `delete from scdauth_session where user_id = ?`, u.ID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	as.Invalidate.Identity(identity)
+
+	return as.NotifyNewPassword(ctx, u, password)
+}
+
+// ChangePassword sets a new password for the user of the session. The current
+// password is not checked if the session is in the ChangePassword state,
+// otherwise a wrong current password returns errLoginFailed. If the new
+// password is not changed the returned message should be shown to the user.
+// Other sessions of the user are logged out.
+func (as *AuthenticateSQL) ChangePassword(ctx context.Context, sessionToken, currentPassword, newPassword string) (changed bool, message string, err error) {
+	t, err := as.loadSession(ctx, as.db, sessionToken, time.Now())
+	if err != nil {
+		return false, "", err
+	}
+	if t == nil {
+		return false, "", errSessionNotFound
+	}
+
+	if t.LoginState == api.LoginState_U2F {
+		return false, "The security key must be used before changing the password.", nil
+	}
+	if t.LoginState != api.LoginState_ChangePassword {
+		_, ok, err := as.verifyPassword(ctx, t.Identity, currentPassword)
+		if err != nil {
+			return false, "", err
+		}
+		if !ok {
+			return false, "", errLoginFailed
+		}
+	}
+	if msg := checkNewPassword(t.Identity, newPassword); len(msg) > 0 {
+		return false, msg, nil
+	}
+	_, same, err := as.verifyPassword(ctx, t.Identity, newPassword)
+	if err != nil {
+		return false, "", err
+	}
+	if same {
+		return false, "The new password must be different from the current password.", nil
+	}
+
+	err = as.inTx(ctx, func(tx *sql.Tx) error {
+		userID := t.UserID
+		err := as.setPassword(ctx, tx, userID, newPassword)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `update scdauth_user set force_reset_password = 0, password_changed = ? where id = ?`, sqlTime(time.Now()), userID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `update scdauth_session set login_state = ? where token = ? and login_state = ?`,
+			api.LoginState_Granted, sessionToken, api.LoginState_ChangePassword,
+		)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `delete from scdauth_session where user_id = ? and token <> ?`, userID, sessionToken)
+		return err
+	})
+	if err != nil {
+		return false, "", err
+	}
+	as.Invalidate.Identity(t.Identity)
+	return true, "", nil
+}
+
+func (as *AuthenticateSQL) Elevate(ctx context.Context, sessionToken, password string) (elevatedUntil time.Time, err error) {
+	now := time.Now()
+	t, err := as.loadSession(ctx, as.db, sessionToken, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if t == nil {
+		return time.Time{}, errSessionNotFound
+	}
+	if t.LoginState != api.LoginState_Granted {
+		return time.Time{}, errNotGranted
+	}
+	_, ok, err := as.verifyPassword(ctx, t.Identity, password)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !ok {
+		return time.Time{}, errLoginFailed
+	}
+	until := elevateUntil(t, as.ElevateLifetime, now)
+	res, err := as.db.ExecContext(ctx, `update scdauth_session set elevated_until = ? where token = ? and login_state = ?`,
+		sqlTime(until), sessionToken, api.LoginState_Granted,
+	)
+	if err != nil {
+		return time.Time{}, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if n == 0 {
+		// The session ended while the password was checked.
+		return time.Time{}, errSessionNotFound
+	}
+	as.Invalidate.Token(sessionToken)
+	return until, nil
+}
+
+func (as *AuthenticateSQL) Impersonate(ctx context.Context, sessionToken, identity string, allow func(roles []int64) bool) error {
+	identity = normalizeIdentity(identity)
+	var t *MemorySession
+	err := as.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		t, err = as.loadSession(ctx, tx, sessionToken, time.Now())
+		if err != nil || t == nil {
+			return err
+		}
+		var impersonateID interface{}
+		if len(identity) > 0 {
+			u, err := as.loadUser(ctx, tx, identity)
+			if err != nil {
+				return err
+			}
+			roles, admitted := u.admit(t.Scope)
+			if !admitted {
+				return errIdentityNotFound
+			}
+			if !allow(roles) {
+				return errNotImpersonator
+			}
+			impersonateID = u.ID
+		}
+		_, err = tx.ExecContext(ctx, `update scdauth_session set impersonate_user_id = ? where token = ?`, impersonateID, sessionToken)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return errSessionNotFound
+	}
+	as.Invalidate.Token(sessionToken)
+	return nil
+}
+
+// removeExpired deletes all sessions that may no longer be used.
+func (as *AuthenticateSQL) removeExpired(ctx context.Context, now time.Time) (int64, error) {
+	idleBefore := int64(0)
+	if as.SessionIdle > 0 {
+		idleBefore = sqlTime(now.Add(-as.SessionIdle))
+	}
+	res, err := as.db.ExecContext(ctx, `
+delete from scdauth_session
+where time_expire <= ?
+	or time_last_hit <= ?
+	or time_created < (select u.revoked_before from scdauth_user u where u.id = scdauth_session.user_id)`,
+		sqlTime(now), idleBefore,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// RunExpire removes expired sessions every interval until ctx is canceled.
+func (as *AuthenticateSQL) RunExpire(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			_, err := as.removeExpired(ctx, now)
+			if err != nil {
+				fmt.Printf("unable to remove expired sessions: %v\n", err)
+			}
+		}
+	}
+}
+
+// loadDevices returns the security keys of the user.
+func (as *AuthenticateSQL) loadDevices(ctx context.Context, q sqlQueryer, u *MemoryUser) ([]*MemoryDevices, error) {
+	rows, err := q.QueryContext(ctx, `select name, credential_id, registration, counter from scdauth_device where user_id = ? order by id`, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*MemoryDevices
+	for rows.Next() {
+		d := &MemoryDevices{Identity: u.Identity}
+		var credentialID, registration string
+		err = rows.Scan(&d.Name, &credentialID, &registration, &d.Counter)
+		if err != nil {
+			return nil, err
+		}
+		d.CredentialID, err = base64.RawURLEncoding.DecodeString(credentialID)
+		if err != nil {
+			return nil, fmt.Errorf("auth: invalid credential ID for %q: %v", u.Identity, err)
+		}
+		d.Registration, err = base64.StdEncoding.DecodeString(registration)
+		if err != nil {
+			return nil, fmt.Errorf("auth: invalid device registration for %q: %v", u.Identity, err)
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+func (as *AuthenticateSQL) SwapChallenge(ctx context.Context, sessionToken string, challenge []byte, until time.Time) (*DeviceSession, error) {
+	now := time.Now()
+	ds := &DeviceSession{}
+	var t *MemorySession
+	err := as.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		t, err = as.loadSession(ctx, tx, sessionToken, now)
+		if err != nil || t == nil {
+			return err
+		}
+		ds.LoginState = t.LoginState
+
+		var prev string
+		var prevUntil int64
+		err = tx.QueryRowContext(ctx, `select challenge, challenge_until from scdauth_session where token = ?`, sessionToken).Scan(&prev, &prevUntil)
+		if err != nil {
+			return err
+		}
+		if now.Before(fromSQLTime(prevUntil)) {
+			ds.Challenge, err = base64.RawURLEncoding.DecodeString(prev)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = tx.ExecContext(ctx, `update scdauth_session set challenge = ?, challenge_until = ? where token = ?`,
+			base64.RawURLEncoding.EncodeToString(challenge), sqlTime(until), sessionToken,
+		)
+		if err != nil {
+			return err
+		}
+
+		ds.User, err = as.loadUser(ctx, tx, t.Identity)
+		if err != nil {
+			return err
+		}
+		ds.Devices, err = as.loadDevices(ctx, tx, ds.User)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, errSessionNotFound
+	}
+	return ds, nil
+}
+
+func (as *AuthenticateSQL) AddDevice(ctx context.Context, identity string, d *MemoryDevices) error {
+	identity = normalizeIdentity(identity)
+	credentialID := base64.RawURLEncoding.EncodeToString(d.CredentialID)
+	return as.inTx(ctx, func(tx *sql.Tx) error {
+		u, err := as.loadUser(ctx, tx, identity)
+		if err != nil {
+			return err
+		}
+		var n int
+		err = tx.QueryRowContext(ctx, `select count(*) from scdauth_device where credential_id = ? or (user_id = ? and name = ?)`,
+			credentialID, u.ID, d.Name,
+		).Scan(&n)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return errDeviceExists
+		}
+		_, err = tx.ExecContext(ctx, `insert into scdauth_device (user_id, name, credential_id, registration, counter) values (?, ?, ?, ?, ?)`,
+			u.ID, d.Name, credentialID, base64.StdEncoding.EncodeToString(d.Registration), d.Counter,
+		)
+		if err != nil {
+			return err
+		}
+		d.Identity = identity
+		return nil
+	})
+}
+
+func (as *AuthenticateSQL) UseDevice(ctx context.Context, sessionToken string, credentialID []byte, counter int64) error {
+	now := time.Now()
+	var t *MemorySession
+	err := as.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		t, err = as.loadSession(ctx, tx, sessionToken, now)
+		if err != nil || t == nil {
+			return err
+		}
+		var deviceID, stored int64
+		err = tx.QueryRowContext(ctx, `select id, counter from scdauth_device where user_id = ? and credential_id = ?`,
+			t.UserID, base64.RawURLEncoding.EncodeToString(credentialID),
+		).Scan(&deviceID, &stored)
+		switch {
+		case err == sql.ErrNoRows:
+			return errDeviceNotFound
+		case err != nil:
+			return err
+		}
+		if !checkDeviceCounter(stored, counter) {
+			return errDeviceCounter
+		}
+		// Only update the counter if it was not changed by a concurrent assertion.
+		res, err := tx.ExecContext(ctx, `update scdauth_device set counter = ? where id = ? and counter = ?`, counter, deviceID, stored)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n != 1 {
+			return errDeviceCounter
+		}
+		u, err := as.loadUser(ctx, tx, t.Identity)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `update scdauth_session set login_state = ? where token = ? and login_state = ?`,
+			deviceLoginState(u, as.PasswordMaxAge, now), sessionToken, api.LoginState_U2F,
+		)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return errSessionNotFound
+	}
+	as.Invalidate.Token(sessionToken)
+	return nil
+}
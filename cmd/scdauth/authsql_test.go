@@ -0,0 +1,258 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/solidcoredata/scd/api"
+)
+
+func openTestSQL(t *testing.T) *AuthenticateSQL {
+	db, err := sql.Open("sqlite3", ":memory:?_foreign_keys=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each connection would open a new in-memory database.
+	db.SetMaxOpenConns(1)
+	as := NewAuthenticateSQL(db)
+	err = as.Migrate(context.Background())
+	if err != nil {
+		db.Close()
+		t.Fatal(err)
+	}
+	return as
+}
+
+func (as *AuthenticateSQL) testSessionCount(t *testing.T, token string) int {
+	var n int
+	err := as.db.QueryRow(`select count(*) from scdauth_session where token = ?`, token).Scan(&n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestSQLMigrate(t *testing.T) {
+	ctx := context.Background()
+	as := openTestSQL(t)
+	defer as.Close()
+
+	// Migrating again does not change the schema.
+	err := as.Migrate(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var version, count int
+	err = as.db.QueryRow(`select max(version), count(*) from scdauth_migration`).Scan(&version, &count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != len(sqlMigrations) || count != len(sqlMigrations) {
+		t.Fatalf("schema version %d with %d migrations, want %d", version, count, len(sqlMigrations))
+	}
+
+	_, err = as.db.Exec(`insert into scdauth_user (identity) values ('u1')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var method int64
+	err = as.db.QueryRow(`select password_method from scdauth_user where identity = 'u1'`).Scan(&method)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if method != DefaultPasswordMethod {
+		t.Fatalf("default password method %d, want %d", method, DefaultPasswordMethod)
+	}
+
+	// A newer schema is not used.
+	_, err = as.db.Exec(`insert into scdauth_migration (version, time_applied) values (?, 0)`, len(sqlMigrations)+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = as.Migrate(ctx); err == nil {
+		t.Fatal("migrated a newer schema")
+	}
+}
+
+func TestSQLiteDSN(t *testing.T) {
+	list := []struct {
+		dsn, want string
+		err       bool
+	}{
+		{"auth.db", "auth.db?_busy_timeout=5000&_foreign_keys=1", false},
+		{"file:auth.db?_fk=0&_timeout=100", "file:auth.db?_fk=0&_timeout=100", false},
+		{"file:auth?mode=memory&cache=shared", "file:auth?_busy_timeout=5000&_foreign_keys=1&cache=shared&mode=memory", false},
+		{":memory:", "", true},
+		{"file::memory:?_fk=1", "", true},
+		{"file:auth?mode=memory", "", true},
+	}
+	for _, item := range list {
+		got, err := sqliteDSN(item.dsn)
+		if (err != nil) != item.err {
+			t.Errorf("%q: got error %v, want error %t", item.dsn, err, item.err)
+			continue
+		}
+		if got != item.want {
+			t.Errorf("%q: got %q, want %q", item.dsn, got, item.want)
+		}
+	}
+}
+
+func TestSQLLogin(t *testing.T) {
+	ctx := context.Background()
+	as := openTestSQL(t)
+	defer as.Close()
+
+	err := as.AddUser(ctx, &MemoryUser{
+		Identity:       "U1",
+		GivenName:      "Ada",
+		Password:       "correct horse",
+		PasswordMethod: PasswordPlain,
+		Roles:          []int64{1, 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = as.AddUser(ctx, &MemoryUser{Identity: "u2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := as.loadUser(ctx, as.db, "u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.PasswordMethod != DefaultPasswordMethod || u.Password == "correct horse" {
+		t.Fatalf("plain password stored with method %d", u.PasswordMethod)
+	}
+
+	list := []struct {
+		identity, password string
+		err                error
+	}{
+		{"u1", "wrong horse", errLoginFailed},
+		{"u3", "correct horse", errLoginFailed},
+		{"u2", "", errLoginFailed}, // No password set.
+		{"u1", " correct horse ", errLoginFailed},
+		{" u1 ", "correct horse", nil},
+		{"u1", "correct horse", nil},
+	}
+	var token string
+	for _, item := range list {
+		token, err = as.Login(ctx, item.identity, item.password, authScope{})
+		if err != item.err {
+			t.Fatalf("login %q with %q: got %v, want %v", item.identity, item.password, err, item.err)
+		}
+	}
+
+	ra, err := as.RequestAuth(ctx, token, authScope{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ra.LoginState != api.LoginState_Granted {
+		t.Fatalf("login state %v", ra.LoginState)
+	}
+	if ra.Identity != "u1" || ra.GivenName != "Ada" {
+		t.Fatalf("session user %q %q", ra.Identity, ra.GivenName)
+	}
+	if len(ra.Roles) != 2 || ra.Roles[0] != 1 || ra.Roles[1] != 2 {
+		t.Fatalf("roles %v", ra.Roles)
+	}
+
+	// The session is not used in other environments.
+	ra, err = as.RequestAuth(ctx, token, authScope{Area: api.ConfigureAuth_System, Environment: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ra.LoginState != api.LoginState_None {
+		t.Fatalf("other environment login state %v", ra.LoginState)
+	}
+
+	ra, err = as.RequestAuth(ctx, "unknown", authScope{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ra.LoginState != api.LoginState_None {
+		t.Fatalf("unknown session login state %v", ra.LoginState)
+	}
+}
+
+func TestSQLSessionEnd(t *testing.T) {
+	ctx := context.Background()
+	as := openTestSQL(t)
+	defer as.Close()
+
+	err := as.AddUser(ctx, &MemoryUser{Identity: "u1", Password: "correct horse", PasswordMethod: PasswordPlain})
+	if err != nil {
+		t.Fatal(err)
+	}
+	login := func() string {
+		token, err := as.Login(ctx, "u1", "correct horse", authScope{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	list := []struct {
+		name string
+		end  func(token string) error
+	}{
+		{"expired", func(token string) error {
+			_, err := as.db.Exec(`update scdauth_session set time_expire = ? where token = ?`, sqlTime(time.Now().Add(-time.Second)), token)
+			return err
+		}},
+		{"idle", func(token string) error {
+			_, err := as.db.Exec(`update scdauth_session set time_last_hit = ? where token = ?`, sqlTime(time.Now().Add(-as.SessionIdle)), token)
+			return err
+		}},
+		{"revoked", func(token string) error {
+			_, err := as.db.Exec(`update scdauth_user set revoked_before = ? where identity = 'u1'`, sqlTime(time.Now().Add(time.Second)))
+			return err
+		}},
+	}
+	for _, item := range list {
+		token := login()
+		err = item.end(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ra, err := as.RequestAuth(ctx, token, authScope{})
+		if err != nil {
+			t.Fatalf("%s: %v", item.name, err)
+		}
+		if ra.LoginState != api.LoginState_None {
+			t.Fatalf("%s: login state %v", item.name, ra.LoginState)
+		}
+		if n := as.testSessionCount(t, token); n != 0 {
+			t.Fatalf("%s: session was not deleted", item.name)
+		}
+		_, err = as.Elevate(ctx, token, "correct horse")
+		if err != errSessionNotFound {
+			t.Fatalf("%s: elevate got %v, want %v", item.name, err, errSessionNotFound)
+		}
+		_, err = as.db.Exec(`update scdauth_user set revoked_before = 0`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	token := login()
+	err = as.Logout(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ra, err := as.RequestAuth(ctx, token, authScope{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ra.LoginState != api.LoginState_None {
+		t.Fatalf("login state after logout %v", ra.LoginState)
+	}
+}
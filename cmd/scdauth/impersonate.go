@@ -0,0 +1,133 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+
+	"github.com/solidcoredata/scd/api"
+)
+
+// impersonatedAuth returns the auth of the session ra acting as u with
+// roles. The state of the session is kept and ra becomes the Secondary.
+// The elevation of the session is not used when acting as u.
+func impersonatedAuth(ra *api.RequestAuthResp, u *MemoryUser, roles []int64) *api.RequestAuthResp {
+	return &api.RequestAuthResp{
+		LoginState: ra.LoginState,
+		ID:         u.ID,
+		Identity:   u.Identity,
+		Roles:      roles,
+		ValidUntil: ra.ValidUntil,
+		GivenName:  u.GivenName,
+		FamilyName: u.FamilyName,
+		Email:      u.Email,
+		TokenKey:   ra.TokenKey,
+		Secondary:  ra,
+	}
+}
+
+func hasRole(roles []int64, role int64) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// mayImpersonate reports if a user with roles may act as other users.
+func (s *ServiceConfig) mayImpersonate(roles []int64) bool {
+	s.rlk.RLock()
+	role := s.impersonateRole
+	s.rlk.RUnlock()
+
+	return role != 0 && hasRole(roles, role)
+}
+
+// mayActAs reports if a user with roles may act as a user with target
+// roles. The target may not have a role the user lacks or impersonate
+// other users itself.
+func (s *ServiceConfig) mayActAs(roles, target []int64) bool {
+	s.rlk.RLock()
+	role := s.impersonateRole
+	s.rlk.RUnlock()
+
+	if hasRole(target, role) {
+		return false
+	}
+	for _, r := range target {
+		if !hasRole(roles, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// impersonate sets the identity a granted session acts as and records
+// the change in the audit log. An empty identity, or the identity of the
+// session user, ends impersonation.
+func (s *ServiceConfig) impersonate(ctx context.Context, sessionToken, identity string, scope authScope, remoteAddr string, secure bool) error {
+	ra, err := s.am.RequestAuth(ctx, sessionToken, scope)
+	if err != nil {
+		return err
+	}
+	switch ra.LoginState {
+	case api.LoginState_Granted:
+	case api.LoginState_None:
+		return errSessionNotFound
+	default:
+		return errNotGranted
+	}
+	user := ra
+	if ra.Secondary != nil {
+		user = ra.Secondary
+	}
+	identity = normalizeIdentity(identity)
+	if identity == user.Identity {
+		identity = ""
+	}
+	ev := &auditEvent{
+		Event:       "impersonate",
+		Identity:    user.Identity,
+		RemoteAddr:  remoteAddr,
+		Secure:      secure,
+		Impersonate: identity,
+	}
+	if s.mayImpersonate(user.Roles) {
+		err = s.am.Impersonate(ctx, sessionToken, identity, func(target []int64) bool {
+			return s.mayActAs(user.Roles, target)
+		})
+	} else {
+		err = errNotImpersonator
+	}
+	switch err {
+	case nil:
+		ev.Result = auditGranted
+	case errNotImpersonator, errIdentityNotFound:
+		ev.Result = auditFailed
+	default:
+		ev.Result = auditError
+	}
+	s.audit.record(ev, err)
+	return err
+}
+
+// auditRequest records a request made by a session while it impersonates
+// a user.
+func (s *ServiceConfig) auditRequest(r *api.RequestAuthReq, ra *api.RequestAuthResp) {
+	if ra == nil || ra.Secondary == nil {
+		return
+	}
+	s.audit.record(&auditEvent{
+		Event:       "request",
+		Identity:    ra.Secondary.Identity,
+		RemoteAddr:  r.RemoteAddr,
+		Secure:      r.Secure,
+		Result:      auditGranted,
+		Impersonate: ra.Identity,
+		Method:      r.Method,
+		URL:         r.URL,
+	}, nil)
+}
@@ -7,45 +7,281 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/solidcoredata/scd/api"
 	"github.com/solidcoredata/scd/service"
 
+	"github.com/golang/protobuf/ptypes"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+
+	// Database drivers available to -auth-driver.
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var (
+	authDriver  = flag.String("auth-driver", "", "database/sql driver to store users and sessions in, such as sqlite3; in-memory test users if empty")
+	authDSN     = flag.String("auth-dsn", "", "data source name of the -auth-driver database")
+	authAddUser = flag.String("auth-add-user", "", "add the identity to the -auth-driver database and print its new password")
+
+	passwordMaxAge  = flag.Duration("password-max-age", 0, "require a password change on login once the password is older, zero to never expire")
+	sessionLifetime = flag.Duration("session-lifetime", 28*24*time.Hour, "absolute lifetime of a login session and its cookie")
+	elevateLifetime = flag.Duration("elevate-lifetime", 10*time.Minute, "how long a session may use sensitive resources after the password is entered again")
+	auditLogFile    = flag.String("audit-log", "", "file to append login attempts to as JSON lines, standard error if empty")
+	impersonateRole = flag.String("impersonate-role", "", "catalog role name of users that may act as other users, empty disables impersonation")
+	adminRole       = flag.String("admin-role", "", "catalog role name of users that may set new passwords and log out other users, empty disables both")
 )
 
 func main() {
 	ctx := context.TODO()
 	s := service.New()
-	s.Setup(ctx, NewServiceConfig())
+	sc := NewServiceConfig()
+	s.Setup(ctx, sc)
 }
 
-var _ service.Configration = &ServiceConfig{}
+var (
+	_ service.Configration = &ServiceConfig{}
+	_ service.Initer       = &ServiceConfig{}
+	_ service.Stopper      = &ServiceConfig{}
+)
 
 func NewServiceConfig() *ServiceConfig {
-	s := &ServiceConfig{}
+	s := &ServiceConfig{
+		identityThrottle: newIdentityThrottle(),
+		addrThrottle:     newAddrThrottle(),
+		invalidate:       &api.Invalidator{},
+	}
 
 	s.am = NewAuthenticateMemory(
 		&MemoryUser{
-			ID:       1,
-			Identity: "u1",
-			Password: "p1",
+			ID:             1,
+			Identity:       "u1",
+			Password:       "p1",
+			PasswordMethod: PasswordPlain,
+			Roles:          []int64{1, 2},
 		},
 		&MemoryUser{
-			ID:       2,
-			Identity: "u2",
-			Password: "p2",
+			ID:             2,
+			Identity:       "u2",
+			Password:       "p2",
+			PasswordMethod: PasswordPlain,
+			Roles:          []int64{2},
 		},
 	)
 	return s
 }
 
 type ServiceConfig struct {
-	am *AuthenticateMemory
+	am Authenticator
+
+	// sessionLifetime is the max age of the session cookie.
+	sessionLifetime time.Duration
+
+	identityThrottle *loginThrottle
+	addrThrottle     *loginThrottle
+	audit            *auditLog
+
+	// impersonateRoleName is the catalog role that may act as other users,
+	// empty if none.
+	impersonateRoleName string
+
+	// adminRoleName is the catalog role that may set new passwords and
+	// log out other users, empty if none.
+	adminRoleName string
+
+	// rlk guards the IDs of impersonateRoleName and adminRoleName in the
+	// role catalog, zero if not found.
+	rlk             sync.RWMutex
+	impersonateRole int64
+	adminRole       int64
+
+	// invalidate sends the sessions that end or change to routers
+	// caching request authentications.
+	invalidate *api.Invalidator
+}
+
+// Init selects the authenticator from the command line flags.
+func (s *ServiceConfig) Init(ctx context.Context) error {
+	switch {
+	case len(*authDriver) > 0:
+		as, err := OpenAuthenticateSQL(ctx, *authDriver, *authDSN)
+		if err != nil {
+			return err
+		}
+		if len(*authAddUser) > 0 {
+			err = as.AddUser(ctx, &MemoryUser{Identity: *authAddUser})
+			if err != nil {
+				as.Close()
+				return err
+			}
+			// Only the operator adding the user is shown the password.
+			as.NotifyNewPassword = func(ctx context.Context, u *MemoryUser, password string) error {
+				fmt.Printf("new password for %q: %s\n", u.Identity, password)
+				return nil
+			}
+			err = as.NewPassword(ctx, *authAddUser)
+			as.NotifyNewPassword = nil
+			if err != nil {
+				as.Close()
+				return err
+			}
+		}
+		s.am = as
+	case len(*authAddUser) > 0:
+		return fmt.Errorf("-auth-add-user requires -auth-driver")
+	}
+	if *sessionLifetime <= 0 {
+		return fmt.Errorf("-session-lifetime must be positive")
+	}
+	s.sessionLifetime = *sessionLifetime
+	al, err := openAuditLog(*auditLogFile)
+	if err != nil {
+		return err
+	}
+	s.audit = al
+	s.impersonateRoleName = *impersonateRole
+	s.adminRoleName = *adminRole
+	switch am := s.am.(type) {
+	case *AuthenticateMemory:
+		am.PasswordMaxAge = *passwordMaxAge
+		am.SessionLifetime = *sessionLifetime
+		am.ElevateLifetime = *elevateLifetime
+		am.Invalidate = s.invalidate
+	case *AuthenticateSQL:
+		am.PasswordMaxAge = *passwordMaxAge
+		am.SessionLifetime = *sessionLifetime
+		am.ElevateLifetime = *elevateLifetime
+		am.Invalidate = s.invalidate
+	}
+	go s.am.RunExpire(ctx, time.Minute*5)
+	go s.identityThrottle.run(ctx, time.Minute*5)
+	go s.addrThrottle.run(ctx, time.Minute*5)
+	return nil
+}
+
+// throttle runs attempt unless the identity or client address of the event
+// failed too often, counts a failure if attempt returns errLoginFailed, and
+// records the attempt in the audit log.
+func (s *ServiceConfig) throttle(ev *auditEvent, attempt func() error) error {
+	key := normalizeIdentity(ev.Identity)
+	addr := remoteHost(ev.RemoteAddr)
+	now := time.Now()
+
+	wait := s.identityThrottle.attempt(key, now)
+	if wait == 0 && len(addr) > 0 {
+		wait = s.addrThrottle.attempt(addr, now)
+		if wait > 0 {
+			s.identityThrottle.forgive(key)
+		}
+	}
+	if wait > 0 {
+		err := &throttleError{Wait: wait}
+		ev.Result = auditThrottled
+		s.audit.record(ev, err)
+		return err
+	}
+
+	err := attempt()
+	switch err {
+	case nil:
+		s.identityThrottle.reset(key)
+		if len(addr) > 0 {
+			s.addrThrottle.forgive(addr)
+		}
+		ev.Result = auditGranted
+	case errLoginFailed:
+		ev.Result = auditFailed
+	default:
+		// Do not count errors of the authenticator against the client.
+		s.identityThrottle.forgive(key)
+		if len(addr) > 0 {
+			s.addrThrottle.forgive(addr)
+		}
+		ev.Result = auditError
+	}
+	s.audit.record(ev, err)
+	return err
+}
+
+// login checks the password of identity and returns a new session.
+func (s *ServiceConfig) login(ctx context.Context, identity, password string, scope authScope, remoteAddr string, secure bool) (string, error) {
+	ev := &auditEvent{
+		Event:      "login",
+		Identity:   identity,
+		RemoteAddr: remoteAddr,
+		Secure:     secure,
+	}
+	var token string
+	err := s.throttle(ev, func() error {
+		var err error
+		token, err = s.am.Login(ctx, identity, password, scope)
+		return err
+	})
+	return token, err
+}
+
+// elevate checks the password of a granted session again.
+func (s *ServiceConfig) elevate(ctx context.Context, sessionToken, password string, scope authScope, remoteAddr string, secure bool) (time.Time, error) {
+	ra, err := s.am.RequestAuth(ctx, sessionToken, scope)
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch ra.LoginState {
+	case api.LoginState_Granted:
+	case api.LoginState_None:
+		return time.Time{}, errSessionNotFound
+	default:
+		return time.Time{}, errNotGranted
+	}
+	ev := &auditEvent{
+		Event:      "elevate",
+		Identity:   ra.Identity,
+		RemoteAddr: remoteAddr,
+		Secure:     secure,
+	}
+	var until time.Time
+	err = s.throttle(ev, func() error {
+		var err error
+		until, err = s.am.Elevate(ctx, sessionToken, password)
+		return err
+	})
+	return until, err
+}
+
+// changePassword sets a new password for the user of the session.
+func (s *ServiceConfig) changePassword(ctx context.Context, sessionToken, currentPassword, newPassword string, scope authScope, remoteAddr string, secure bool) (changed bool, message string, err error) {
+	ra, err := s.am.RequestAuth(ctx, sessionToken, scope)
+	if err != nil {
+		return false, "", err
+	}
+	if ra.LoginState == api.LoginState_None {
+		return false, "", errSessionNotFound
+	}
+	user := ra
+	if ra.Secondary != nil {
+		user = ra.Secondary
+	}
+	ev := &auditEvent{
+		Event:      "change-password",
+		Identity:   user.Identity,
+		RemoteAddr: remoteAddr,
+		Secure:     secure,
+	}
+	err = s.throttle(ev, func() error {
+		var err error
+		changed, message, err = s.am.ChangePassword(ctx, sessionToken, currentPassword, newPassword)
+		return err
+	})
+	return changed, message, err
 }
 
 func (s *ServiceConfig) HTTPServer() (api.HTTPServer, bool) {
@@ -54,8 +290,35 @@ func (s *ServiceConfig) HTTPServer() (api.HTTPServer, bool) {
 func (s *ServiceConfig) AuthServer() (api.AuthServer, bool) {
 	return s, true
 }
+
+// catalogRole returns the ID of the role name in the catalog of sb,
+// zero if name is empty or not found.
+func catalogRole(sb *api.ServiceBundle, use, name string) int64 {
+	if len(name) == 0 {
+		return 0
+	}
+	for _, r := range sb.Role {
+		if r.Name == name {
+			return r.ID
+		}
+	}
+	fmt.Printf("%s role %q not in the role catalog, %s disabled\n", use, name, use)
+	return 0
+}
+
+// BundleUpdate finds the impersonate and admin roles in the role catalog.
 func (s *ServiceConfig) BundleUpdate(sb *api.ServiceBundle) {
+	impersonate := catalogRole(sb, "impersonate", s.impersonateRoleName)
+	admin := catalogRole(sb, "admin", s.adminRoleName)
 
+	s.rlk.Lock()
+	s.impersonateRole = impersonate
+	s.adminRole = admin
+	s.rlk.Unlock()
+}
+
+func (s *ServiceConfig) ServeHTTPStream(stream api.HTTP_ServeHTTPStreamServer) error {
+	return api.ServeStreamUnary(stream, s)
 }
 
 func (s *ServiceConfig) ServeHTTP(ctx context.Context, r *api.HTTPRequest) (*api.HTTPResponse, error) {
@@ -69,24 +332,16 @@ func (s *ServiceConfig) ServeHTTP(ctx context.Context, r *api.HTTPRequest) (*api
 		if err != nil {
 			return nil, grpc.Errorf(codes.Internal, "unable to parse form values %v", err)
 		}
+		// The password is normalized by the authenticator.
 		u, p := f.Value.Get("u"), f.Value.Get("p")
 		u = strings.TrimSpace(u)
-		p = strings.TrimSpace(p)
 
-		token, err := s.am.Login(ctx, u, p)
+		token, err := s.login(ctx, u, p, scopeOf(r.AuthConfig), r.RemoteAddr, r.Secure)
 		if err != nil {
-			return nil, grpc.Errorf(codes.PermissionDenied, "bad login: %v", err)
+			return nil, authError(err)
 		}
-		rs := r.Auth
 		resp.Header = api.NewKeyValueList(nil)
-		// TODO(kardianos): set exire time, secure=true, strict origin.
-		c := &http.Cookie{
-			Name:     rs.TokenKey,
-			Value:    token,
-			Path:     "/",
-			HttpOnly: true,
-		}
-		resp.Header.Add("Set-Cookie", c.String())
+		resp.Header.Add("Set-Cookie", r.SessionCookie(token, s.sessionLifetime).String())
 	case "logout":
 		rs := r.Auth
 		c, err := r.Cookie(rs.TokenKey)
@@ -99,17 +354,377 @@ func (s *ServiceConfig) ServeHTTP(ctx context.Context, r *api.HTTPRequest) (*api
 			return nil, fmt.Errorf("unable to logout: %v", err)
 		}
 		resp.Header = api.NewKeyValueList(nil)
-		// TODO(kardianos): set exire time, secure=true, strict origin.
-		c = &http.Cookie{
-			Name:   rs.TokenKey,
-			Path:   "/",
-			MaxAge: -1,
+		resp.Header.Add("Set-Cookie", r.SessionCookie("", -1).String())
+	case "change-password":
+		c, err := r.Cookie(r.Auth.TokenKey)
+		if err != nil {
+			return nil, grpc.Errorf(codes.Unauthenticated, "missing session")
+		}
+		f, err := r.FormValues()
+		if err != nil {
+			return nil, grpc.Errorf(codes.Internal, "unable to parse form values %v", err)
+		}
+		changed, msg, err := s.changePassword(ctx, c.Value, f.Value.Get("c"), f.Value.Get("n"), scopeOf(r.AuthConfig), r.RemoteAddr, r.Secure)
+		if err != nil {
+			return nil, authError(err)
+		}
+		return jsonResponse(&api.ChangePasswordResp{
+			Changed:                   changed,
+			InvalidNewPasswordMessage: msg,
+		})
+	case "elevate":
+		c, err := r.Cookie(r.Auth.TokenKey)
+		if err != nil {
+			return nil, grpc.Errorf(codes.Unauthenticated, "missing session")
+		}
+		f, err := r.FormValues()
+		if err != nil {
+			return nil, grpc.Errorf(codes.Internal, "unable to parse form values %v", err)
+		}
+		until, err := s.elevate(ctx, c.Value, f.Value.Get("p"), scopeOf(r.AuthConfig), r.RemoteAddr, r.Secure)
+		if err != nil {
+			return nil, authError(err)
+		}
+		er := &api.ElevateResp{}
+		er.ElevatedUntil, err = ptypes.TimestampProto(until)
+		if err != nil {
+			return nil, err
+		}
+		return jsonResponse(er)
+	case "impersonate":
+		c, err := r.Cookie(r.Auth.TokenKey)
+		if err != nil {
+			return nil, grpc.Errorf(codes.Unauthenticated, "missing session")
+		}
+		f, err := r.FormValues()
+		if err != nil {
+			return nil, grpc.Errorf(codes.Internal, "unable to parse form values %v", err)
+		}
+		err = s.impersonate(ctx, c.Value, f.Value.Get("identity"), scopeOf(r.AuthConfig), r.RemoteAddr, r.Secure)
+		if err != nil {
+			return nil, authError(err)
 		}
-		resp.Header.Add("Set-Cookie", c.String())
+		return jsonResponse(&api.ImpersonateResp{})
+	case "webauthn/login":
+		return s.serveWebAuthnLogin(ctx, r)
+	case "webauthn/register":
+		return s.serveWebAuthnRegister(ctx, r)
 	}
 	return resp, nil
 }
 
+// webauthnTimeout is how long a security key challenge is valid.
+const webauthnTimeout = 2 * time.Minute
+
+// webauthnCredential is a credential descriptor sent to the browser.
+type webauthnCredential struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+func webauthnCredentials(devices []*MemoryDevices) []webauthnCredential {
+	list := make([]webauthnCredential, len(devices))
+	for i, d := range devices {
+		list[i] = webauthnCredential{Type: "public-key", ID: base64.RawURLEncoding.EncodeToString(d.CredentialID)}
+	}
+	return list
+}
+
+// webauthnResponse is the credential returned by the browser with each
+// binary value encoded as unpadded base64url.
+type webauthnResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+
+	ClientDataJSON    string `json:"clientDataJSON"`
+	AttestationObject string `json:"attestationObject"`
+	AuthenticatorData string `json:"authenticatorData"`
+	Signature         string `json:"signature"`
+}
+
+// decode returns the named binary values in order.
+func (wr *webauthnResponse) decode(v ...string) ([][]byte, error) {
+	list := make([][]byte, len(v))
+	for i, value := range v {
+		b, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil {
+			return nil, grpc.Errorf(codes.InvalidArgument, "invalid security key response: %v", err)
+		}
+		list[i] = b
+	}
+	return list, nil
+}
+
+// webauthnBegin sets a new challenge on the session of the request and
+// returns the session if it is in the required state.
+func (s *ServiceConfig) webauthnBegin(ctx context.Context, r *api.HTTPRequest, state api.LoginState) (*DeviceSession, []byte, error) {
+	c, err := r.Cookie(r.Auth.TokenKey)
+	if err != nil {
+		return nil, nil, grpc.Errorf(codes.Unauthenticated, "missing session")
+	}
+	var challenge []byte
+	var until time.Time
+	if r.Method == http.MethodGet {
+		challenge, err = randomBytes(webauthnChallengeLength)
+		if err != nil {
+			return nil, nil, err
+		}
+		until = time.Now().Add(webauthnTimeout)
+	}
+	ds, err := s.am.SwapChallenge(ctx, c.Value, challenge, until)
+	if err != nil {
+		return nil, nil, authError(err)
+	}
+	if ds.LoginState != state {
+		return nil, nil, grpc.Errorf(codes.FailedPrecondition, "session is %v, not %v", ds.LoginState, state)
+	}
+	return ds, challenge, nil
+}
+
+func jsonResponse(v interface{}) (*api.HTTPResponse, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return &api.HTTPResponse{ContentType: "application/json", Body: b}, nil
+}
+
+// serveWebAuthnLogin returns the assertion options on GET and verifies
+// the assertion on POST, granting the session.
+func (s *ServiceConfig) serveWebAuthnLogin(ctx context.Context, r *api.HTTPRequest) (*api.HTTPResponse, error) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		return nil, grpc.Errorf(codes.InvalidArgument, "method %s not allowed", r.Method)
+	}
+	rp := newWebAuthnRelyingParty(r.Host, r.Secure)
+	ds, challenge, err := s.webauthnBegin(ctx, r, api.LoginState_U2F)
+	if err != nil {
+		return nil, err
+	}
+	if r.Method == http.MethodGet {
+		return jsonResponse(map[string]interface{}{
+			"challenge":        base64.RawURLEncoding.EncodeToString(challenge),
+			"rpId":             rp.ID,
+			"timeout":          webauthnTimeout / time.Millisecond,
+			"allowCredentials": webauthnCredentials(ds.Devices),
+			"userVerification": "discouraged",
+		})
+	}
+
+	wr := &webauthnResponse{}
+	err = json.Unmarshal(r.Body, wr)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "invalid security key response: %v", err)
+	}
+	b, err := wr.decode(wr.ID, wr.ClientDataJSON, wr.AuthenticatorData, wr.Signature)
+	if err != nil {
+		return nil, err
+	}
+	ev := &auditEvent{
+		Event:      "webauthn",
+		Identity:   ds.User.Identity,
+		RemoteAddr: r.RemoteAddr,
+		Secure:     r.Secure,
+		Result:     auditFailed,
+	}
+	d := findDevice(ds.Devices, b[0])
+	if d == nil {
+		s.audit.record(ev, errDeviceNotFound)
+		return nil, authError(errDeviceNotFound)
+	}
+	counter, err := rp.verifyAssertion(ds.Challenge, d.Registration, b[1], b[2], b[3])
+	if err != nil {
+		s.audit.record(ev, err)
+		return nil, grpc.Errorf(codes.PermissionDenied, "%v", err)
+	}
+	c, _ := r.Cookie(r.Auth.TokenKey)
+	err = s.am.UseDevice(ctx, c.Value, d.CredentialID, counter)
+	if err != nil {
+		s.audit.record(ev, err)
+		return nil, authError(err)
+	}
+	ev.Result = auditGranted
+	s.audit.record(ev, nil)
+	return &api.HTTPResponse{}, nil
+}
+
+// serveWebAuthnRegister returns the credential creation options on GET and
+// registers the new security key on POST.
+func (s *ServiceConfig) serveWebAuthnRegister(ctx context.Context, r *api.HTTPRequest) (*api.HTTPResponse, error) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		return nil, grpc.Errorf(codes.InvalidArgument, "method %s not allowed", r.Method)
+	}
+	rp := newWebAuthnRelyingParty(r.Host, r.Secure)
+	ds, challenge, err := s.webauthnBegin(ctx, r, api.LoginState_Granted)
+	if err != nil {
+		return nil, err
+	}
+	u := ds.User
+	if r.Method == http.MethodGet {
+		userID := make([]byte, 8)
+		binary.BigEndian.PutUint64(userID, uint64(u.ID))
+		displayName := strings.TrimSpace(u.GivenName + " " + u.FamilyName)
+		if len(displayName) == 0 {
+			displayName = u.Identity
+		}
+		return jsonResponse(map[string]interface{}{
+			"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+			"rp":        map[string]string{"id": rp.ID, "name": rp.ID},
+			"user": map[string]string{
+				"id":          base64.RawURLEncoding.EncodeToString(userID),
+				"name":        u.Identity,
+				"displayName": displayName,
+			},
+			"pubKeyCredParams": []map[string]interface{}{
+				{"type": "public-key", "alg": coseES256},
+				{"type": "public-key", "alg": coseEdDSA},
+				{"type": "public-key", "alg": coseRS256},
+			},
+			"timeout":                webauthnTimeout / time.Millisecond,
+			"attestation":            "none",
+			"excludeCredentials":     webauthnCredentials(ds.Devices),
+			"authenticatorSelection": map[string]string{"userVerification": "discouraged"},
+		})
+	}
+
+	wr := &webauthnResponse{}
+	err = json.Unmarshal(r.Body, wr)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "invalid security key response: %v", err)
+	}
+	b, err := wr.decode(wr.ClientDataJSON, wr.AttestationObject)
+	if err != nil {
+		return nil, err
+	}
+	credentialID, publicKey, counter, err := rp.verifyRegistration(ds.Challenge, b[0], b[1])
+	if err != nil {
+		return nil, grpc.Errorf(codes.PermissionDenied, "%v", err)
+	}
+	name := strings.TrimSpace(wr.Name)
+	if len(name) == 0 {
+		name = fmt.Sprintf("Security key %d", len(ds.Devices)+1)
+	}
+	err = s.am.AddDevice(ctx, u.Identity, &MemoryDevices{
+		Name:         name,
+		CredentialID: credentialID,
+		Registration: publicKey,
+		Counter:      counter,
+	})
+	if err != nil {
+		return nil, authError(err)
+	}
+	return &api.HTTPResponse{}, nil
+}
+
 func (s *ServiceConfig) RequestAuth(ctx context.Context, r *api.RequestAuthReq) (*api.RequestAuthResp, error) {
-	return s.am.RequestAuth(ctx, r.Token)
+	ra, err := s.am.RequestAuth(ctx, r.Token, scopeOf(r.Configuration))
+	if err != nil {
+		return nil, err
+	}
+	s.auditRequest(r, ra)
+	return ra, nil
+}
+
+// authError converts authenticator errors into gRPC errors.
+func authError(err error) error {
+	if _, ok := err.(*throttleError); ok {
+		return grpc.Errorf(codes.ResourceExhausted, "%v", err)
+	}
+	switch err {
+	case nil:
+		return nil
+	case errLoginFailed:
+		return grpc.Errorf(codes.PermissionDenied, "%v", err)
+	case errIdentityNotFound:
+		return grpc.Errorf(codes.NotFound, "%v", err)
+	case errSessionNotFound:
+		return grpc.Errorf(codes.Unauthenticated, "%v", err)
+	case errNotGranted:
+		return grpc.Errorf(codes.FailedPrecondition, "%v", err)
+	case errNotImpersonator, errNotAdmin:
+		return grpc.Errorf(codes.PermissionDenied, "%v", err)
+	case errNoNotifyNewPassword:
+		return grpc.Errorf(codes.FailedPrecondition, "%v", err)
+	case errDeviceNotFound, errDeviceCounter:
+		return grpc.Errorf(codes.PermissionDenied, "%v", err)
+	case errDeviceExists:
+		return grpc.Errorf(codes.AlreadyExists, "%v", err)
+	}
+	return grpc.Errorf(codes.Internal, "%v", err)
+}
+
+func (s *ServiceConfig) Login(ctx context.Context, r *api.LoginReq) (*api.LoginResp, error) {
+	token, err := s.login(ctx, r.Identity, r.Password, scopeOf(r.Configuration), r.RemoteAddr, r.Secure)
+	if err != nil {
+		return nil, authError(err)
+	}
+	return &api.LoginResp{SessionTokenValue: token}, nil
+}
+
+func (s *ServiceConfig) Logout(ctx context.Context, r *api.LogoutReq) (*api.LogoutResp, error) {
+	var err error
+	switch v := r.Value.(type) {
+	default:
+		return nil, grpc.Errorf(codes.InvalidArgument, "missing session token or identity")
+	case *api.LogoutReq_SessionTokenValue:
+		err = s.am.Logout(ctx, v.SessionTokenValue)
+	case *api.LogoutReq_Identity:
+		err = s.admin(ctx, r.Admin, "logout-identity", v.Identity, func() error {
+			return s.am.LogoutIdentity(ctx, v.Identity)
+		})
+	}
+	if err != nil {
+		return nil, authError(err)
+	}
+	return &api.LogoutResp{}, nil
+}
+
+func (s *ServiceConfig) NewPassword(ctx context.Context, r *api.NewPasswordReq) (*api.NewPasswordResp, error) {
+	err := s.admin(ctx, r.Admin, "new-password", r.Identity, func() error {
+		return s.am.NewPassword(ctx, r.Identity)
+	})
+	if err != nil {
+		return nil, authError(err)
+	}
+	return &api.NewPasswordResp{}, nil
+}
+
+func (s *ServiceConfig) ChangePassword(ctx context.Context, r *api.ChangePasswordReq) (*api.ChangePasswordResp, error) {
+	changed, msg, err := s.changePassword(ctx, r.SessionTokenValue, r.CurrentPassword, r.NewPassword, scopeOf(r.Configuration), r.RemoteAddr, r.Secure)
+	if err != nil {
+		return nil, authError(err)
+	}
+	return &api.ChangePasswordResp{
+		Changed:                   changed,
+		InvalidNewPasswordMessage: msg,
+	}, nil
+}
+
+func (s *ServiceConfig) Elevate(ctx context.Context, r *api.ElevateReq) (*api.ElevateResp, error) {
+	until, err := s.elevate(ctx, r.SessionTokenValue, r.Password, scopeOf(r.Configuration), r.RemoteAddr, r.Secure)
+	if err != nil {
+		return nil, authError(err)
+	}
+	resp := &api.ElevateResp{}
+	resp.ElevatedUntil, err = ptypes.TimestampProto(until)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *ServiceConfig) Impersonate(ctx context.Context, r *api.ImpersonateReq) (*api.ImpersonateResp, error) {
+	err := s.impersonate(ctx, r.SessionTokenValue, r.Identity, scopeOf(r.Configuration), r.RemoteAddr, r.Secure)
+	if err != nil {
+		return nil, authError(err)
+	}
+	return &api.ImpersonateResp{}, nil
+}
+
+func (s *ServiceConfig) Invalidations(r *api.InvalidationsReq, stream api.Auth_InvalidationsServer) error {
+	return s.invalidate.Serve(stream)
+}
+
+// Stop ends the invalidation streams when the service shuts down.
+func (s *ServiceConfig) Stop() {
+	s.invalidate.Stop()
 }
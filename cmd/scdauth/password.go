@@ -0,0 +1,242 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Password methods stored in MemoryUser.PasswordMethod.
+// A method includes the hash parameters, so changing the parameters requires
+// a new method. Existing passwords are rehashed with the current method on
+// the next successful login.
+const (
+	// PasswordNone is a user without a password. It never verifies.
+	PasswordNone int64 = 0
+
+	// PasswordPlain stores the password as is. Only used to setup test users.
+	PasswordPlain int64 = -1
+
+	PasswordBcrypt   int64 = 1
+	PasswordScrypt   int64 = 2
+	PasswordArgon2id int64 = 3
+)
+
+// DefaultPasswordMethod is used to hash new and rehashed passwords.
+const DefaultPasswordMethod = PasswordArgon2id
+
+const passwordSaltLength = 16
+
+// passwordHasher hashes and verifies passwords for a single method.
+type passwordHasher interface {
+	// NeedSalt reports if a random salt must be passed to Hash.
+	NeedSalt() bool
+	Hash(password, salt []byte) (string, error)
+	Verify(password, salt []byte, hash string) bool
+}
+
+var passwordHashers = map[int64]passwordHasher{
+	PasswordPlain:    plainHasher{},
+	PasswordBcrypt:   bcryptHasher{cost: 12},
+	PasswordScrypt:   scryptHasher{n: 1 << 15, r: 8, p: 1, keyLen: 32},
+	PasswordArgon2id: argon2idHasher{time: 1, memory: 64 * 1024, threads: 4, keyLen: 32},
+}
+
+type plainHasher struct{}
+
+func (plainHasher) NeedSalt() bool { return false }
+func (plainHasher) Hash(password, salt []byte) (string, error) {
+	return string(password), nil
+}
+func (plainHasher) Verify(password, salt []byte, hash string) bool {
+	return subtle.ConstantTimeCompare(password, []byte(hash)) == 1
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+// NeedSalt is false as bcrypt includes its own salt in the hash.
+func (bcryptHasher) NeedSalt() bool { return false }
+func (h bcryptHasher) Hash(password, salt []byte) (string, error) {
+	b, err := bcrypt.GenerateFromPassword(password, h.cost)
+	return string(b), err
+}
+func (h bcryptHasher) Verify(password, salt []byte, hash string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), password) == nil
+}
+
+type scryptHasher struct {
+	n, r, p, keyLen int
+}
+
+func (scryptHasher) NeedSalt() bool { return true }
+func (h scryptHasher) Hash(password, salt []byte) (string, error) {
+	b, err := scrypt.Key(password, salt, h.n, h.r, h.p, h.keyLen)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawStdEncoding.EncodeToString(b), nil
+}
+func (h scryptHasher) Verify(password, salt []byte, hash string) bool {
+	v, err := h.Hash(password, salt)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(v), []byte(hash)) == 1
+}
+
+type argon2idHasher struct {
+	time    uint32
+	memory  uint32
+	threads uint8
+	keyLen  uint32
+}
+
+func (argon2idHasher) NeedSalt() bool { return true }
+func (h argon2idHasher) Hash(password, salt []byte) (string, error) {
+	b := argon2.IDKey(password, salt, h.time, h.memory, h.threads, h.keyLen)
+	return base64.RawStdEncoding.EncodeToString(b), nil
+}
+func (h argon2idHasher) Verify(password, salt []byte, hash string) bool {
+	v, _ := h.Hash(password, salt)
+	return subtle.ConstantTimeCompare([]byte(v), []byte(hash)) == 1
+}
+
+// hashPassword hashes the normalized password with method, returning the
+// hash and the salt used, if any.
+func hashPassword(method int64, password string, random func(int) ([]byte, error)) (hash string, salt []byte, err error) {
+	h, found := passwordHashers[method]
+	if !found {
+		return "", nil, fmt.Errorf("auth: unknown password method %d", method)
+	}
+	if h.NeedSalt() {
+		salt, err = random(passwordSaltLength)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	hash, err = h.Hash([]byte(normalizePassword(password)), salt)
+	return hash, salt, err
+}
+
+// verifyPassword reports if the password matches the stored hash.
+// A user without a password is always rejected.
+func verifyPassword(method int64, password string, salt []byte, hash string) bool {
+	h, found := passwordHashers[method]
+	if !found || len(hash) == 0 {
+		return false
+	}
+	return h.Verify([]byte(normalizePassword(password)), salt, hash)
+}
+
+// dummyPasswordSalt and dummyPasswordHash are verified against when an
+// identity does not exist, so the time taken does not reveal it.
+var (
+	dummyPasswordSalt = make([]byte, passwordSaltLength)
+	dummyPasswordHash string
+)
+
+func init() {
+	dummyPasswordHash, _ = passwordHashers[DefaultPasswordMethod].Hash([]byte("dummy"), dummyPasswordSalt)
+}
+
+// normalizePassword returns the NFKC form of the password so the same
+// password typed on different systems compares equal. It is applied to
+// every password hashed or verified. White space is kept; new passwords
+// may not start or end with it.
+func normalizePassword(password string) string {
+	return norm.NFKC.String(password)
+}
+
+// normalizeIdentity returns the NFKC, case folded form of the identity
+// without surrounding white space.
+func normalizeIdentity(identity string) string {
+	// A Caser is stateful and not safe to share between goroutines.
+	return cases.Fold().String(norm.NFKC.String(strings.TrimSpace(identity)))
+}
+
+// Policy for new passwords.
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 1024
+
+	// minPasswordUnique is the minimum number of different characters.
+	minPasswordUnique = 4
+)
+
+// commonPasswords are rejected as new passwords. They are stored in the
+// normalized identity form, so a change of case is also rejected.
+var commonPasswords = map[string]bool{
+	"password":    true,
+	"password1":   true,
+	"password12":  true,
+	"password123": true,
+	"passw0rd":    true,
+	"p@ssw0rd":    true,
+	"12345678":    true,
+	"123456789":   true,
+	"1234567890":  true,
+	"87654321":    true,
+	"qwertyuiop":  true,
+	"qwerty123":   true,
+	"1q2w3e4r":    true,
+	"abc12345":    true,
+	"iloveyou":    true,
+	"sunshine":    true,
+	"princess":    true,
+	"football":    true,
+	"baseball":    true,
+	"superman":    true,
+	"starwars":    true,
+	"whatever":    true,
+	"trustno1":    true,
+	"letmein1":    true,
+	"welcome1":    true,
+	"changeme":    true,
+	"admin123":    true,
+}
+
+// checkNewPassword returns a message for the user if the password of the
+// normalized identity may not be used.
+func checkNewPassword(identity, password string) string {
+	password = normalizePassword(password)
+	if strings.TrimSpace(password) != password {
+		return "The password must not start or end with a space."
+	}
+	n := utf8.RuneCountInString(password)
+	if n < minPasswordLength {
+		return fmt.Sprintf("The password must have at least %d characters.", minPasswordLength)
+	}
+	if n > maxPasswordLength {
+		return fmt.Sprintf("The password must not have more than %d characters.", maxPasswordLength)
+	}
+	unique := make(map[rune]bool, n)
+	for _, r := range password {
+		unique[r] = true
+	}
+	if len(unique) < minPasswordUnique {
+		return fmt.Sprintf("The password must use at least %d different characters.", minPasswordUnique)
+	}
+	folded := normalizeIdentity(password)
+	// Short identities would reject too many passwords if contained.
+	if folded == identity || (utf8.RuneCountInString(identity) >= 3 && strings.Contains(folded, identity)) {
+		return "The password must not contain the identity."
+	}
+	if commonPasswords[folded] {
+		return "The password is too common."
+	}
+	return ""
+}
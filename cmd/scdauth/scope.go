@@ -0,0 +1,60 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"github.com/solidcoredata/scd/api"
+)
+
+// authScope is the area and environment of an application. Users, role
+// grants, and sessions are kept apart for each scope so one authenticator
+// may serve both QA and PROD applications.
+type authScope struct {
+	Area        api.ConfigureAuth_AreaType
+	Environment string
+}
+
+// scopeOf returns the scope of an application configuration.
+func scopeOf(c *api.ConfigureAuth) authScope {
+	if c == nil {
+		return authScope{}
+	}
+	return authScope{
+		Area:        c.Area,
+		Environment: c.Environment,
+	}
+}
+
+// Grant allows a user to use a System area environment with the roles.
+type Grant struct {
+	Environment string
+	Roles       []int64
+}
+
+// admit returns the roles of u in scope, or false if u may not use it.
+//
+// A User area environment is the sandbox of the user with that identity.
+// A user without grants may use every System area environment. An
+// application without an area is not scoped.
+func (u *MemoryUser) admit(scope authScope) ([]int64, bool) {
+	switch scope.Area {
+	case api.ConfigureAuth_User:
+		if normalizeIdentity(scope.Environment) != u.Identity {
+			return nil, false
+		}
+		return u.Roles, true
+	case api.ConfigureAuth_System:
+		if len(u.Grants) == 0 {
+			return u.Roles, true
+		}
+		for _, g := range u.Grants {
+			if g.Environment == scope.Environment {
+				return g.Roles, true
+			}
+		}
+		return nil, false
+	}
+	return u.Roles, true
+}
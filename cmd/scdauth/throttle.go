@@ -0,0 +1,174 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// loginThrottle delays login attempts for a key, such as an identity or
+// client address, after repeated failures.
+//
+// An attempt is recorded as a failure before the password is checked so
+// concurrent attempts cannot all pass the check; a successful attempt is
+// then forgiven or reset.
+type loginThrottle struct {
+	// Free is the number of failures allowed without delay.
+	Free int
+
+	// Delay is the wait after the first failure past Free.
+	// It doubles with each failure up to MaxDelay.
+	Delay    time.Duration
+	MaxDelay time.Duration
+
+	// LockoutAfter failures the key is locked for Lockout.
+	LockoutAfter int
+	Lockout      time.Duration
+
+	// Window is how long failures are remembered after the last one.
+	Window time.Duration
+
+	lk   sync.Mutex
+	fail map[string]*loginFailures
+}
+
+type loginFailures struct {
+	Count int
+	Last  time.Time
+}
+
+// throttleError is returned when a login is attempted too soon.
+type throttleError struct {
+	Wait time.Duration
+}
+
+func (err *throttleError) Error() string {
+	// Round up to whole seconds.
+	wait := (err.Wait + time.Second - 1).Truncate(time.Second)
+	return fmt.Sprintf("auth: too many failed logins, try again in %v", wait)
+}
+
+func newIdentityThrottle() *loginThrottle {
+	return &loginThrottle{
+		Free:         3,
+		Delay:        time.Second,
+		MaxDelay:     time.Minute,
+		LockoutAfter: 10,
+		Lockout:      15 * time.Minute,
+		Window:       24 * time.Hour,
+		fail:         make(map[string]*loginFailures),
+	}
+}
+
+// newAddrThrottle allows more failures as many users may share an address.
+func newAddrThrottle() *loginThrottle {
+	return &loginThrottle{
+		Free:         20,
+		Delay:        time.Second,
+		MaxDelay:     time.Minute,
+		LockoutAfter: 100,
+		Lockout:      15 * time.Minute,
+		Window:       24 * time.Hour,
+		fail:         make(map[string]*loginFailures),
+	}
+}
+
+// delay returns the wait after count failures.
+func (t *loginThrottle) delay(count int) time.Duration {
+	switch {
+	case count <= t.Free:
+		return 0
+	case count >= t.LockoutAfter:
+		return t.Lockout
+	}
+	d := t.Delay
+	for i := t.Free + 1; i < count && d < t.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > t.MaxDelay {
+		d = t.MaxDelay
+	}
+	return d
+}
+
+// attempt records an attempt as a failure. If the key must wait the attempt
+// is not recorded and the remaining wait is returned.
+func (t *loginThrottle) attempt(key string, now time.Time) time.Duration {
+	t.lk.Lock()
+	defer t.lk.Unlock()
+
+	f, found := t.fail[key]
+	if !found || now.Sub(f.Last) > t.Window {
+		f = &loginFailures{}
+		t.fail[key] = f
+	}
+	if wait := f.Last.Add(t.delay(f.Count)).Sub(now); wait > 0 {
+		return wait
+	}
+	f.Count++
+	f.Last = now
+	return 0
+}
+
+// forgive removes a recorded attempt that succeeded.
+func (t *loginThrottle) forgive(key string) {
+	t.lk.Lock()
+	defer t.lk.Unlock()
+
+	f, found := t.fail[key]
+	if !found {
+		return
+	}
+	f.Count--
+	if f.Count <= 0 {
+		delete(t.fail, key)
+	}
+}
+
+// reset removes all failures of the key.
+func (t *loginThrottle) reset(key string) {
+	t.lk.Lock()
+	delete(t.fail, key)
+	t.lk.Unlock()
+}
+
+// prune removes failures older than the window.
+func (t *loginThrottle) prune(now time.Time) {
+	t.lk.Lock()
+	defer t.lk.Unlock()
+
+	for key, f := range t.fail {
+		if now.Sub(f.Last) > t.Window {
+			delete(t.fail, key)
+		}
+	}
+}
+
+// run prunes the throttle every interval until the context is canceled.
+func (t *loginThrottle) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			t.prune(now)
+		}
+	}
+}
+
+// remoteHost returns the host of a "host:port" address.
+func remoteHost(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
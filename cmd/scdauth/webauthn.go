@@ -0,0 +1,337 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+
+	"github.com/fxamacker/cbor/v2"
+	"golang.org/x/crypto/ed25519"
+)
+
+// WebAuthn is used for both FIDO2 and FIDO U2F security keys. Attestation
+// is not requested and the attestation statement is not verified; a device
+// may only be registered from a session that is already granted.
+
+const (
+	webauthnChallengeLength = 32
+
+	// COSE algorithms supported for device public keys.
+	coseES256 = -7
+	coseEdDSA = -8
+	coseRS256 = -257
+
+	authDataUserPresent  = 0x01
+	authDataAttestedData = 0x40
+)
+
+var errWebAuthn = errors.New("auth: security key verification failed")
+
+// webauthnRelyingParty is the application the security key is used with.
+type webauthnRelyingParty struct {
+	// ID is the host name of the application.
+	ID string
+
+	// Host is the expected host and port of the client origin.
+	Host string
+
+	// AllowHTTP permits a client origin without TLS.
+	AllowHTTP bool
+}
+
+// newWebAuthnRelyingParty returns the relying party for a request host.
+func newWebAuthnRelyingParty(host string, secure bool) webauthnRelyingParty {
+	id := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		id = h
+	}
+	return webauthnRelyingParty{
+		ID:        id,
+		Host:      host,
+		AllowHTTP: !secure,
+	}
+}
+
+// webauthnClientData is the JSON collected by the browser and signed
+// by the security key.
+type webauthnClientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+func (rp webauthnRelyingParty) verifyClientData(clientDataJSON []byte, typ string, challenge []byte) error {
+	cd := webauthnClientData{}
+	err := json.Unmarshal(clientDataJSON, &cd)
+	if err != nil {
+		return fmt.Errorf("%v: client data: %v", errWebAuthn, err)
+	}
+	if cd.Type != typ {
+		return fmt.Errorf("%v: client data type %q, expected %q", errWebAuthn, cd.Type, typ)
+	}
+	got, err := base64.RawURLEncoding.DecodeString(cd.Challenge)
+	if err != nil || len(challenge) == 0 || subtle.ConstantTimeCompare(got, challenge) != 1 {
+		return fmt.Errorf("%v: challenge does not match", errWebAuthn)
+	}
+	origin, err := url.Parse(cd.Origin)
+	if err != nil || origin.Host != rp.Host || (origin.Scheme != "https" && !(rp.AllowHTTP && origin.Scheme == "http")) {
+		return fmt.Errorf("%v: unexpected origin %q", errWebAuthn, cd.Origin)
+	}
+	return nil
+}
+
+// webauthnAuthData is the parsed authenticator data.
+type webauthnAuthData struct {
+	RPIDHash []byte
+	Flags    byte
+	Counter  uint32
+
+	// Set when authDataAttestedData is set.
+	CredentialID []byte
+	PublicKey    []byte
+}
+
+func parseWebAuthnAuthData(b []byte) (*webauthnAuthData, error) {
+	if len(b) < 37 {
+		return nil, fmt.Errorf("%v: authenticator data too short", errWebAuthn)
+	}
+	ad := &webauthnAuthData{
+		RPIDHash: b[:32],
+		Flags:    b[32],
+		Counter:  binary.BigEndian.Uint32(b[33:37]),
+	}
+	if ad.Flags&authDataAttestedData == 0 {
+		return ad, nil
+	}
+	b = b[37:]
+	// AAGUID followed by the credential ID length.
+	if len(b) < 18 {
+		return nil, fmt.Errorf("%v: attested credential data too short", errWebAuthn)
+	}
+	n := int(binary.BigEndian.Uint16(b[16:18]))
+	b = b[18:]
+	if len(b) < n {
+		return nil, fmt.Errorf("%v: credential ID too short", errWebAuthn)
+	}
+	ad.CredentialID = b[:n]
+
+	// The public key may be followed by extensions.
+	var key cbor.RawMessage
+	_, err := cbor.UnmarshalFirst(b[n:], &key)
+	if err != nil {
+		return nil, fmt.Errorf("%v: public key: %v", errWebAuthn, err)
+	}
+	ad.PublicKey = []byte(key)
+	return ad, nil
+}
+
+func (rp webauthnRelyingParty) verifyAuthData(ad *webauthnAuthData) error {
+	h := sha256.Sum256([]byte(rp.ID))
+	if subtle.ConstantTimeCompare(ad.RPIDHash, h[:]) != 1 {
+		return fmt.Errorf("%v: relying party does not match", errWebAuthn)
+	}
+	if ad.Flags&authDataUserPresent == 0 {
+		return fmt.Errorf("%v: user not present", errWebAuthn)
+	}
+	return nil
+}
+
+// verifyRegistration checks a new credential created for challenge and
+// returns the credential ID, COSE public key, and signature counter.
+func (rp webauthnRelyingParty) verifyRegistration(challenge, clientDataJSON, attestationObject []byte) (credentialID, publicKey []byte, counter int64, err error) {
+	err = rp.verifyClientData(clientDataJSON, "webauthn.create", challenge)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	att := struct {
+		Format   string          `cbor:"fmt"`
+		AuthData []byte          `cbor:"authData"`
+		Stmt     cbor.RawMessage `cbor:"attStmt"`
+	}{}
+	err = cbor.Unmarshal(attestationObject, &att)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("%v: attestation object: %v", errWebAuthn, err)
+	}
+	ad, err := parseWebAuthnAuthData(att.AuthData)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	err = rp.verifyAuthData(ad)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if len(ad.CredentialID) == 0 {
+		return nil, nil, 0, fmt.Errorf("%v: missing credential", errWebAuthn)
+	}
+	_, err = parseCOSEKey(ad.PublicKey)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return ad.CredentialID, ad.PublicKey, int64(ad.Counter), nil
+}
+
+// verifyAssertion checks the signature of an assertion for challenge made by
+// the device with publicKey and returns the new signature counter.
+func (rp webauthnRelyingParty) verifyAssertion(challenge, publicKey, clientDataJSON, authData, signature []byte) (counter int64, err error) {
+	err = rp.verifyClientData(clientDataJSON, "webauthn.get", challenge)
+	if err != nil {
+		return 0, err
+	}
+	ad, err := parseWebAuthnAuthData(authData)
+	if err != nil {
+		return 0, err
+	}
+	err = rp.verifyAuthData(ad)
+	if err != nil {
+		return 0, err
+	}
+	key, err := parseCOSEKey(publicKey)
+	if err != nil {
+		return 0, err
+	}
+	cdHash := sha256.Sum256(clientDataJSON)
+	signed := make([]byte, 0, len(authData)+len(cdHash))
+	signed = append(append(signed, authData...), cdHash[:]...)
+	if !key.verify(signed, signature) {
+		return 0, fmt.Errorf("%v: invalid signature", errWebAuthn)
+	}
+	return int64(ad.Counter), nil
+}
+
+// coseKey is a public key in the COSE_Key format.
+type coseKey struct {
+	Algorithm int64
+
+	public crypto.PublicKey
+}
+
+// COSE_Key labels. Negative labels depend on the key type.
+const (
+	coseLabelType      = 1
+	coseLabelAlgorithm = 3
+
+	coseLabelCurve    = -1 // EC2, OKP
+	coseLabelX        = -2 // EC2, OKP
+	coseLabelY        = -3 // EC2
+	coseLabelModulus  = -1 // RSA
+	coseLabelExponent = -2 // RSA
+)
+
+func parseCOSEKey(b []byte) (*coseKey, error) {
+	m := map[int64]cbor.RawMessage{}
+	err := cbor.Unmarshal(b, &m)
+	if err != nil {
+		return nil, fmt.Errorf("%v: public key: %v", errWebAuthn, err)
+	}
+	intLabel := func(label int64) int64 {
+		var v int64
+		if cbor.Unmarshal(m[label], &v) != nil {
+			return 0
+		}
+		return v
+	}
+	bytesLabel := func(label int64) []byte {
+		var v []byte
+		if cbor.Unmarshal(m[label], &v) != nil {
+			return nil
+		}
+		return v
+	}
+
+	k := &coseKey{Algorithm: intLabel(coseLabelAlgorithm)}
+	kty := intLabel(coseLabelType)
+	switch k.Algorithm {
+	default:
+		return nil, fmt.Errorf("%v: unsupported key algorithm %d", errWebAuthn, k.Algorithm)
+	case coseES256:
+		x, y := bytesLabel(coseLabelX), bytesLabel(coseLabelY)
+		// EC2 key on P-256.
+		if kty != 2 || intLabel(coseLabelCurve) != 1 || len(x) != 32 || len(y) != 32 {
+			return nil, fmt.Errorf("%v: invalid ES256 key", errWebAuthn)
+		}
+		pub := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, fmt.Errorf("%v: invalid ES256 key", errWebAuthn)
+		}
+		k.public = pub
+	case coseEdDSA:
+		x := bytesLabel(coseLabelX)
+		// OKP key on Ed25519.
+		if kty != 1 || intLabel(coseLabelCurve) != 6 || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%v: invalid EdDSA key", errWebAuthn)
+		}
+		k.public = ed25519.PublicKey(x)
+	case coseRS256:
+		n, e := bytesLabel(coseLabelModulus), bytesLabel(coseLabelExponent)
+		if kty != 3 || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("%v: invalid RS256 key", errWebAuthn)
+		}
+		e4 := make([]byte, 4)
+		copy(e4[4-len(e):], e)
+		k.public = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(binary.BigEndian.Uint32(e4)),
+		}
+	}
+	return k, nil
+}
+
+func (k *coseKey) verify(signed, signature []byte) bool {
+	switch pub := k.public.(type) {
+	case *ecdsa.PublicKey:
+		sig := struct{ R, S *big.Int }{}
+		rest, err := asn1.Unmarshal(signature, &sig)
+		if err != nil || len(rest) > 0 {
+			return false
+		}
+		h := sha256.Sum256(signed)
+		return ecdsa.Verify(pub, h[:], sig.R, sig.S)
+	case ed25519.PublicKey:
+		return ed25519.Verify(pub, signed, signature)
+	case *rsa.PublicKey:
+		h := sha256.Sum256(signed)
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, h[:], signature) == nil
+	}
+	return false
+}
+
+// findDevice returns the device with the credential ID.
+func findDevice(devices []*MemoryDevices, credentialID []byte) *MemoryDevices {
+	for _, d := range devices {
+		if bytes.Equal(d.CredentialID, credentialID) {
+			return d
+		}
+	}
+	return nil
+}
+
+// checkDeviceCounter reports if the signature counter of a device may be
+// updated to counter. A counter that does not increase may be a cloned device.
+// Devices that do not implement a counter always report zero.
+func checkDeviceCounter(stored, counter int64) bool {
+	if stored == 0 && counter == 0 {
+		return true
+	}
+	return counter > stored
+}
@@ -0,0 +1,252 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+const (
+	testRPHost   = "example.com:8443"
+	testRPOrigin = "https://example.com:8443"
+)
+
+// webauthnTestKey is an ES256 security key.
+type webauthnTestKey struct {
+	t    *testing.T
+	priv *ecdsa.PrivateKey
+	cose []byte
+}
+
+func newWebAuthnTestKey(t *testing.T) *webauthnTestKey {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coord := func(v *big.Int) []byte {
+		b := make([]byte, 32)
+		vb := v.Bytes()
+		copy(b[32-len(vb):], vb)
+		return b
+	}
+	cose, err := cbor.Marshal(map[int64]interface{}{
+		coseLabelType:      2,
+		coseLabelAlgorithm: coseES256,
+		coseLabelCurve:     1,
+		coseLabelX:         coord(priv.X),
+		coseLabelY:         coord(priv.Y),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &webauthnTestKey{t: t, priv: priv, cose: cose}
+}
+
+// sign returns the assertion signature over authData and clientDataJSON.
+func (k *webauthnTestKey) sign(authData, clientDataJSON []byte) []byte {
+	cdHash := sha256.Sum256(clientDataJSON)
+	h := sha256.Sum256(append(append([]byte{}, authData...), cdHash[:]...))
+	r, s, err := ecdsa.Sign(rand.Reader, k.priv, h[:])
+	if err != nil {
+		k.t.Fatal(err)
+	}
+	sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	if err != nil {
+		k.t.Fatal(err)
+	}
+	return sig
+}
+
+// testAuthData returns authenticator data. The attested credential data is
+// added if credentialID is set.
+func testAuthData(rpID string, flags byte, counter uint32, credentialID, publicKey []byte) []byte {
+	h := sha256.Sum256([]byte(rpID))
+	b := append([]byte{}, h[:]...)
+	b = append(b, flags)
+	b = append(b, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(b[33:], counter)
+	if len(credentialID) == 0 {
+		return b
+	}
+	b = append(b, make([]byte, 16)...) // AAGUID.
+	b = append(b, byte(len(credentialID)>>8), byte(len(credentialID)))
+	b = append(b, credentialID...)
+	return append(b, publicKey...)
+}
+
+func testClientData(t *testing.T, typ string, challenge []byte, origin string) []byte {
+	b, err := json.Marshal(webauthnClientData{
+		Type:      typ,
+		Challenge: base64.RawURLEncoding.EncodeToString(challenge),
+		Origin:    origin,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestWebAuthnAuthData(t *testing.T) {
+	key := newWebAuthnTestKey(t)
+	credentialID := []byte("credential-1")
+	attested := testAuthData("example.com", authDataUserPresent|authDataAttestedData, 7, credentialID, key.cose)
+	// Extensions follow the public key.
+	extension, _ := cbor.Marshal(map[string]bool{"ext": true})
+
+	list := []struct {
+		name         string
+		data         []byte
+		err          bool
+		counter      uint32
+		credentialID []byte
+		publicKey    []byte
+	}{
+		{name: "short", data: attested[:36], err: true},
+		{name: "assertion", data: testAuthData("example.com", authDataUserPresent, 9, nil, nil), counter: 9},
+		{name: "attested", data: attested, counter: 7, credentialID: credentialID, publicKey: key.cose},
+		{name: "extension", data: append(append([]byte{}, attested...), extension...), counter: 7, credentialID: credentialID, publicKey: key.cose},
+		{name: "short attested", data: attested[:37+10], err: true},
+		{name: "short credential", data: attested[:37+18+4], err: true},
+		{name: "missing key", data: attested[:37+18+len(credentialID)], err: true},
+	}
+	for _, item := range list {
+		ad, err := parseWebAuthnAuthData(item.data)
+		if item.err {
+			if err == nil {
+				t.Errorf("%s: parsed", item.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", item.name, err)
+			continue
+		}
+		if ad.Flags&authDataUserPresent == 0 || ad.Counter != item.counter {
+			t.Errorf("%s: flags %x counter %d", item.name, ad.Flags, ad.Counter)
+		}
+		if !bytes.Equal(ad.CredentialID, item.credentialID) || !bytes.Equal(ad.PublicKey, item.publicKey) {
+			t.Errorf("%s: credential %q public key %x", item.name, ad.CredentialID, ad.PublicKey)
+		}
+	}
+}
+
+func TestWebAuthnRegistration(t *testing.T) {
+	rp := newWebAuthnRelyingParty(testRPHost, true)
+	key := newWebAuthnTestKey(t)
+	challenge := []byte("registration challenge")
+	credentialID := []byte("credential-1")
+
+	attestation := func(authData []byte) []byte {
+		b, err := cbor.Marshal(map[string]interface{}{
+			"fmt":      "none",
+			"authData": authData,
+			"attStmt":  map[string]interface{}{},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	valid := testAuthData(rp.ID, authDataUserPresent|authDataAttestedData, 3, credentialID, key.cose)
+
+	list := []struct {
+		name       string
+		clientData []byte
+		authData   []byte
+		ok         bool
+	}{
+		{"valid", testClientData(t, "webauthn.create", challenge, testRPOrigin), valid, true},
+		{"type", testClientData(t, "webauthn.get", challenge, testRPOrigin), valid, false},
+		{"challenge", testClientData(t, "webauthn.create", []byte("other challenge"), testRPOrigin), valid, false},
+		{"origin", testClientData(t, "webauthn.create", challenge, "https://example.org:8443"), valid, false},
+		{"http origin", testClientData(t, "webauthn.create", challenge, "http://example.com:8443"), valid, false},
+		{"rp id hash", testClientData(t, "webauthn.create", challenge, testRPOrigin), testAuthData("example.org", authDataUserPresent|authDataAttestedData, 3, credentialID, key.cose), false},
+		{"user present", testClientData(t, "webauthn.create", challenge, testRPOrigin), testAuthData(rp.ID, authDataAttestedData, 3, credentialID, key.cose), false},
+		{"no credential", testClientData(t, "webauthn.create", challenge, testRPOrigin), testAuthData(rp.ID, authDataUserPresent, 3, nil, nil), false},
+	}
+	for _, item := range list {
+		gotID, gotKey, counter, err := rp.verifyRegistration(challenge, item.clientData, attestation(item.authData))
+		if !item.ok {
+			if err == nil {
+				t.Errorf("%s: registration accepted", item.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", item.name, err)
+			continue
+		}
+		if !bytes.Equal(gotID, credentialID) || !bytes.Equal(gotKey, key.cose) || counter != 3 {
+			t.Errorf("%s: credential %q counter %d", item.name, gotID, counter)
+		}
+	}
+}
+
+func TestWebAuthnAssertion(t *testing.T) {
+	rp := newWebAuthnRelyingParty(testRPHost, true)
+	key := newWebAuthnTestKey(t)
+	other := newWebAuthnTestKey(t)
+	challenge := []byte("assertion challenge")
+
+	const stored = 5
+	list := []struct {
+		name       string
+		clientData []byte
+		authData   []byte
+		signer     *webauthnTestKey
+		ok         bool
+	}{
+		{"valid", testClientData(t, "webauthn.get", challenge, testRPOrigin), testAuthData(rp.ID, authDataUserPresent, 6, nil, nil), key, true},
+		{"type", testClientData(t, "webauthn.create", challenge, testRPOrigin), testAuthData(rp.ID, authDataUserPresent, 6, nil, nil), key, false},
+		{"challenge", testClientData(t, "webauthn.get", []byte("other challenge"), testRPOrigin), testAuthData(rp.ID, authDataUserPresent, 6, nil, nil), key, false},
+		{"origin", testClientData(t, "webauthn.get", challenge, "https://example.com:9443"), testAuthData(rp.ID, authDataUserPresent, 6, nil, nil), key, false},
+		{"rp id hash", testClientData(t, "webauthn.get", challenge, testRPOrigin), testAuthData("example.org", authDataUserPresent, 6, nil, nil), key, false},
+		{"user present", testClientData(t, "webauthn.get", challenge, testRPOrigin), testAuthData(rp.ID, 0, 6, nil, nil), key, false},
+		{"signature", testClientData(t, "webauthn.get", challenge, testRPOrigin), testAuthData(rp.ID, authDataUserPresent, 6, nil, nil), other, false},
+		{"same counter", testClientData(t, "webauthn.get", challenge, testRPOrigin), testAuthData(rp.ID, authDataUserPresent, stored, nil, nil), key, false},
+		{"lower counter", testClientData(t, "webauthn.get", challenge, testRPOrigin), testAuthData(rp.ID, authDataUserPresent, stored-1, nil, nil), key, false},
+	}
+	for _, item := range list {
+		sig := item.signer.sign(item.authData, item.clientData)
+		counter, err := rp.verifyAssertion(challenge, key.cose, item.clientData, item.authData, sig)
+		if err == nil && !checkDeviceCounter(stored, counter) {
+			err = errDeviceCounter
+		}
+		if item.ok != (err == nil) {
+			t.Errorf("%s: got error %v, want ok %t", item.name, err, item.ok)
+		}
+	}
+}
+
+func TestCheckDeviceCounter(t *testing.T) {
+	list := []struct {
+		stored, counter int64
+		ok              bool
+	}{
+		{0, 0, true}, // No counter implemented.
+		{0, 1, true},
+		{1, 2, true},
+		{2, 2, false},
+		{2, 1, false},
+		{2, 0, false},
+	}
+	for _, item := range list {
+		if got := checkDeviceCounter(item.stored, item.counter); got != item.ok {
+			t.Errorf("stored %d counter %d: got %t, want %t", item.stored, item.counter, got, item.ok)
+		}
+	}
+}
@@ -4,8 +4,271 @@
 
 // scdctl communicates with the scdrouter to notify the router of new
 // services and update the application setup.
+//
+//	scdctl [-router localhost:9301] [-json] <command> [arguments]
+//
+// Commands:
+//
+//	notify <service-address>   notify the router of a service
+//	update [flags]             list, insert, alter, or delete host bindings
+//	status                     show the current router run
+//	resources                  show the resolved resource graph
+//	rotate-token-key           replace the key session cookie names are derived from
 package main
 
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/solidcoredata/scd/api"
+
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc"
+)
+
+const (
+	printMessage  = 1
+	printDefaults = 2
+)
+
+func onErr(t byte, msg string) {
+	if len(msg) > 0 {
+		fmt.Fprint(os.Stderr, msg)
+		fmt.Fprintln(os.Stderr)
+	}
+	switch t {
+	case printMessage:
+		os.Exit(1)
+	case printDefaults:
+		usage()
+		os.Exit(2)
+	}
+}
+func onErrf(t byte, f string, v ...interface{}) {
+	onErr(t, fmt.Sprintf(f, v...))
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %s [flags] <notify|update|status|resources|rotate-token-key> [arguments]\n", os.Args[0])
+	flag.PrintDefaults()
+}
+
+// command is run with the arguments after the command name.
+type command func(ctx context.Context, client api.RouterConfigurationClient, out *output, args []string) error
+
+var commands = map[string]command{
+	"notify":           runNotify,
+	"update":           runUpdate,
+	"status":           runStatus,
+	"resources":        runResources,
+	"rotate-token-key": runRotateTokenKey,
+}
+
 func main() {
+	var routerAddress string
+	var asJSON bool
+	var timeout time.Duration
+	flag.StringVar(&routerAddress, "router", "localhost:9301", "router RPC address")
+	flag.BoolVar(&asJSON, "json", false, "output JSON instead of a table")
+	flag.DurationVar(&timeout, "timeout", time.Second*10, "time to wait for the router")
+	flag.Usage = usage
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		onErr(printDefaults, "missing command")
+	}
+	cmd, found := commands[args[0]]
+	if !found {
+		onErrf(printDefaults, "unknown command %q", args[0])
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, routerAddress, grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		onErrf(printMessage, "unable to connect to router %q: %v", routerAddress, err)
+	}
+	defer conn.Close()
+
+	out := &output{w: os.Stdout, json: asJSON}
+	err = cmd(ctx, api.NewRouterConfigurationClient(conn), out, args[1:])
+	if err != nil {
+		conn.Close()
+		onErrf(printMessage, "%s: %v", args[0], err)
+	}
+}
+
+// output writes a result either as JSON or as a table.
+type output struct {
+	w    io.Writer
+	json bool
+}
+
+// value writes v as JSON if requested, otherwise table is called to write it.
+func (o *output) value(v interface{}, table func(tw *tabwriter.Writer)) error {
+	if o.json {
+		e := json.NewEncoder(o.w)
+		e.SetIndent("", "\t")
+		return e.Encode(v)
+	}
+	tw := tabwriter.NewWriter(o.w, 0, 8, 2, ' ', 0)
+	table(tw)
+	return tw.Flush()
+}
+
+func runNotify(ctx context.Context, client api.RouterConfigurationClient, out *output, args []string) error {
+	fs := flag.NewFlagSet("notify", flag.ExitOnError)
+	remove := fs.Bool("remove", false, "remove the service from the router")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected a single service address")
+	}
+	_, err := client.Notify(ctx, &api.NotifyReq{
+		ServiceAddress: fs.Arg(0),
+		Remove:         *remove,
+	})
+	return err
+}
+
+func runRotateTokenKey(ctx context.Context, client api.RouterConfigurationClient, out *output, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("unexpected arguments %q", args)
+	}
+	resp, err := client.RotateTokenKey(ctx, &api.RotateTokenKeyReq{})
+	if err != nil {
+		return err
+	}
+	return out.value(resp, func(tw *tabwriter.Writer) {
+		if resp.PreviousUntil == nil {
+			fmt.Fprintln(tw, "previous token key no longer accepted")
+			return
+		}
+		until, _ := ptypes.Timestamp(resp.PreviousUntil)
+		fmt.Fprintf(tw, "previous token key accepted until %v\n", until.Local().Format(time.RFC3339))
+	})
+}
+
+// stringList is a flag that may be repeated.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func runUpdate(ctx context.Context, client api.RouterConfigurationClient, out *output, args []string) error {
+	fs := flag.NewFlagSet("update", flag.ExitOnError)
+	action := fs.String("action", "list", "list, insert, alter, or delete")
+	bind := fs.String("bind", "", "bind to update")
+	var hosts, bundles stringList
+	fs.Var(&hosts, "host", "host name, may be repeated")
+	fs.Var(&bundles, "bundle", "bundle name, may be repeated")
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+
+	req := &api.UpdateReq{
+		Bind:   *bind,
+		Host:   hosts,
+		Bundle: bundles,
+	}
+	switch strings.ToLower(*action) {
+	default:
+		return fmt.Errorf("unknown action %q, need one of list, insert, alter, delete", *action)
+	case "list":
+		req.Action = api.UpdateAction_UpdateNOOP
+	case "insert":
+		req.Action = api.UpdateAction_UpdateInsert
+	case "alter":
+		req.Action = api.UpdateAction_UpdateAlter
+	case "delete":
+		req.Action = api.UpdateAction_UpdateDelete
+	}
+	resp, err := client.Update(ctx, req)
+	if err != nil {
+		return err
+	}
+	return out.value(resp, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "BIND\tHOST\tBUNDLE")
+		for _, b := range resp.Binding {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", b.Bind, strings.Join(b.Host, ","), strings.Join(b.Bundle, ","))
+		}
+	})
+}
+
+func runStatus(ctx context.Context, client api.RouterConfigurationClient, out *output, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("unexpected arguments %q", args)
+	}
+	resp, err := client.Status(ctx, &api.StatusReq{})
+	if err != nil {
+		return err
+	}
+	return out.value(resp, func(tw *tabwriter.Writer) {
+		if len(resp.Version) == 0 {
+			fmt.Fprintln(tw, "not configured")
+			return
+		}
+		fmt.Fprintf(tw, "version\t%s\n\n", resp.Version)
+
+		fmt.Fprintln(tw, "SERVICE\tHOST\tAUTH\tLOGIN STATE\tPREFIX\tBUNDLE")
+		for _, a := range resp.App {
+			host := strings.Join(a.Host, ",")
+			if len(a.LoginBundle) == 0 {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t\t\t\n", a.Service, host, a.Auth)
+				continue
+			}
+			for _, lb := range a.LoginBundle {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", a.Service, host, a.Auth, lb.LoginState, lb.Prefix, lb.Bundle)
+			}
+		}
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "CONNECTED\tADDRESS\tSTATE")
+		for _, cs := range resp.Service {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", cs.Name, cs.Address, cs.State)
+		}
+		if len(resp.Error) == 0 {
+			return
+		}
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "ERROR\tSERVICE\tMESSAGE")
+		for _, e := range resp.Error {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", e.Kind, strings.Join(e.Service, ","), e.Message)
+		}
+	})
+}
+
+func runResources(ctx context.Context, client api.RouterConfigurationClient, out *output, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("unexpected arguments %q", args)
+	}
+	resp, err := client.Resources(ctx, &api.ResourcesReq{})
+	if err != nil {
+		return err
+	}
+	return out.value(resp, func(tw *tabwriter.Writer) {
+		if len(resp.Version) == 0 {
+			fmt.Fprintln(tw, "not configured")
+			return
+		}
+		fmt.Fprintf(tw, "version\t%s\n\n", resp.Version)
 
+		fmt.Fprintln(tw, "NAME\tTYPE\tCONSUME\tPARENT\tINCLUDE\tROLE\tELEVATE")
+		for _, r := range resp.Resource {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%t\n", r.Name, r.Type, r.Consume, r.Parent, strings.Join(r.Include, ","), strings.Join(r.Role, ","), r.Elevate)
+		}
+	})
 }
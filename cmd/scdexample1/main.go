@@ -54,6 +54,10 @@ func (s *ServiceConfig) BundleUpdate(sb *api.ServiceBundle) {
 	s.mu.Unlock()
 }
 
+func (s *ServiceConfig) ServeHTTPStream(stream api.HTTP_ServeHTTPStreamServer) error {
+	return api.ServeStreamUnary(stream, s)
+}
+
 func (s *ServiceConfig) ServeHTTP(ctx context.Context, r *api.HTTPRequest) (*api.HTTPResponse, error) {
 	resp := &api.HTTPResponse{}
 	switch r.URL.Path {
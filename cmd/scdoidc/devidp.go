@@ -0,0 +1,314 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// devCodeLifetime is how long an authorization code of the stand-in
+// identity provider may be redeemed.
+const devCodeLifetime = time.Minute
+
+// devIdP is a stand-in OpenID Connect identity provider for development.
+// It signs in any identity entered in its form without a password and
+// accepts any client.
+type devIdP struct {
+	issuer string
+	key    *rsa.PrivateKey
+
+	lk    sync.Mutex
+	codes map[string]*devCode
+}
+
+// devCode is an issued authorization code.
+type devCode struct {
+	ClientID    string
+	RedirectURI string
+	Challenge   string
+	Nonce       string
+	Claims      map[string]interface{}
+	Expire      time.Time
+}
+
+// startDevIdP serves a stand-in identity provider on addr until ctx is
+// canceled.
+func startDevIdP(ctx context.Context, addr string) (*devIdP, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("dev-idp: %v", err)
+	}
+	idp := &devIdP{
+		issuer: "http://" + l.Addr().String(),
+		key:    key,
+		codes:  make(map[string]*devCode),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.serveDiscovery)
+	mux.HandleFunc("/authorize", idp.serveAuthorize)
+	mux.HandleFunc("/token", idp.serveToken)
+	mux.HandleFunc("/jwks", idp.serveJWKS)
+	srv := &http.Server{Handler: mux}
+	go func() {
+		err := srv.Serve(l)
+		if err != nil && err != http.ErrServerClosed {
+			fmt.Printf("dev-idp: %v\n", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	return idp, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (idp *devIdP) serveDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, &discoveryDoc{
+		Issuer:                idp.issuer,
+		AuthorizationEndpoint: idp.issuer + "/authorize",
+		TokenEndpoint:         idp.issuer + "/token",
+		JWKSURI:               idp.issuer + "/jwks",
+		CodeChallengeMethods:  []string{"S256"},
+	})
+}
+
+func (idp *devIdP) serveJWKS(w http.ResponseWriter, r *http.Request) {
+	pub := idp.key.PublicKey
+	writeJSON(w, http.StatusOK, &jwkSet{Keys: []jwk{{
+		Kty: "RSA",
+		Kid: "dev",
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}}})
+}
+
+var devLoginTemplate = template.Must(template.New("").Parse(`<!DOCTYPE html>
+<meta charset="UTF-8">
+<title>Stand-in identity provider</title>
+<p>Development only. Any identity is signed in without a password.</p>
+<form method="POST">
+	{{range $key, $value := .Hidden}}<input type="hidden" name="{{$key}}" value="{{$value}}">
+	{{end}}
+	<label>Email <input name="email" value="u1@example.com" autofocus></label><br>
+	<label>Given name <input name="given_name"></label><br>
+	<label>Family name <input name="family_name"></label><br>
+	<label>Groups <input name="groups" value="admin user"></label><br>
+	<button type="submit">Sign in to {{.ClientID}}</button>
+</form>
+`))
+
+// devAuthorizeParams are passed from the authorize request to the form.
+var devAuthorizeParams = []string{"response_type", "client_id", "redirect_uri", "scope", "state", "nonce", "code_challenge", "code_challenge_method"}
+
+// serveAuthorize shows the sign in form on GET and issues a code on POST.
+func (idp *devIdP) serveAuthorize(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f := r.Form
+	redirect, err := url.Parse(f.Get("redirect_uri"))
+	if err != nil || (redirect.Scheme != "http" && redirect.Scheme != "https") || len(redirect.Host) == 0 {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	switch {
+	case f.Get("response_type") != "code":
+		http.Error(w, "response_type must be code", http.StatusBadRequest)
+		return
+	case len(f.Get("client_id")) == 0:
+		http.Error(w, "missing client_id", http.StatusBadRequest)
+		return
+	case f.Get("code_challenge_method") != "S256" || len(f.Get("code_challenge")) == 0:
+		http.Error(w, "S256 code_challenge required", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		hidden := make(map[string]string, len(devAuthorizeParams))
+		for _, name := range devAuthorizeParams {
+			hidden[name] = f.Get(name)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		devLoginTemplate.Execute(w, map[string]interface{}{
+			"Hidden":   hidden,
+			"ClientID": f.Get("client_id"),
+		})
+		return
+	}
+
+	email := strings.TrimSpace(f.Get("email"))
+	if len(email) == 0 {
+		http.Error(w, "missing email", http.StatusBadRequest)
+		return
+	}
+	groups := strings.Fields(f.Get("groups"))
+	if groups == nil {
+		groups = []string{}
+	}
+	code, err := randomToken()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	now := time.Now()
+	idp.lk.Lock()
+	for c, dc := range idp.codes {
+		if !now.Before(dc.Expire) {
+			delete(idp.codes, c)
+		}
+	}
+	idp.codes[code] = &devCode{
+		ClientID:    f.Get("client_id"),
+		RedirectURI: redirect.String(),
+		Challenge:   f.Get("code_challenge"),
+		Nonce:       f.Get("nonce"),
+		Claims: map[string]interface{}{
+			"sub":            email,
+			"email":          email,
+			"email_verified": true,
+			"given_name":     f.Get("given_name"),
+			"family_name":    f.Get("family_name"),
+			"groups":         groups,
+		},
+		Expire: now.Add(devCodeLifetime),
+	}
+	idp.lk.Unlock()
+
+	q := redirect.Query()
+	q.Set("code", code)
+	q.Set("state", f.Get("state"))
+	redirect.RawQuery = q.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func tokenError(w http.ResponseWriter, code, description string) {
+	writeJSON(w, http.StatusBadRequest, &tokenResponse{
+		Error:            code,
+		ErrorDescription: description,
+	})
+}
+
+// serveToken redeems an authorization code for a signed ID token.
+func (idp *devIdP) serveToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	err := r.ParseForm()
+	if err != nil {
+		tokenError(w, "invalid_request", err.Error())
+		return
+	}
+	f := r.PostForm
+	if f.Get("grant_type") != "authorization_code" {
+		tokenError(w, "unsupported_grant_type", "only authorization_code is supported")
+		return
+	}
+	clientID := f.Get("client_id")
+	if id, _, ok := r.BasicAuth(); ok {
+		clientID, _ = url.QueryUnescape(id)
+	}
+
+	code := f.Get("code")
+	idp.lk.Lock()
+	dc, found := idp.codes[code]
+	delete(idp.codes, code)
+	idp.lk.Unlock()
+
+	switch {
+	case !found || !time.Now().Before(dc.Expire):
+		tokenError(w, "invalid_grant", "unknown or expired code")
+		return
+	case dc.ClientID != clientID:
+		tokenError(w, "invalid_grant", "code was issued to another client")
+		return
+	case dc.RedirectURI != f.Get("redirect_uri"):
+		tokenError(w, "invalid_grant", "redirect_uri does not match")
+		return
+	case subtle.ConstantTimeCompare([]byte(codeChallenge(f.Get("code_verifier"))), []byte(dc.Challenge)) != 1:
+		tokenError(w, "invalid_grant", "code_verifier does not match")
+		return
+	}
+
+	now := time.Now()
+	claims := dc.Claims
+	claims["iss"] = idp.issuer
+	claims["aud"] = dc.ClientID
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(5 * time.Minute).Unix()
+	if len(dc.Nonce) > 0 {
+		claims["nonce"] = dc.Nonce
+	}
+	idToken, err := idp.sign(claims)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	accessToken, err := randomToken()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+// sign returns claims as an RS256 signed JSON web token.
+func (idp *devIdP) sign(claims map[string]interface{}) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": "dev", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	sum := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
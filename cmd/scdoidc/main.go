@@ -0,0 +1,364 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// scdoidc is an authorization service that signs users in with an external
+// OpenID Connect identity provider. It may be used in place of scdauth.
+//
+// Users are sent to the identity provider with the authorization code flow
+// and PKCE. The claims of the returned ID token are mapped to the session
+// user. Values of the role claim are matched to the names of the role catalog.
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/solidcoredata/scd/api"
+	"github.com/solidcoredata/scd/service"
+
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	issuer       = flag.String("issuer", "", "OpenID Connect issuer URL of the identity provider")
+	clientID     = flag.String("client-id", "", "client ID registered with the identity provider")
+	clientSecret = flag.String("client-secret", "", "client secret registered with the identity provider, empty for a public client")
+	scopes       = flag.String("scopes", "openid profile email", "space separated scopes to request")
+
+	identityClaim = flag.String("identity-claim", "email", "ID token claim used as the identity")
+	roleClaim     = flag.String("role-claim", "groups", "ID token claim listing the role names of the user")
+
+	sessionLifetime = flag.Duration("session-lifetime", 12*time.Hour, "absolute lifetime of a login session and its cookie")
+	devIdPAddr      = flag.String("dev-idp", "", "run a stand-in identity provider on this address and use it as the issuer, for development only")
+)
+
+func main() {
+	ctx := context.TODO()
+	s := service.New()
+	sc := NewServiceConfig()
+	s.Setup(ctx, sc)
+}
+
+var (
+	_ service.Configration = &ServiceConfig{}
+	_ service.Initer       = &ServiceConfig{}
+	_ service.Stopper      = &ServiceConfig{}
+)
+
+func NewServiceConfig() *ServiceConfig {
+	return &ServiceConfig{
+		sessions: newSessionStore(),
+	}
+}
+
+type ServiceConfig struct {
+	provider *provider
+	sessions *sessionStore
+
+	// claims names the ID token claims of the session user.
+	claims claimMap
+
+	// rlk guards roles, which maps role names of the catalog to role IDs.
+	rlk   sync.RWMutex
+	roles map[string]int64
+}
+
+// Init discovers the identity provider from the command line flags.
+func (s *ServiceConfig) Init(ctx context.Context) error {
+	if *sessionLifetime <= 0 {
+		return fmt.Errorf("-session-lifetime must be positive")
+	}
+	s.sessions.lifetime = *sessionLifetime
+
+	iss, id := *issuer, *clientID
+	if len(*devIdPAddr) > 0 {
+		idp, err := startDevIdP(ctx, *devIdPAddr)
+		if err != nil {
+			return err
+		}
+		iss = idp.issuer
+		if len(id) == 0 {
+			id = "scdoidc"
+		}
+		fmt.Printf("dev-idp: stand-in identity provider at %s, do not use in production\n", iss)
+	}
+	if len(iss) == 0 {
+		return fmt.Errorf("missing -issuer")
+	}
+	if len(id) == 0 {
+		return fmt.Errorf("missing -client-id")
+	}
+	scopeList := strings.Fields(*scopes)
+	if !contains(scopeList, "openid") {
+		return fmt.Errorf("-scopes must include openid")
+	}
+
+	dctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	p, err := discover(dctx, iss, id, *clientSecret, scopeList)
+	if err != nil {
+		return err
+	}
+	s.provider = p
+	s.claims = claimMap{
+		Identity: *identityClaim,
+		Role:     *roleClaim,
+	}
+	go s.sessions.run(ctx, time.Minute*5)
+	return nil
+}
+
+func (s *ServiceConfig) HTTPServer() (api.HTTPServer, bool) {
+	return s, true
+}
+func (s *ServiceConfig) AuthServer() (api.AuthServer, bool) {
+	return s, true
+}
+
+// BundleUpdate keeps the role catalog to map role claims to role IDs.
+func (s *ServiceConfig) BundleUpdate(sb *api.ServiceBundle) {
+	roles := make(map[string]int64, len(sb.Role))
+	for _, r := range sb.Role {
+		roles[r.Name] = r.ID
+	}
+	s.rlk.Lock()
+	s.roles = roles
+	s.rlk.Unlock()
+
+	// Session roles are mapped on each request.
+	s.sessions.invalidate.Publish(&api.Invalidation{All: true})
+}
+
+// roleIDs returns the IDs of the catalog roles in names. Unknown names
+// are ignored.
+func (s *ServiceConfig) roleIDs(names []string) []int64 {
+	s.rlk.RLock()
+	defer s.rlk.RUnlock()
+
+	var ids []int64
+	for _, name := range names {
+		if id, found := s.roles[name]; found {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (s *ServiceConfig) ServeHTTPStream(stream api.HTTP_ServeHTTPStreamServer) error {
+	return api.ServeStreamUnary(stream, s)
+}
+
+func (s *ServiceConfig) ServeHTTP(ctx context.Context, r *api.HTTPRequest) (*api.HTTPResponse, error) {
+	resp := &api.HTTPResponse{}
+	switch r.URL.Path {
+	default:
+		return nil, grpc.Errorf(codes.NotFound, "path %q not found", r.URL.Path)
+	case "login":
+		return s.serveLogin(ctx, r)
+	case "callback":
+		return s.serveCallback(ctx, r)
+	case "logout":
+		if c, err := r.Cookie(r.Auth.TokenKey); err == nil {
+			s.sessions.remove(c.Value)
+		}
+		resp.Header = api.NewKeyValueList(nil)
+		resp.Header.Add("Set-Cookie", r.SessionCookie("", -1).String())
+	}
+	return resp, nil
+}
+
+// stateCookieName is the cookie that binds a pending login to the browser
+// that started it.
+func stateCookieName(r *api.HTTPRequest) string {
+	return r.Auth.TokenKey + "-login"
+}
+
+// stateCookie returns the state cookie of a pending login. A negative
+// lifetime removes the cookie.
+func stateCookie(r *api.HTTPRequest, state string, lifetime time.Duration) *http.Cookie {
+	c := r.SessionCookie(state, lifetime)
+	c.Name = stateCookieName(r)
+	return c
+}
+
+// serveLogin sends the user to the identity provider. The login resource
+// configures the absolute path of the callback resource:
+//
+//	{Callback: "/login/callback"}
+func (s *ServiceConfig) serveLogin(ctx context.Context, r *api.HTTPRequest) (*api.HTTPResponse, error) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return nil, grpc.Errorf(codes.InvalidArgument, "method %s not allowed", r.Method)
+	}
+	c := struct {
+		Callback string
+	}{}
+	if r.Config != nil && len(r.Config.Config) > 0 {
+		err := json.Unmarshal([]byte(r.Config.Config), &c)
+		if err != nil {
+			return nil, grpc.Errorf(codes.FailedPrecondition, "invalid login configuration: %v", err)
+		}
+	}
+	if !strings.HasPrefix(c.Callback, "/") {
+		return nil, grpc.Errorf(codes.FailedPrecondition, "login configuration must set the Callback path")
+	}
+	scheme := "http"
+	if r.Secure {
+		scheme = "https"
+	}
+	redirectURI := scheme + "://" + r.Host + c.Callback
+
+	pl, err := s.sessions.begin(redirectURI, localRedirect(r.URL.Query.Get("redirect-to")), scopeOf(r.AuthConfig), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	resp := &api.HTTPResponse{
+		Header:   api.NewKeyValueList(nil),
+		Redirect: s.provider.authCodeURL(pl),
+	}
+	resp.Header.Add("Set-Cookie", stateCookie(r, pl.State, pendingLifetime).String())
+	return resp, nil
+}
+
+// serveCallback finishes the login when the identity provider returns the
+// user with an authorization code.
+func (s *ServiceConfig) serveCallback(ctx context.Context, r *api.HTTPRequest) (*api.HTTPResponse, error) {
+	q := r.URL.Query
+	state := q.Get("state")
+
+	// The pending login is removed first so a state is only used once.
+	pl, found := s.sessions.finish(state, time.Now())
+	c, err := r.Cookie(stateCookieName(r))
+	if err != nil || subtle.ConstantTimeCompare([]byte(c.Value), []byte(state)) != 1 {
+		return nil, grpc.Errorf(codes.InvalidArgument, "login was not started by this browser")
+	}
+	if !found {
+		return nil, grpc.Errorf(codes.InvalidArgument, "unknown or expired login, sign in again")
+	}
+	if pl.Scope != scopeOf(r.AuthConfig) {
+		return nil, grpc.Errorf(codes.InvalidArgument, "login was started by another application")
+	}
+	if e := q.Get("error"); len(e) > 0 {
+		return nil, grpc.Errorf(codes.PermissionDenied, "identity provider: %s %s", e, q.Get("error_description"))
+	}
+
+	claims, err := s.provider.exchange(ctx, q.Get("code"), pl)
+	if err != nil {
+		fmt.Printf("login: %v\n", err)
+		return nil, grpc.Errorf(codes.PermissionDenied, "%v", err)
+	}
+	u, err := s.claims.user(claims)
+	if err != nil {
+		fmt.Printf("login: %v\n", err)
+		return nil, grpc.Errorf(codes.PermissionDenied, "%v", err)
+	}
+	if !u.admit(pl.Scope) {
+		fmt.Printf("login: %q may not use environment %q\n", u.Identity, pl.Scope.Environment)
+		return nil, grpc.Errorf(codes.PermissionDenied, "%q may not use this environment", u.Identity)
+	}
+	token, err := s.sessions.create(u, pl.Scope, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("login: %q from %s\n", u.Identity, r.RemoteAddr)
+
+	resp := &api.HTTPResponse{
+		Header:   api.NewKeyValueList(nil),
+		Redirect: pl.RedirectTo,
+	}
+	resp.Header.Add("Set-Cookie", stateCookie(r, "", -1).String())
+	resp.Header.Add("Set-Cookie", r.SessionCookie(token, s.sessions.lifetime).String())
+	return resp, nil
+}
+
+// localRedirect returns next if it is a path on the same host, otherwise "/".
+func localRedirect(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
+
+func (s *ServiceConfig) RequestAuth(ctx context.Context, r *api.RequestAuthReq) (*api.RequestAuthResp, error) {
+	ra := &api.RequestAuthResp{
+		LoginState: api.LoginState_None,
+	}
+	t, found := s.sessions.get(r.Token, scopeOf(r.Configuration), time.Now())
+	if !found || !t.User.admit(t.Scope) {
+		return ra, nil
+	}
+	var err error
+	ra.ValidUntil, err = ptypes.TimestampProto(t.Expire)
+	if err != nil {
+		return nil, err
+	}
+	ra.LoginState = api.LoginState_Granted
+	ra.Identity = t.User.Identity
+	ra.Email = t.User.Email
+	ra.GivenName = t.User.GivenName
+	ra.FamilyName = t.User.FamilyName
+	ra.Roles = s.roleIDs(t.User.RoleNames)
+	return ra, nil
+}
+
+// errProvider is returned for requests the identity provider handles.
+var errProvider = grpc.Errorf(codes.Unimplemented, "passwords are managed by the identity provider")
+
+func (s *ServiceConfig) Login(ctx context.Context, r *api.LoginReq) (*api.LoginResp, error) {
+	return nil, errProvider
+}
+
+func (s *ServiceConfig) Logout(ctx context.Context, r *api.LogoutReq) (*api.LogoutResp, error) {
+	switch v := r.Value.(type) {
+	default:
+		return nil, grpc.Errorf(codes.InvalidArgument, "missing session token or identity")
+	case *api.LogoutReq_SessionTokenValue:
+		s.sessions.remove(v.SessionTokenValue)
+	case *api.LogoutReq_Identity:
+		return nil, grpc.Errorf(codes.Unimplemented, "users are managed by the identity provider")
+	}
+	return &api.LogoutResp{}, nil
+}
+
+func (s *ServiceConfig) NewPassword(ctx context.Context, r *api.NewPasswordReq) (*api.NewPasswordResp, error) {
+	return nil, errProvider
+}
+
+func (s *ServiceConfig) ChangePassword(ctx context.Context, r *api.ChangePasswordReq) (*api.ChangePasswordResp, error) {
+	return nil, errProvider
+}
+
+func (s *ServiceConfig) Elevate(ctx context.Context, r *api.ElevateReq) (*api.ElevateResp, error) {
+	return nil, errProvider
+}
+
+func (s *ServiceConfig) Impersonate(ctx context.Context, r *api.ImpersonateReq) (*api.ImpersonateResp, error) {
+	return nil, grpc.Errorf(codes.Unimplemented, "impersonation is not supported")
+}
+
+func (s *ServiceConfig) Invalidations(r *api.InvalidationsReq, stream api.Auth_InvalidationsServer) error {
+	return s.sessions.invalidate.Serve(stream)
+}
+
+// Stop ends the invalidation streams when the service shuts down.
+func (s *ServiceConfig) Stop() {
+	s.sessions.invalidate.Stop()
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,274 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/solidcoredata/scd/api"
+)
+
+const testTokenKey = "scdtest"
+
+// oidcTest runs the service against the stand-in identity provider.
+type oidcTest struct {
+	t      *testing.T
+	ctx    context.Context
+	cancel func()
+	idp    *devIdP
+	s      *ServiceConfig
+}
+
+func newOIDCTest(t *testing.T) *oidcTest {
+	ctx, cancel := context.WithCancel(context.Background())
+	idp, err := startDevIdP(ctx, "127.0.0.1:0")
+	if err != nil {
+		cancel()
+		t.Fatal(err)
+	}
+	p, err := discover(ctx, idp.issuer, "scdoidc", "", []string{"openid", "email", "profile"})
+	if err != nil {
+		cancel()
+		t.Fatal(err)
+	}
+	s := NewServiceConfig()
+	s.provider = p
+	s.claims = claimMap{Identity: "email", Role: "groups"}
+	s.BundleUpdate(&api.ServiceBundle{Role: []*api.Role{
+		{ID: 1, Name: "admin"},
+		{ID: 2, Name: "user"},
+	}})
+	return &oidcTest{t: t, ctx: ctx, cancel: cancel, idp: idp, s: s}
+}
+
+// close stops the identity provider.
+func (ot *oidcTest) close() {
+	ot.cancel()
+}
+
+func (ot *oidcTest) request(path string, query url.Values, scope *api.ConfigureAuth, cookies ...*http.Cookie) *api.HTTPRequest {
+	h := http.Header{}
+	for _, c := range cookies {
+		h.Add("Cookie", c.String())
+	}
+	return &api.HTTPRequest{
+		Host:       "app.example.test",
+		Method:     http.MethodGet,
+		URL:        &api.URL{Path: path, Query: api.NewKeyValueList(query)},
+		Header:     api.NewKeyValueList(h),
+		RemoteAddr: "127.0.0.1:1",
+		Auth:       &api.RequestAuthResp{TokenKey: testTokenKey},
+		Config:     &api.ConfigureURL{Config: `{"Callback": "/login/callback"}`},
+		AuthConfig: scope,
+	}
+}
+
+func responseCookies(resp *api.HTTPResponse) map[string]*http.Cookie {
+	h := http.Header{}
+	if resp.Header != nil {
+		if v, ok := resp.Header.Values["Set-Cookie"]; ok {
+			h["Set-Cookie"] = v.Value
+		}
+	}
+	cookies := make(map[string]*http.Cookie)
+	for _, c := range (&http.Response{Header: h}).Cookies() {
+		cookies[c.Name] = c
+	}
+	return cookies
+}
+
+// login starts a login and returns the state cookie and the authorize URL.
+func (ot *oidcTest) login(scope *api.ConfigureAuth) (*http.Cookie, *url.URL) {
+	resp, err := ot.s.ServeHTTP(ot.ctx, ot.request("login", url.Values{"redirect-to": {"/app/"}}, scope))
+	if err != nil {
+		ot.t.Fatal(err)
+	}
+	state := responseCookies(resp)[testTokenKey+"-login"]
+	if state == nil {
+		ot.t.Fatal("login did not set the state cookie")
+	}
+	u, err := url.Parse(resp.Redirect)
+	if err != nil {
+		ot.t.Fatal(err)
+	}
+	if !strings.HasPrefix(resp.Redirect, ot.idp.issuer+"/authorize?") {
+		ot.t.Fatalf("login redirected to %q", resp.Redirect)
+	}
+	return state, u
+}
+
+// authorize signs in email at the identity provider, with the authorize
+// parameters changed by edit, and returns the callback query.
+func (ot *oidcTest) authorize(authURL *url.URL, email string, edit func(url.Values)) url.Values {
+	f := authURL.Query()
+	f.Set("email", email)
+	f.Set("groups", "admin user")
+	if edit != nil {
+		edit(f)
+	}
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.PostForm(ot.idp.issuer+"/authorize", f)
+	if err != nil {
+		ot.t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		ot.t.Fatalf("authorize status %d", resp.StatusCode)
+	}
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		ot.t.Fatal(err)
+	}
+	if callback.Path != "/login/callback" {
+		ot.t.Fatalf("identity provider redirected to %q", callback)
+	}
+	return callback.Query()
+}
+
+func (ot *oidcTest) callback(query url.Values, scope *api.ConfigureAuth, state *http.Cookie) (*api.HTTPResponse, error) {
+	return ot.s.ServeHTTP(ot.ctx, ot.request("callback", query, scope, state))
+}
+
+func (ot *oidcTest) requestAuth(token string, scope *api.ConfigureAuth) *api.RequestAuthResp {
+	ra, err := ot.s.RequestAuth(ot.ctx, &api.RequestAuthReq{Token: token, Configuration: scope})
+	if err != nil {
+		ot.t.Fatal(err)
+	}
+	return ra
+}
+
+func TestLoginFlow(t *testing.T) {
+	ot := newOIDCTest(t)
+	defer ot.close()
+	scope := &api.ConfigureAuth{Area: api.ConfigureAuth_System, Environment: "prod"}
+
+	state, authURL := ot.login(scope)
+	resp, err := ot.callback(ot.authorize(authURL, "u1@example.com", nil), scope, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Redirect != "/app/" {
+		t.Fatalf("callback redirected to %q", resp.Redirect)
+	}
+	session := responseCookies(resp)[testTokenKey]
+	if session == nil || len(session.Value) == 0 {
+		t.Fatal("callback did not set the session cookie")
+	}
+
+	ra := ot.requestAuth(session.Value, scope)
+	if ra.LoginState != api.LoginState_Granted {
+		t.Fatalf("login state %v", ra.LoginState)
+	}
+	if ra.Identity != "u1@example.com" {
+		t.Fatalf("identity %q", ra.Identity)
+	}
+	if len(ra.Roles) != 2 || ra.Roles[0] != 1 || ra.Roles[1] != 2 {
+		t.Fatalf("roles %v", ra.Roles)
+	}
+
+	// The session is not used in other environments.
+	other := &api.ConfigureAuth{Area: api.ConfigureAuth_System, Environment: "test"}
+	if ra := ot.requestAuth(session.Value, other); ra.LoginState != api.LoginState_None {
+		t.Fatalf("other environment login state %v", ra.LoginState)
+	}
+
+	_, err = ot.s.Logout(ot.ctx, &api.LogoutReq{Value: &api.LogoutReq_SessionTokenValue{SessionTokenValue: session.Value}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ra := ot.requestAuth(session.Value, scope); ra.LoginState != api.LoginState_None {
+		t.Fatalf("login state after logout %v", ra.LoginState)
+	}
+}
+
+func TestLoginUserEnvironment(t *testing.T) {
+	ot := newOIDCTest(t)
+	defer ot.close()
+	scope := &api.ConfigureAuth{Area: api.ConfigureAuth_User, Environment: "u1@example.com"}
+
+	// Only the owner of a User area environment may sign in to it.
+	state, authURL := ot.login(scope)
+	_, err := ot.callback(ot.authorize(authURL, "u2@example.com", nil), scope, state)
+	if err == nil {
+		t.Fatal("another user signed in to the user environment")
+	}
+
+	state, authURL = ot.login(scope)
+	resp, err := ot.callback(ot.authorize(authURL, "U1@example.com", nil), scope, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := responseCookies(resp)[testTokenKey]
+	if ra := ot.requestAuth(session.Value, scope); ra.LoginState != api.LoginState_Granted {
+		t.Fatalf("owner login state %v", ra.LoginState)
+	}
+}
+
+func TestLoginRejected(t *testing.T) {
+	ot := newOIDCTest(t)
+	defer ot.close()
+	scope := &api.ConfigureAuth{Area: api.ConfigureAuth_System, Environment: "prod"}
+
+	list := []struct {
+		name string
+		run  func() error
+	}{
+		{"nonce", func() error {
+			state, authURL := ot.login(scope)
+			q := ot.authorize(authURL, "u1@example.com", func(f url.Values) {
+				f.Set("nonce", "replayed")
+			})
+			_, err := ot.callback(q, scope, state)
+			return err
+		}},
+		{"state", func() error {
+			_, authURL := ot.login(scope)
+			other, _ := ot.login(scope)
+			q := ot.authorize(authURL, "u1@example.com", nil)
+			_, err := ot.callback(q, scope, other)
+			return err
+		}},
+		{"unknown state", func() error {
+			state, authURL := ot.login(scope)
+			q := ot.authorize(authURL, "u1@example.com", nil)
+			q.Set("state", "forged")
+			state.Value = "forged"
+			_, err := ot.callback(q, scope, state)
+			return err
+		}},
+		{"expired code", func() error {
+			state, authURL := ot.login(scope)
+			q := ot.authorize(authURL, "u1@example.com", nil)
+			ot.idp.lk.Lock()
+			ot.idp.codes[q.Get("code")].Expire = time.Now().Add(-time.Second)
+			ot.idp.lk.Unlock()
+			_, err := ot.callback(q, scope, state)
+			return err
+		}},
+		{"scope", func() error {
+			state, authURL := ot.login(scope)
+			q := ot.authorize(authURL, "u1@example.com", nil)
+			_, err := ot.callback(q, &api.ConfigureAuth{Area: api.ConfigureAuth_System, Environment: "test"}, state)
+			return err
+		}},
+	}
+	for _, item := range list {
+		if err := item.run(); err == nil {
+			t.Errorf("%s: login was not rejected", item.name)
+		}
+	}
+	if n := len(ot.s.sessions.sessions); n != 0 {
+		t.Fatalf("%d sessions created by rejected logins", n)
+	}
+}
@@ -0,0 +1,477 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// clockSkew is the allowed difference between the clocks of the
+	// identity provider and this service.
+	clockSkew = time.Minute
+
+	// keyRefresh limits how often unknown key IDs fetch the key set again.
+	keyRefresh = time.Minute
+
+	// maxResponse limits the size of identity provider responses.
+	maxResponse = 1 << 20
+)
+
+// provider is an OpenID Connect identity provider.
+type provider struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+
+	AuthURL  string
+	TokenURL string
+	JWKSURL  string
+
+	client *http.Client
+
+	// lk guards the signing keys of the provider by key ID.
+	lk          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	keysFetched time.Time
+}
+
+// discoveryDoc is the provider metadata at the well known configuration URL.
+type discoveryDoc struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	CodeChallengeMethods  []string `json:"code_challenge_methods_supported,omitempty"`
+}
+
+// discover reads the provider metadata and signing keys of issuer.
+func discover(ctx context.Context, issuer, clientID, clientSecret string, scopes []string) (*provider, error) {
+	p := &provider{
+		Issuer:       issuer,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Scopes:       scopes,
+
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+	doc := &discoveryDoc{}
+	err := p.getJSON(ctx, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", doc)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: discovery: %v", err)
+	}
+	if doc.Issuer != issuer {
+		return nil, fmt.Errorf("oidc: discovery: issuer %q does not match %q", doc.Issuer, issuer)
+	}
+	if len(doc.AuthorizationEndpoint) == 0 || len(doc.TokenEndpoint) == 0 || len(doc.JWKSURI) == 0 {
+		return nil, fmt.Errorf("oidc: discovery: missing endpoints")
+	}
+	if len(doc.CodeChallengeMethods) > 0 && !contains(doc.CodeChallengeMethods, "S256") {
+		return nil, fmt.Errorf("oidc: discovery: provider does not support S256 code challenges")
+	}
+	p.AuthURL = doc.AuthorizationEndpoint
+	p.TokenURL = doc.TokenEndpoint
+	p.JWKSURL = doc.JWKSURI
+
+	p.lk.Lock()
+	err = p.refreshKeysLocked(ctx, time.Now())
+	p.lk.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *provider) getJSON(ctx context.Context, u string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", u, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponse)).Decode(v)
+}
+
+// codeChallenge returns the S256 PKCE challenge of verifier.
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// authCodeURL returns the URL the user is sent to to sign in.
+func (p *provider) authCodeURL(pl *pendingLogin) string {
+	v := url.Values{}
+	v.Set("response_type", "code")
+	v.Set("client_id", p.ClientID)
+	v.Set("redirect_uri", pl.RedirectURI)
+	v.Set("scope", strings.Join(p.Scopes, " "))
+	v.Set("state", pl.State)
+	v.Set("nonce", pl.Nonce)
+	v.Set("code_challenge", codeChallenge(pl.CodeVerifier))
+	v.Set("code_challenge_method", "S256")
+	sep := "?"
+	if strings.Contains(p.AuthURL, "?") {
+		sep = "&"
+	}
+	return p.AuthURL + sep + v.Encode()
+}
+
+type tokenResponse struct {
+	IDToken          string `json:"id_token,omitempty"`
+	Error            string `json:"error,omitempty"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// exchange redeems the authorization code of the pending login and returns
+// the verified claims of the ID token.
+func (p *provider) exchange(ctx context.Context, code string, pl *pendingLogin) (map[string]interface{}, error) {
+	if len(code) == 0 {
+		return nil, errors.New("oidc: missing authorization code")
+	}
+	v := url.Values{}
+	v.Set("grant_type", "authorization_code")
+	v.Set("code", code)
+	v.Set("redirect_uri", pl.RedirectURI)
+	v.Set("client_id", p.ClientID)
+	v.Set("code_verifier", pl.CodeVerifier)
+	req, err := http.NewRequest(http.MethodPost, p.TokenURL, strings.NewReader(v.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if len(p.ClientSecret) > 0 {
+		req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+	}
+	resp, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("oidc: token: %v", err)
+	}
+	defer resp.Body.Close()
+
+	tr := &tokenResponse{}
+	err = json.NewDecoder(io.LimitReader(resp.Body, maxResponse)).Decode(tr)
+	switch {
+	case len(tr.Error) > 0:
+		return nil, fmt.Errorf("oidc: token: %s %s", tr.Error, tr.ErrorDescription)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("oidc: token: %s", resp.Status)
+	case err != nil:
+		return nil, fmt.Errorf("oidc: token: %v", err)
+	case len(tr.IDToken) == 0:
+		return nil, errors.New("oidc: token: missing ID token")
+	}
+	return p.verify(ctx, tr.IDToken, pl.Nonce, time.Now())
+}
+
+// verify checks the signature and claims of an ID token and returns the claims.
+func (p *provider) verify(ctx context.Context, token, nonce string, now time.Time) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("oidc: malformed ID token")
+	}
+	header := struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}{}
+	err := decodeSegment(parts[0], &header)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: ID token header: %v", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("oidc: ID token signature: %v", err)
+	}
+	key, err := p.key(ctx, header.Kid, now)
+	if err != nil {
+		return nil, err
+	}
+	err = verifySignature(header.Alg, key, parts[0]+"."+parts[1], sig)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := map[string]interface{}{}
+	err = decodeSegment(parts[1], &claims)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: ID token claims: %v", err)
+	}
+	if claimString(claims, "iss") != p.Issuer {
+		return nil, errors.New("oidc: ID token issued by another provider")
+	}
+	if !hasAudience(claims["aud"], p.ClientID) {
+		return nil, errors.New("oidc: ID token issued to another client")
+	}
+	if azp, ok := claims["azp"].(string); ok && azp != p.ClientID {
+		return nil, errors.New("oidc: ID token authorized another client")
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok || now.After(time.Unix(int64(exp), 0).Add(clockSkew)) {
+		return nil, errors.New("oidc: ID token expired")
+	}
+	if iat, ok := claims["iat"].(float64); ok && time.Unix(int64(iat), 0).After(now.Add(clockSkew)) {
+		return nil, errors.New("oidc: ID token issued in the future")
+	}
+	if subtle.ConstantTimeCompare([]byte(claimString(claims, "nonce")), []byte(nonce)) != 1 {
+		return nil, errors.New("oidc: ID token nonce does not match")
+	}
+	return claims, nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// hasAudience reports if the aud claim, a string or a list, contains clientID.
+func hasAudience(aud interface{}, clientID string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == clientID
+	case []interface{}:
+		for _, a := range aud {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// verifySignature checks the RS256 or ES256 signature of signed.
+func verifySignature(alg string, key crypto.PublicKey, signed string, sig []byte) error {
+	sum := sha256.Sum256([]byte(signed))
+	switch alg {
+	default:
+		return fmt.Errorf("oidc: unsupported ID token algorithm %q", alg)
+	case "RS256":
+		k, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("oidc: ID token key is not an RSA key")
+		}
+		err := rsa.VerifyPKCS1v15(k, crypto.SHA256, sum[:], sig)
+		if err != nil {
+			return errors.New("oidc: invalid ID token signature")
+		}
+	case "ES256":
+		k, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("oidc: ID token key is not an EC key")
+		}
+		if len(sig) != 64 {
+			return errors.New("oidc: invalid ID token signature")
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(k, sum[:], r, s) {
+			return errors.New("oidc: invalid ID token signature")
+		}
+	}
+	return nil
+}
+
+// key returns the signing key of kid. An unknown key ID fetches the key
+// set again, as the provider may have rotated its keys.
+func (p *provider) key(ctx context.Context, kid string, now time.Time) (crypto.PublicKey, error) {
+	p.lk.Lock()
+	defer p.lk.Unlock()
+
+	k, found := p.keyLocked(kid)
+	if found {
+		return k, nil
+	}
+	if now.Sub(p.keysFetched) < keyRefresh {
+		return nil, fmt.Errorf("oidc: unknown ID token key %q", kid)
+	}
+	err := p.refreshKeysLocked(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	k, found = p.keyLocked(kid)
+	if !found {
+		return nil, fmt.Errorf("oidc: unknown ID token key %q", kid)
+	}
+	return k, nil
+}
+
+// keyLocked returns the key of kid. A token without a key ID may only be
+// used when the provider has a single key.
+func (p *provider) keyLocked(kid string) (crypto.PublicKey, bool) {
+	if len(kid) == 0 && len(p.keys) == 1 {
+		for _, k := range p.keys {
+			return k, true
+		}
+	}
+	k, found := p.keys[kid]
+	return k, found
+}
+
+// jwk is a JSON web key.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+
+	// RSA keys.
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// EC keys.
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+func (p *provider) refreshKeysLocked(ctx context.Context, now time.Time) error {
+	p.keysFetched = now
+	set := &jwkSet{}
+	err := p.getJSON(ctx, p.JWKSURL, set)
+	if err != nil {
+		return fmt.Errorf("oidc: key set: %v", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if len(k.Use) > 0 && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			// Keys of other types may be listed for other clients.
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+	if len(keys) == 0 {
+		return errors.New("oidc: key set has no supported signing keys")
+	}
+	p.keys = keys
+	return nil
+}
+
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid RSA key")
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		pub := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, errors.New("invalid EC key")
+		}
+		return pub, nil
+	}
+}
+
+// claimMap names the ID token claims of the session user.
+type claimMap struct {
+	Identity string
+	Role     string
+}
+
+// user returns the session user of verified ID token claims.
+func (cm claimMap) user(claims map[string]interface{}) (*user, error) {
+	u := &user{
+		Subject:    claimString(claims, "sub"),
+		Identity:   strings.TrimSpace(claimString(claims, cm.Identity)),
+		Email:      claimString(claims, "email"),
+		GivenName:  claimString(claims, "given_name"),
+		FamilyName: claimString(claims, "family_name"),
+		RoleNames:  claimStrings(claims, cm.Role),
+	}
+	if len(u.Identity) == 0 {
+		return nil, fmt.Errorf("oidc: ID token is missing the %q claim", cm.Identity)
+	}
+	if cm.Identity == "email" {
+		if verified, ok := claims["email_verified"].(bool); ok && !verified {
+			return nil, errors.New("oidc: email is not verified")
+		}
+	}
+	return u, nil
+}
+
+func claimString(claims map[string]interface{}, name string) string {
+	s, _ := claims[name].(string)
+	return s
+}
+
+// claimStrings returns a list claim, or a space separated string claim.
+func claimStrings(claims map[string]interface{}, name string) []string {
+	switch v := claims[name].(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
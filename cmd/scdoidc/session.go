@@ -0,0 +1,251 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/solidcoredata/scd/api"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// pendingLifetime is how long the user has to sign in with the
+	// identity provider.
+	pendingLifetime = 10 * time.Minute
+
+	// maxPending limits the logins waiting for the identity provider.
+	maxPending = 10000
+)
+
+// authScope is the area and environment of an application. A session may
+// only be used by applications in the scope it was created in.
+type authScope struct {
+	Area        api.ConfigureAuth_AreaType
+	Environment string
+}
+
+// scopeOf returns the scope of an application configuration.
+func scopeOf(c *api.ConfigureAuth) authScope {
+	if c == nil {
+		return authScope{}
+	}
+	return authScope{
+		Area:        c.Area,
+		Environment: c.Environment,
+	}
+}
+
+// user is the session user mapped from the ID token claims.
+type user struct {
+	Subject    string
+	Identity   string
+	Email      string
+	GivenName  string
+	FamilyName string
+
+	// RoleNames are matched to the role catalog on each request.
+	RoleNames []string
+}
+
+// admit reports if u may use applications in scope. A User area
+// environment is the sandbox of the user with that identity.
+func (u *user) admit(scope authScope) bool {
+	if scope.Area == api.ConfigureAuth_User {
+		return strings.EqualFold(strings.TrimSpace(scope.Environment), u.Identity)
+	}
+	return true
+}
+
+type session struct {
+	User    *user
+	Scope   authScope
+	Created time.Time
+	Expire  time.Time
+}
+
+// pendingLogin is a login sent to the identity provider that has not
+// returned yet.
+type pendingLogin struct {
+	State        string
+	Nonce        string
+	CodeVerifier string
+
+	// RedirectURI is the callback URL sent to the identity provider.
+	RedirectURI string
+
+	// RedirectTo is the local path the user is sent to after the login.
+	RedirectTo string
+
+	Scope  authScope
+	Expire time.Time
+}
+
+// sessionStore keeps sessions and pending logins in memory. Sessions end
+// when the service restarts; users sign in with the identity provider again.
+type sessionStore struct {
+	lk sync.Mutex
+
+	// lifetime is the absolute lifetime of a session.
+	lifetime time.Duration
+
+	sessions map[string]*session
+	pending  map[string]*pendingLogin
+
+	// invalidate sends the sessions that end to routers caching
+	// request authentications.
+	invalidate *api.Invalidator
+}
+
+func newSessionStore() *sessionStore {
+	return &sessionStore{
+		lifetime: 12 * time.Hour,
+		sessions: make(map[string]*session, 50),
+		pending:  make(map[string]*pendingLogin, 50),
+
+		invalidate: &api.Invalidator{},
+	}
+}
+
+// randomToken returns a random URL safe value.
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// begin starts a login with a new state, nonce, and PKCE code verifier.
+func (ss *sessionStore) begin(redirectURI, redirectTo string, scope authScope, now time.Time) (*pendingLogin, error) {
+	pl := &pendingLogin{
+		RedirectURI: redirectURI,
+		RedirectTo:  redirectTo,
+		Scope:       scope,
+		Expire:      now.Add(pendingLifetime),
+	}
+	for _, v := range []*string{&pl.State, &pl.Nonce, &pl.CodeVerifier} {
+		var err error
+		*v, err = randomToken()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ss.lk.Lock()
+	defer ss.lk.Unlock()
+
+	if len(ss.pending) >= maxPending {
+		return nil, grpc.Errorf(codes.ResourceExhausted, "too many logins in progress, try again later")
+	}
+	ss.pending[pl.State] = pl
+	return pl, nil
+}
+
+// finish removes and returns the pending login of state if it has not expired.
+func (ss *sessionStore) finish(state string, now time.Time) (*pendingLogin, bool) {
+	ss.lk.Lock()
+	defer ss.lk.Unlock()
+
+	pl, found := ss.pending[state]
+	if !found {
+		return nil, false
+	}
+	delete(ss.pending, state)
+	if !now.Before(pl.Expire) {
+		return nil, false
+	}
+	return pl, true
+}
+
+// create returns the token of a new session for u.
+func (ss *sessionStore) create(u *user, scope authScope, now time.Time) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	ss.lk.Lock()
+	defer ss.lk.Unlock()
+
+	ss.sessions[token] = &session{
+		User:    u,
+		Scope:   scope,
+		Created: now,
+		Expire:  now.Add(ss.lifetime),
+	}
+	return token, nil
+}
+
+// get returns the session of token if it is valid in scope.
+func (ss *sessionStore) get(token string, scope authScope, now time.Time) (*session, bool) {
+	ss.lk.Lock()
+	defer ss.lk.Unlock()
+
+	t, found := ss.sessions[token]
+	if !found {
+		return nil, false
+	}
+	if !now.Before(t.Expire) {
+		delete(ss.sessions, token)
+		return nil, false
+	}
+	if t.Scope != scope {
+		// The session belongs to another environment.
+		return nil, false
+	}
+	return t, true
+}
+
+func (ss *sessionStore) remove(token string) {
+	ss.lk.Lock()
+	delete(ss.sessions, token)
+	ss.lk.Unlock()
+	ss.invalidate.Token(token)
+}
+
+// removeExpired deletes expired sessions and pending logins and returns
+// the number removed.
+func (ss *sessionStore) removeExpired(now time.Time) int {
+	ss.lk.Lock()
+	defer ss.lk.Unlock()
+
+	n := 0
+	for token, t := range ss.sessions {
+		if !now.Before(t.Expire) {
+			delete(ss.sessions, token)
+			n++
+		}
+	}
+	for state, pl := range ss.pending {
+		if !now.Before(pl.Expire) {
+			delete(ss.pending, state)
+			n++
+		}
+	}
+	return n
+}
+
+// run removes expired sessions every interval until ctx is canceled.
+func (ss *sessionStore) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			ss.removeExpired(now)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/solidcoredata/scd/api"
+)
+
+// adminHandler serves the router status and resources as a web page on "/"
+// and as JSON on "/json".
+type adminHandler struct {
+	s *RouterServer
+}
+
+// adminView is the router status with the resources of the same run.
+type adminView struct {
+	*api.StatusResp
+	Resource []*api.StatusResource
+}
+
+func (h adminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	status, err := h.s.Status(r.Context(), &api.StatusReq{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp := adminView{StatusResp: status}
+	if rr := h.s.currentRouter(); rr != nil && rr.Version == status.Version {
+		resp.Resource = statusResources(rr)
+	}
+	switch r.URL.Path {
+	default:
+		http.NotFound(w, r)
+	case "/json":
+		w.Header().Set("Content-Type", "application/json")
+		e := json.NewEncoder(w)
+		e.SetIndent("", "\t")
+		e.Encode(resp)
+	case "/":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err = adminPage.Execute(w, resp)
+		if err != nil {
+			log.Printf("router: admin page: %v", err)
+		}
+	}
+}
+
+var adminPage = template.Must(template.New("admin").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>scdrouter</title>
+<style>
+body { font-family: sans-serif; margin: 1em 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 2px 8px; text-align: left; vertical-align: top; }
+.error { color: #a00; }
+</style>
+</head>
+<body>
+<h1>scdrouter</h1>
+{{if .Version}}<p>Version <code>{{.Version}}</code> (<a href="json">json</a>)</p>{{else}}<p>Not configured.</p>{{end}}
+
+{{if .Error}}
+<h2 class="error">Errors</h2>
+<table>
+<tr><th>Kind</th><th>Service</th><th>Message</th></tr>
+{{range .Error}}<tr class="error"><td>{{.Kind}}</td><td>{{range .Service}}{{.}}<br>{{end}}</td><td>{{.Message}}</td></tr>
+{{end}}</table>
+{{end}}
+
+<h2>Services</h2>
+<table>
+<tr><th>Name</th><th>Address</th><th>State</th></tr>
+{{range .Service}}<tr><td>{{.Name}}</td><td>{{.Address}}</td><td>{{.State}}</td></tr>
+{{end}}</table>
+
+<h2>Applications</h2>
+{{range .App}}
+<h3>{{range $i, $h := .Host}}{{if $i}}, {{end}}{{$h}}{{end}}</h3>
+<p>Service <code>{{.Service}}</code>, auth <code>{{.Auth}}</code>{{if .Bind}}, bound by <code>{{.Bind}}</code>{{end}}</p>
+<table>
+<tr><th>Login State</th><th>Prefix</th><th>Bundle</th><th>URL</th><th>Resource</th><th>Role</th><th>Elevate</th></tr>
+{{range .LoginBundle}}{{$lb := .}}{{range $i, $u := .URL}}<tr>{{if not $i}}<td rowspan="{{len $lb.URL}}">{{$lb.LoginState}}</td><td rowspan="{{len $lb.URL}}">{{$lb.Prefix}}</td><td rowspan="{{len $lb.URL}}">{{$lb.Bundle}}</td>{{end}}<td>{{$u.Pattern}}{{if $u.Stream}} (stream){{end}}</td><td>{{$u.Resource}}</td><td>{{range $u.Role}}{{.}}<br>{{end}}</td><td>{{if $u.Elevate}}yes{{end}}</td></tr>
+{{else}}<tr><td>{{$lb.LoginState}}</td><td>{{$lb.Prefix}}</td><td>{{$lb.Bundle}}</td><td></td><td></td><td></td></tr>
+{{end}}{{end}}</table>
+{{end}}
+
+<h2>Resources</h2>
+<table>
+<tr><th>Name</th><th>Type</th><th>Consume</th><th>Parent</th><th>Include</th><th>Role</th><th>Elevate</th></tr>
+{{range .Resource}}<tr><td>{{.Name}}</td><td>{{.Type}}</td><td>{{.Consume}}</td><td>{{.Parent}}</td><td>{{range .Include}}{{.}}<br>{{end}}</td><td>{{range .Role}}{{.}}<br>{{end}}</td><td>{{if .Elevate}}yes{{end}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
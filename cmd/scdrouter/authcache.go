@@ -0,0 +1,250 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"container/list"
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/solidcoredata/scd/api"
+
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// authCacheKey identifies the authentication of a session token for
+// applications in the same area and environment.
+type authCacheKey struct {
+	Service     string // Name of the authenticator service.
+	Token       string
+	Area        api.ConfigureAuth_AreaType
+	Environment string
+}
+
+type authCacheEntry struct {
+	key    authCacheKey
+	resp   *api.RequestAuthResp
+	expire time.Time
+}
+
+// authCache keeps recent RequestAuth results so each request does not wait
+// on the authenticator. Results of an authenticator are only cached while
+// its invalidation stream is connected, and are dropped when it reports
+// the session ended or changed.
+//
+// Results of impersonating sessions are not cached so each request is
+// audited by the authenticator. A cached result does not extend the idle
+// expiry of the session; the cache lifetime should be short compared to it.
+type authCache struct {
+	lk   sync.Mutex
+	ttl  time.Duration
+	size int
+
+	entries map[authCacheKey]*list.Element
+	lru     *list.List // Of *authCacheEntry, most recently used first.
+
+	// watching counts the connected invalidation streams of each service.
+	watching map[string]int
+
+	// gen of a service increases with each invalidation. A result is only
+	// stored if no invalidation arrived while it was requested.
+	gen map[string]uint64
+}
+
+func newAuthCache() *authCache {
+	return &authCache{
+		entries:  make(map[authCacheKey]*list.Element, 100),
+		lru:      list.New(),
+		watching: make(map[string]int, 5),
+		gen:      make(map[string]uint64, 5),
+	}
+}
+
+// configure sets the cache lifetime and size, dropping entries over the size.
+func (c *authCache) configure(ttl time.Duration, size int) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	c.ttl, c.size = ttl, size
+	if ttl <= 0 {
+		c.size = 0
+	}
+	c.trimLocked()
+}
+
+func (c *authCache) trimLocked() {
+	for c.lru.Len() > c.size {
+		c.removeLocked(c.lru.Back())
+	}
+}
+
+func (c *authCache) removeLocked(el *list.Element) {
+	e := c.lru.Remove(el).(*authCacheEntry)
+	delete(c.entries, e.key)
+}
+
+// get returns a copy of the cached result of key. If not found, the
+// returned generation is passed to put with the result.
+func (c *authCache) get(key authCacheKey, now time.Time) (*api.RequestAuthResp, uint64, bool) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	gen := c.gen[key.Service]
+	el, found := c.entries[key]
+	if !found {
+		return nil, gen, false
+	}
+	e := el.Value.(*authCacheEntry)
+	if !now.Before(e.expire) {
+		c.removeLocked(el)
+		return nil, gen, false
+	}
+	c.lru.MoveToFront(el)
+	resp := *e.resp
+	return &resp, gen, true
+}
+
+// put caches resp for key unless the service was invalidated since gen.
+// The result is kept no longer than the session is valid.
+func (c *authCache) put(key authCacheKey, resp *api.RequestAuthResp, gen uint64, now time.Time) {
+	switch {
+	case resp.Secondary != nil:
+		return
+	case resp.LoginState == api.LoginState_Missing, resp.LoginState == api.LoginState_Error:
+		return
+	}
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	if c.size == 0 || c.watching[key.Service] == 0 || c.gen[key.Service] != gen {
+		return
+	}
+	expire := now.Add(c.ttl)
+	if resp.ValidUntil != nil {
+		until, err := ptypes.Timestamp(resp.ValidUntil)
+		if err != nil {
+			return
+		}
+		if until.Before(expire) {
+			expire = until
+		}
+	}
+	if !now.Before(expire) {
+		return
+	}
+
+	cached := *resp
+	e := &authCacheEntry{key: key, resp: &cached, expire: expire}
+	if el, found := c.entries[key]; found {
+		el.Value = e
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(e)
+	c.trimLocked()
+}
+
+// invalidate drops the cached results of service named by inv.
+func (c *authCache) invalidate(service string, inv *api.Invalidation) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	c.gen[service]++
+	token := make(map[string]bool, len(inv.Token))
+	for _, t := range inv.Token {
+		token[t] = true
+	}
+	identity := make(map[string]bool, len(inv.Identity))
+	for _, id := range inv.Identity {
+		identity[id] = true
+	}
+	for el := c.lru.Front(); el != nil; {
+		next := el.Next()
+		e := el.Value.(*authCacheEntry)
+		if e.key.Service == service && (inv.All || token[e.key.Token] || identity[e.resp.Identity]) {
+			c.removeLocked(el)
+		}
+		el = next
+	}
+}
+
+// watch marks the invalidation stream of service as connected or not.
+// All cached results of service are dropped either way.
+func (c *authCache) watch(service string, connected bool) {
+	c.lk.Lock()
+	if connected {
+		c.watching[service]++
+	} else {
+		c.watching[service]--
+		if c.watching[service] <= 0 {
+			delete(c.watching, service)
+		}
+	}
+	c.lk.Unlock()
+
+	c.invalidate(service, &api.Invalidation{All: true})
+}
+
+// requestAuth returns the authentication of the request, from the cache if
+// it is there.
+func (s *RouterServer) requestAuth(ctx context.Context, app *App, req *api.RequestAuthReq) (*api.RequestAuthResp, error) {
+	key := authCacheKey{
+		Service: app.AuthService,
+		Token:   req.Token,
+	}
+	if app.AuthConfig != nil {
+		key.Area = app.AuthConfig.Area
+		key.Environment = app.AuthConfig.Environment
+	}
+	now := time.Now()
+	resp, gen, found := s.authCache.get(key, now)
+	if found {
+		return resp, nil
+	}
+	resp, err := app.Auth.RequestAuth(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	s.authCache.put(key, resp, gen, now)
+	return resp, nil
+}
+
+// watchInvalidations drops the cached authentications of the service as it
+// reports sessions that end or change, until ctx is done or the stream
+// ends. Services that are not authenticators return Unimplemented.
+func (s *RouterServer) watchInvalidations(ctx context.Context, serviceName string, conn *grpc.ClientConn) {
+	stream, err := api.NewAuthClient(conn).Invalidations(ctx, &api.InvalidationsReq{})
+	if err != nil {
+		return
+	}
+	connected := false
+	defer func() {
+		if connected {
+			s.authCache.watch(serviceName, false)
+		}
+	}()
+	for {
+		inv, err := stream.Recv()
+		if err != nil {
+			switch grpc.Code(err) {
+			case codes.Unimplemented, codes.Canceled:
+			default:
+				log.Printf("router: invalidations from %q ended %v", serviceName, err)
+			}
+			return
+		}
+		if !connected {
+			// The stream starts with an invalidation of All.
+			connected = true
+			s.authCache.watch(serviceName, true)
+			continue
+		}
+		s.authCache.invalidate(serviceName, inv)
+	}
+}
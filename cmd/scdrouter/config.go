@@ -0,0 +1,321 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/solidcoredata/scd/service"
+)
+
+// Duration is a time.Duration read from a string such as "30s".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30s\": %v", err)
+	}
+	if len(s) == 0 {
+		*d = 0
+		return nil
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// AppConfig are router settings for a single application host.
+type AppConfig struct {
+	// MaxBodySize is the request body limit in bytes. If zero the
+	// RouterConfig.MaxBodySize is used.
+	MaxBodySize int64
+
+	// AllowOrigin lists other origins, such as "https://admin.example.com",
+	// that may send state changing requests to the application.
+	AllowOrigin []string
+}
+
+// RouterConfig is the configuration of the router itself, separate from
+// the service bundles it routes to.
+type RouterConfig struct {
+	// Listen addresses. An empty BindHTTPS, the default, disables the HTTPS
+	// listener; set it once an application configures TLS certificates.
+	BindRPC   string
+	BindHTTP  string
+	BindHTTPS string
+
+	// BindAdmin serves the router introspection web page. It is disabled
+	// if empty and should not be reachable from untrusted networks.
+	BindAdmin string
+
+	ReadTimeout       Duration
+	ReadHeaderTimeout Duration
+	WriteTimeout      Duration
+	IdleTimeout       Duration
+
+	// ShutdownTimeout is how long open requests are given to finish
+	// when the router is stopped.
+	ShutdownTimeout Duration
+
+	// MaxBodySize is the default request body limit in bytes.
+	MaxBodySize int64
+
+	// App settings, keyed by host.
+	App map[string]AppConfig
+
+	// TrustedProxy is a list of CIDR ranges, such as "10.0.0.0/8".
+	// Requests from these addresses may set the client address and
+	// scheme with the X-Forwarded-For and X-Forwarded-Proto headers.
+	TrustedProxy []string
+
+	// OverlayFile stores the operator bindings set with Update.
+	// It is only read when the router starts. If empty a file in the
+	// user configuration directory is used.
+	OverlayFile string
+
+	// TokenKeyFile stores the secret keys session cookie names are derived
+	// from. It is created if it does not exist and is only read when the
+	// router starts. If empty a file in the user configuration directory
+	// is used.
+	TokenKeyFile string
+
+	// TokenKeyGrace is how long cookies named with the previous token key
+	// are accepted after the key is rotated.
+	TokenKeyGrace Duration
+
+	// AuthCacheTTL is how long the authentication of a session is cached.
+	// Cached sessions are dropped when the authenticator reports they
+	// ended or changed. Zero disables the cache.
+	AuthCacheTTL Duration
+
+	// AuthCacheSize is the most sessions cached.
+	AuthCacheSize int
+
+	trusted []*net.IPNet
+}
+
+func defaultRouterConfig() *RouterConfig {
+	return &RouterConfig{
+		BindRPC:  ":9301",
+		BindHTTP: ":8301",
+
+		ReadTimeout:       Duration(time.Minute * 5),
+		ReadHeaderTimeout: Duration(time.Second * 10),
+		IdleTimeout:       Duration(time.Minute * 2),
+		ShutdownTimeout:   Duration(time.Second * 30),
+
+		MaxBodySize: 1024 * 1024 * 100, // 100 MB.
+
+		TokenKeyGrace: Duration(28 * 24 * time.Hour),
+
+		AuthCacheTTL:  Duration(10 * time.Second),
+		AuthCacheSize: 10000,
+	}
+}
+
+// routerFlags are set on the command line and take precedence over
+// the configuration file.
+type routerFlags struct {
+	config    string
+	bindRPC   string
+	bindHTTP  string
+	bindHTTPS string
+	bindAdmin string
+}
+
+// apply the flags set on the command line to c.
+func (f *routerFlags) apply(c *RouterConfig) {
+	if len(f.bindRPC) > 0 {
+		c.BindRPC = f.bindRPC
+	}
+	if len(f.bindHTTP) > 0 {
+		c.BindHTTP = f.bindHTTP
+	}
+	if len(f.bindHTTPS) > 0 {
+		c.BindHTTPS = f.bindHTTPS
+	}
+	if len(f.bindAdmin) > 0 {
+		c.BindAdmin = f.bindAdmin
+	}
+}
+
+// init validates the configuration and parses the trusted proxy ranges.
+func (c *RouterConfig) init() error {
+	if len(c.BindRPC) == 0 {
+		return fmt.Errorf("missing BindRPC")
+	}
+	if len(c.BindHTTP) == 0 {
+		return fmt.Errorf("missing BindHTTP")
+	}
+	if c.MaxBodySize < 0 {
+		return fmt.Errorf("MaxBodySize must not be negative")
+	}
+	if c.TokenKeyGrace < 0 {
+		return fmt.Errorf("TokenKeyGrace must not be negative")
+	}
+	if c.AuthCacheTTL < 0 {
+		return fmt.Errorf("AuthCacheTTL must not be negative")
+	}
+	if c.AuthCacheSize < 0 {
+		return fmt.Errorf("AuthCacheSize must not be negative")
+	}
+	for host, ac := range c.App {
+		if ac.MaxBodySize < 0 {
+			return fmt.Errorf("app %q MaxBodySize must not be negative", host)
+		}
+		for _, origin := range ac.AllowOrigin {
+			u, err := url.Parse(origin)
+			if err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 || origin != u.Scheme+"://"+u.Host {
+				return fmt.Errorf("app %q AllowOrigin %q must be in the form scheme://host[:port]", host, origin)
+			}
+		}
+	}
+	c.trusted = make([]*net.IPNet, 0, len(c.TrustedProxy))
+	for _, cidr := range c.TrustedProxy {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy: %v", err)
+		}
+		c.trusted = append(c.trusted, n)
+	}
+	return nil
+}
+
+// app returns the settings for host, which may be configured without a port.
+func (c *RouterConfig) app(host string) AppConfig {
+	ac, found := c.App[host]
+	if !found {
+		ac = c.App[hostName(host)]
+	}
+	return ac
+}
+
+// maxBodySize returns the request body limit for host.
+func (c *RouterConfig) maxBodySize(host string) int64 {
+	if ac := c.app(host); ac.MaxBodySize > 0 {
+		return ac.MaxBodySize
+	}
+	return c.MaxBodySize
+}
+
+func (c *RouterConfig) isTrusted(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, n := range c.trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// client returns the address of the client and whether the client
+// connected with TLS. Forwarding headers are only used when the request
+// came from a trusted proxy.
+func (c *RouterConfig) client(r *http.Request) (remoteAddr string, secure bool) {
+	remoteAddr = r.RemoteAddr
+	secure = r.TLS != nil
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !c.isTrusted(net.ParseIP(host)) {
+		return remoteAddr, secure
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); len(proto) > 0 {
+		secure = strings.EqualFold(strings.TrimSpace(proto), "https")
+	}
+
+	// The right most address not from a trusted proxy is the client.
+	var forwarded []string
+	for _, v := range r.Header["X-Forwarded-For"] {
+		forwarded = append(forwarded, strings.Split(v, ",")...)
+	}
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(forwarded[i])
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			break
+		}
+		remoteAddr = addr
+		if !c.isTrusted(ip) {
+			break
+		}
+	}
+	return remoteAddr, secure
+}
+
+// configReader reads the router configuration file and reports when
+// files in its directory change.
+type configReader struct {
+	*service.ConfigReader
+}
+
+func newConfigReader(p string) (*configReader, error) {
+	cr, err := service.NewConfigReader(p)
+	if err != nil {
+		return nil, err
+	}
+	return &configReader{ConfigReader: cr}, nil
+}
+
+// open reads the configuration, starting from the default configuration.
+func (r *configReader) open(f *routerFlags) (*RouterConfig, error) {
+	c := defaultRouterConfig()
+	err := r.Decode(c)
+	if err != nil {
+		return nil, err
+	}
+	f.apply(c)
+	if err = c.init(); err != nil {
+		return nil, fmt.Errorf("config %q: %v", r.Path(), err)
+	}
+	return c, nil
+}
+
+// defaultConfigPath is used when no configuration file is given.
+// It is only read if it exists.
+const defaultConfigPath = "res/scdrouter.jsonnet"
+
+// loadConfig returns the initial router configuration and the reader used
+// to reload it. The reader is nil if there is no configuration file.
+func loadConfig(f *routerFlags) (*RouterConfig, *configReader, error) {
+	p := f.config
+	if len(p) == 0 {
+		if _, err := os.Stat(defaultConfigPath); err != nil {
+			c := defaultRouterConfig()
+			f.apply(c)
+			return c, nil, c.init()
+		}
+		p = defaultConfigPath
+	}
+	cr, err := newConfigReader(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	c, err := cr.open(f)
+	if err != nil {
+		cr.Close()
+		return nil, nil, err
+	}
+	return c, cr, nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConfigErrorKind classifies a ConfigError.
+type ConfigErrorKind int
+
+const (
+	ConfigInvalid ConfigErrorKind = iota
+	ConfigMissing
+	ConfigResourceConflict
+	ConfigHostConflict
+	ConfigURLConflict
+	ConfigListen
+)
+
+func (k ConfigErrorKind) String() string {
+	switch k {
+	default:
+		return fmt.Sprintf("ConfigErrorKind(%d)", int(k))
+	case ConfigInvalid:
+		return "invalid"
+	case ConfigMissing:
+		return "missing"
+	case ConfigResourceConflict:
+		return "resource conflict"
+	case ConfigHostConflict:
+		return "host conflict"
+	case ConfigURLConflict:
+		return "url conflict"
+	case ConfigListen:
+		return "listen"
+	}
+}
+
+// ConfigError is a problem found while composing a RouterRun.
+// The components named in the error are disabled in that RouterRun, while
+// the rest of the configuration is still loaded.
+type ConfigError struct {
+	Kind ConfigErrorKind
+
+	// Service names that provided the components in error.
+	Service []string
+
+	// Host, Resource, and URL pattern in error, if any.
+	Host     string
+	Resource []string
+	URL      string
+
+	// Bind is the operator binding in error, if any.
+	Bind string
+
+	Message string
+}
+
+func (e ConfigError) Error() string {
+	msg := fmt.Sprintf("%v: %s", e.Kind, e.Message)
+	if len(e.Service) > 0 {
+		msg += fmt.Sprintf(" (services %s)", strings.Join(e.Service, ", "))
+	}
+	if len(e.Bind) > 0 {
+		msg += fmt.Sprintf(" (bind %q)", e.Bind)
+	}
+	return msg
+}
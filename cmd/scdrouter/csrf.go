@@ -0,0 +1,49 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// unsafeMethod reports if an HTTP method may change state.
+func unsafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return false
+	}
+	return true
+}
+
+// checkOrigin verifies a state changing request came from a page of the
+// application. Browsers send the Origin header on cross-origin and most
+// same-origin requests; the Referer is used when it is absent. Requests with
+// neither are from non-browser clients or are stopped by the SameSite
+// session cookie.
+func (c *RouterConfig) checkOrigin(r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if len(origin) == 0 {
+		origin = r.Header.Get("Referer")
+	}
+	if len(origin) == 0 {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 {
+		return fmt.Errorf("invalid origin %q", origin)
+	}
+	if u.Host == r.Host {
+		return nil
+	}
+	from := u.Scheme + "://" + u.Host
+	for _, allow := range c.app(r.Host).AllowOrigin {
+		if from == allow {
+			return nil
+		}
+	}
+	return fmt.Errorf("origin %q not allowed", from)
+}
@@ -5,26 +5,167 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
 	"encoding/base64"
-	"hash"
-	"strings"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/solidcoredata/scd/api"
+
+	"github.com/golang/protobuf/ptypes"
 	"github.com/minio/blake2b-simd"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 )
 
-var tokenKeyHMAC = []byte(`solidcoredata`)
-var tokenKeyHasher hash.Hash
+// tokenKeySize is the size of a generated token key in bytes.
+const tokenKeySize = 32
+
+// TokenKeys are the secret keys session cookie names are derived from.
+// Routers that serve the same hosts must share the keys. A value is not
+// modified after it is created; rotate returns new keys.
+type TokenKeys struct {
+	Key []byte
+
+	// Previous is the key before the last rotation. Cookies named with it
+	// are accepted until PreviousUntil.
+	Previous      []byte    `json:",omitempty"`
+	PreviousUntil time.Time `json:",omitempty"`
+}
+
+func newTokenKeys() (*TokenKeys, error) {
+	key := make([]byte, tokenKeySize)
+	_, err := rand.Read(key)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenKeys{Key: key}, nil
+}
+
+// rotate returns new keys with the current key accepted for grace.
+func (tk *TokenKeys) rotate(grace time.Duration, now time.Time) (*TokenKeys, error) {
+	next, err := newTokenKeys()
+	if err != nil {
+		return nil, err
+	}
+	if grace > 0 {
+		next.Previous = tk.Key
+		next.PreviousUntil = now.Add(grace)
+	}
+	return next, nil
+}
+
+// names returns the cookie name of the host and, during the grace period,
+// the cookie name derived from the previous key.
+func (tk *TokenKeys) names(host string, now time.Time) (name, previous string) {
+	name = tokenKeyName(tk.Key, host)
+	if len(tk.Previous) > 0 && now.Before(tk.PreviousUntil) {
+		previous = tokenKeyName(tk.Previous, host)
+	}
+	return name, previous
+}
 
-func init() {
+// tokenKeyName derives the cookie name of the host from key.
+// A hash is created for each call so it is safe for concurrent use.
+func tokenKeyName(key []byte, host string) string {
 	h, err := blake2b.New(&blake2b.Config{
-		Size: 4,
-		Key:  tokenKeyHMAC,
+		Size: 8,
+		Key:  key,
 	})
 	if err != nil {
-		panic("unable to create token key hasher")
+		panic("unable to create token key hasher: " + err.Error())
+	}
+	h.Write([]byte(host))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// defaultTokenKeyFile returns the file token keys are stored in if not configured.
+func defaultTokenKeyFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "scdrouter", "tokenkey.json")
+}
+
+// loadTokenKeys reads the keys from fn. If the file does not exist a new
+// key is generated and saved.
+func loadTokenKeys(fn string) (*TokenKeys, error) {
+	if len(fn) == 0 {
+		return newTokenKeys()
+	}
+	b, err := ioutil.ReadFile(fn)
+	if os.IsNotExist(err) {
+		tk, err := newTokenKeys()
+		if err != nil {
+			return nil, err
+		}
+		return tk, saveTokenKeys(fn, tk)
+	}
+	if err != nil {
+		return nil, err
+	}
+	tk := &TokenKeys{}
+	err = json.Unmarshal(b, tk)
+	if err != nil {
+		return nil, fmt.Errorf("decode token keys %q: %v", fn, err)
+	}
+	if n := len(tk.Key); n < 16 || n > blake2b.KeySize {
+		return nil, fmt.Errorf("token key in %q must be 16 to %d bytes", fn, blake2b.KeySize)
+	}
+	if len(tk.Previous) > blake2b.KeySize {
+		return nil, fmt.Errorf("previous token key in %q must be at most %d bytes", fn, blake2b.KeySize)
+	}
+	return tk, nil
+}
+
+// saveTokenKeys writes the keys to fn.
+func saveTokenKeys(fn string, tk *TokenKeys) error {
+	if len(fn) == 0 {
+		return nil
+	}
+	b, err := json.MarshalIndent(tk, "", "\t")
+	if err != nil {
+		return err
 	}
-	tokenKeyHasher = h
+	return writeFileAtomic(fn, b)
 }
-func tokenKeyName(host string) string {
-	return strings.TrimRight(base64.RawURLEncoding.EncodeToString(tokenKeyHasher.Sum([]byte(host))), "=")
+
+// RotateTokenKey replaces the token key and composes a new router run with
+// the new cookie names. The previous key is accepted for the configured
+// TokenKeyGrace.
+func (s *RouterServer) RotateTokenKey(ctx context.Context, req *api.RotateTokenKeyReq) (*api.RotateTokenKeyResp, error) {
+	s.rlk.RLock()
+	grace := time.Duration(s.config.TokenKeyGrace)
+	s.rlk.RUnlock()
+
+	s.slk.Lock()
+	defer s.slk.Unlock()
+
+	next, err := s.tokenKeys.rotate(grace, time.Now())
+	if err != nil {
+		return nil, grpc.Errorf(codes.Internal, "unable to create token key: %v", err)
+	}
+	err = saveTokenKeys(s.tokenKeyFile, next)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Internal, "unable to save token keys: %v", err)
+	}
+	s.tokenKeys = next
+	s.updateCompleteSLocked()
+	log.Printf("router: token key rotated, previous key accepted until %v", next.PreviousUntil)
+
+	resp := &api.RotateTokenKeyResp{}
+	if !next.PreviousUntil.IsZero() {
+		resp.PreviousUntil, err = ptypes.TimestampProto(next.PreviousUntil)
+		if err != nil {
+			return nil, grpc.Errorf(codes.Internal, "%v", err)
+		}
+	}
+	return resp, nil
 }
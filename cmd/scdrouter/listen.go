@@ -0,0 +1,248 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/solidcoredata/scd/api"
+
+	"google.golang.org/grpc"
+)
+
+// runningServer is a server on a single listener.
+type runningServer struct {
+	name string
+	bind string
+
+	// key of the configuration the server was started with. The server is
+	// restarted when the key changes.
+	key string
+
+	// serve creates the server again if it must be restarted.
+	serve serveFunc
+
+	l        net.Listener
+	stopped  int32
+	shutdown func(ctx context.Context)
+}
+
+// stop closes the listener and finishes the open requests in the background.
+// Open requests are not limited in time as the router continues to run.
+func (rs *runningServer) stop() {
+	atomic.StoreInt32(&rs.stopped, 1)
+	rs.l.Close()
+	go rs.shutdown(context.Background())
+}
+
+// serverSet runs the RPC, HTTP, and HTTPS servers of the router.
+type serverSet struct {
+	s *RouterServer
+
+	lk     sync.Mutex
+	closed bool
+	rpc    *runningServer
+	http   *runningServer
+	https  *runningServer
+	admin  *runningServer
+
+	// errs from the last apply, reported in the router status.
+	errs []ConfigError
+}
+
+// apply starts, restarts, or stops servers to match c.
+// Servers whose settings did not change are left running.
+func (ss *serverSet) apply(c *RouterConfig) error {
+	ss.lk.Lock()
+	defer ss.lk.Unlock()
+
+	if ss.closed {
+		return nil
+	}
+	timeouts := fmt.Sprintf("%v %v %v %v", c.ReadTimeout, c.ReadHeaderTimeout, c.WriteTimeout, c.IdleTimeout)
+	ss.errs = nil
+	var msgs []string
+	var err error
+	check := func(err error) {
+		if err == nil {
+			return
+		}
+		ss.errs = append(ss.errs, ConfigError{
+			Kind:    ConfigListen,
+			Message: err.Error(),
+		})
+		msgs = append(msgs, err.Error())
+	}
+	ss.rpc, err = ss.restart(ss.rpc, "RPC", c.BindRPC, c.BindRPC, ss.serveRPC)
+	check(err)
+	ss.http, err = ss.restart(ss.http, "HTTP", c.BindHTTP, c.BindHTTP+" "+timeouts, func() (func(l net.Listener) error, func(context.Context)) {
+		return ss.serveHTTP(c, ss.s.tls.HTTPHandler(ss.s), nil)
+	})
+	check(err)
+	ss.https, err = ss.restart(ss.https, "HTTPS", c.BindHTTPS, c.BindHTTPS+" "+timeouts, func() (func(l net.Listener) error, func(context.Context)) {
+		return ss.serveHTTP(c, ss.s, ss.s.tls.TLSConfig())
+	})
+	check(err)
+	ss.admin, err = ss.restart(ss.admin, "admin", c.BindAdmin, c.BindAdmin+" "+timeouts, func() (func(l net.Listener) error, func(context.Context)) {
+		return ss.serveHTTP(c, adminHandler{s: ss.s}, nil)
+	})
+	check(err)
+	if len(msgs) > 0 {
+		return errors.New(strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// status returns the errors from the last apply.
+func (ss *serverSet) status() []ConfigError {
+	if ss == nil {
+		return nil
+	}
+	ss.lk.Lock()
+	defer ss.lk.Unlock()
+
+	return append([]ConfigError(nil), ss.errs...)
+}
+
+// shutdown stops all servers from accepting new connections and waits
+// for open requests to finish. Requests still open when ctx is done are
+// closed.
+func (ss *serverSet) shutdown(ctx context.Context) {
+	ss.lk.Lock()
+	defer ss.lk.Unlock()
+
+	ss.closed = true
+	wg := &sync.WaitGroup{}
+	for _, rs := range []*runningServer{ss.rpc, ss.http, ss.https, ss.admin} {
+		if rs == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(rs *runningServer) {
+			defer wg.Done()
+			atomic.StoreInt32(&rs.stopped, 1)
+			rs.shutdown(ctx)
+		}(rs)
+	}
+	wg.Wait()
+	ss.rpc, ss.http, ss.https, ss.admin = nil, nil, nil, nil
+}
+
+// serveFunc creates a server, returning the functions to serve it and
+// to gracefully shut it down.
+type serveFunc func() (serve func(l net.Listener) error, shutdown func(context.Context))
+
+func (ss *serverSet) restart(rs *runningServer, name, bind, key string, f serveFunc) (*runningServer, error) {
+	if rs != nil && rs.key == key {
+		return rs, nil
+	}
+	if len(bind) == 0 {
+		if rs != nil {
+			log.Printf("router: stop %s on %q", rs.name, rs.bind)
+			rs.stop()
+		}
+		return nil, nil
+	}
+	// The new listener can only be opened before the old one is closed
+	// if the address changed. Otherwise the prior server is started again
+	// if the new one can not listen.
+	if rs != nil && rs.bind == bind {
+		rs.stop()
+		next, err := startServer(name, bind, key, f)
+		if err == nil {
+			return next, nil
+		}
+		prior, perr := startServer(rs.name, rs.bind, rs.key, rs.serve)
+		if perr != nil {
+			log.Printf("router: unable to restart prior %s on %q: %v", rs.name, rs.bind, perr)
+			return nil, err
+		}
+		return prior, err
+	}
+	next, err := startServer(name, bind, key, f)
+	if err != nil {
+		// Keep the prior server running.
+		return rs, err
+	}
+	if rs != nil {
+		rs.stop()
+	}
+	return next, nil
+}
+
+// startServer listens on bind and serves the server created by f.
+func startServer(name, bind, key string, f serveFunc) (*runningServer, error) {
+	l, err := net.Listen("tcp", bind)
+	if err != nil {
+		return nil, fmt.Errorf("unable to listen %s on %q: %v", name, bind, err)
+	}
+	serve, shutdown := f()
+	next := &runningServer{
+		name:     name,
+		bind:     bind,
+		key:      key,
+		serve:    f,
+		l:        l,
+		shutdown: shutdown,
+	}
+	go func() {
+		err := serve(l)
+		if err != nil && err != http.ErrServerClosed && atomic.LoadInt32(&next.stopped) == 0 {
+			log.Printf("router: failed to serve %s on %q: %v", name, bind, err)
+		}
+	}()
+	log.Printf("router: serve %s on %q", name, bind)
+	return next, nil
+}
+
+func (ss *serverSet) serveRPC() (func(l net.Listener) error, func(context.Context)) {
+	server := grpc.NewServer()
+	api.RegisterRouterConfigurationServer(server, ss.s)
+	return server.Serve, func(ctx context.Context) {
+		done := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			server.Stop()
+		}
+	}
+}
+
+func (ss *serverSet) serveHTTP(c *RouterConfig, h http.Handler, tc *tls.Config) (func(l net.Listener) error, func(context.Context)) {
+	server := &http.Server{
+		Handler:           h,
+		TLSConfig:         tc,
+		ReadTimeout:       time.Duration(c.ReadTimeout),
+		ReadHeaderTimeout: time.Duration(c.ReadHeaderTimeout),
+		WriteTimeout:      time.Duration(c.WriteTimeout),
+		IdleTimeout:       time.Duration(c.IdleTimeout),
+	}
+	serve := server.Serve
+	if tc != nil {
+		// Certificates are provided by TLSConfig.GetCertificate.
+		serve = func(l net.Listener) error {
+			return server.ServeTLS(l, "", "")
+		}
+	}
+	return serve, func(ctx context.Context) {
+		if err := server.Shutdown(ctx); err != nil {
+			server.Close()
+		}
+	}
+}
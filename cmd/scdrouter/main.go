@@ -12,20 +12,23 @@ import (
 	"encoding/base64"
 	"flag"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"log"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
 	"path"
+	"sort"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/solidcoredata/scd/api"
 
+	"github.com/golang/protobuf/ptypes"
 	google_protobuf1 "github.com/golang/protobuf/ptypes/empty"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -54,38 +57,127 @@ func onErrf(t byte, f string, v ...interface{}) {
 }
 
 func main() {
-	const (
-		bindRPC  = ":9301"
-		bindHTTP = ":8301"
-	)
-	ctx := context.TODO()
+	f := &routerFlags{}
+	flag.StringVar(&f.config, "config", "", "router configuration file, defaults to "+defaultConfigPath+" if present")
+	flag.StringVar(&f.bindRPC, "rpc", "", "address and port to bind the RPC server to")
+	flag.StringVar(&f.bindHTTP, "http", "", "address and port to bind the HTTP server to")
+	flag.StringVar(&f.bindHTTPS, "https", "", "address and port to bind the HTTPS server to")
+	flag.StringVar(&f.bindAdmin, "admin", "", "address and port to bind the admin web page to")
+	flag.Parse()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config, cr, err := loadConfig(f)
+	if err != nil {
+		onErrf(printDefaults, "unable to load configuration: %v", err)
+	}
 
 	s := NewRouterServer(ctx)
+	s.servers = &serverSet{s: s}
+	s.overlayFile = config.OverlayFile
+	if len(s.overlayFile) == 0 {
+		s.overlayFile = defaultOverlayFile()
+	}
+	s.overlay, err = loadOverlay(s.overlayFile)
+	if err != nil {
+		onErrf(printMessage, "unable to load bindings: %v", err)
+	}
+	s.tokenKeyFile = config.TokenKeyFile
+	if len(s.tokenKeyFile) == 0 {
+		s.tokenKeyFile = defaultTokenKeyFile()
+	}
+	s.tokenKeys, err = loadTokenKeys(s.tokenKeyFile)
+	if err != nil {
+		onErrf(printMessage, "unable to load token keys: %v", err)
+	}
+	err = s.updateConfig(config)
+	if err != nil {
+		onErrf(printMessage, "%v", err)
+	}
+	if cr != nil {
+		go s.runConfig(ctx, cr, f)
+	}
 
-	go s.startRPC(ctx, bindRPC)
-	go s.startHTTP(ctx, bindHTTP)
-	select {}
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	<-sig
+	go func() {
+		<-sig
+		onErr(printMessage, "router: forced stop")
+	}()
+	log.Println("router: shutting down")
+	s.shutdown()
+	log.Println("router: stopped")
 }
-func (s *RouterServer) startRPC(ctx context.Context, bindRPC string) {
-	server := grpc.NewServer()
-	api.RegisterRouterConfigurationServer(server, s)
 
-	l, err := net.Listen("tcp", bindRPC)
+// shutdown stops accepting new requests, waits for open requests to finish
+// up to the configured ShutdownTimeout, then removes the current router run
+// from the services.
+func (s *RouterServer) shutdown() {
+	s.rlk.RLock()
+	timeout := time.Duration(s.config.ShutdownTimeout)
+	s.rlk.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	s.servers.shutdown(ctx)
+
+	s.rlk.RLock()
+	rr := s.router
+	s.rlk.RUnlock()
+	if rr == nil {
+		return
+	}
+	err := rr.updateServices(ctx, api.ServiceConfigAction_Remove)
 	if err != nil {
-		onErrf(printMessage, `unable to listen on %q: %v`, bindRPC, err)
+		log.Printf("router: failed to remove router from services %v", err)
 	}
-	defer l.Close()
+}
 
-	err = server.Serve(l)
-	if err != nil {
-		onErrf(printMessage, `failed to serve RPC on %q: %v`, bindRPC, err)
+// runConfig reloads the router configuration when it changes.
+func (s *RouterServer) runConfig(ctx context.Context, cr *configReader, f *routerFlags) {
+	defer cr.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-cr.Changes:
+			config, err := cr.open(f)
+			if err != nil {
+				log.Printf("router: failed to read config: %v", err)
+				continue
+			}
+			err = s.updateConfig(config)
+			if err != nil {
+				log.Printf("router: %v", err)
+				continue
+			}
+			log.Println("router: router config updated")
+		}
 	}
 }
-func (s *RouterServer) startHTTP(ctx context.Context, bindHTTP string) {
-	err := http.ListenAndServe(bindHTTP, s)
+
+// updateConfig sets the router configuration and starts or restarts
+// the servers that changed.
+func (s *RouterServer) updateConfig(config *RouterConfig) error {
+	s.rlk.Lock()
+	s.config = config
+	s.rlk.Unlock()
+
+	s.authCache.configure(time.Duration(config.AuthCacheTTL), config.AuthCacheSize)
+
+	return s.servers.apply(config)
+}
+
+// portOf returns the port of a bind address.
+func portOf(bind string) string {
+	_, port, err := net.SplitHostPort(bind)
 	if err != nil {
-		onErrf(printMessage, `failed to listen and serve HTTP on %q: %v`, bindHTTP, err)
+		return ""
 	}
+	return port
 }
 
 func (s *RouterServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -97,7 +189,12 @@ func (s *RouterServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	version := s.router.Version
+	config := s.config
 	appToken, found := s.router.App[r.Host]
+	if !found {
+		// Hosts may be configured without a port.
+		appToken, found = s.router.App[hostName(r.Host)]
+	}
 	s.rlk.RUnlock()
 
 	if !found {
@@ -106,30 +203,75 @@ func (s *RouterServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	app := appToken.App
 
+	remoteAddr, secure := config.client(r)
+	httpsPort := portOf(config.BindHTTPS)
+
+	if !secure && app.TLS != nil && app.TLS.RedirectHTTP && len(httpsPort) > 0 {
+		host := hostName(r.Host)
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+		u := *r.URL
+		u.Scheme = "https"
+		u.Host = host
+		http.Redirect(w, r, u.String(), http.StatusPermanentRedirect)
+		return
+	}
+
 	token := ""
+	fromPrevious := false
 	if c, err := r.Cookie(appToken.TokenKey); err == nil {
 		token = c.Value
+	} else if appToken.acceptPrevious(time.Now()) {
+		if c, err := r.Cookie(appToken.PreviousTokenKey); err == nil {
+			token = c.Value
+			fromPrevious = true
+			// Services only look for the current cookie name.
+			r.AddCookie(&http.Cookie{Name: appToken.TokenKey, Value: token})
+		}
 	}
 
 	if app.Auth == nil {
 		http.Error(w, "auth not configured", http.StatusInternalServerError)
 		return
 	}
-	authResp, err := app.Auth.RequestAuth(r.Context(), &api.RequestAuthReq{
+	authResp, err := s.requestAuth(r.Context(), app, &api.RequestAuthReq{
 		Token:         token,
 		Configuration: app.AuthConfig,
+		RemoteAddr:    remoteAddr,
+		Secure:        secure,
+		Method:        r.Method,
+		URL:           r.URL.String(),
 	})
 	if err != nil {
 		http.Error(w, "auth: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 	authResp.TokenKey = appToken.TokenKey
+	if fromPrevious {
+		moveSessionCookie(w, appToken, token, authResp, secure)
+	}
 
 	lb, found := app.LoginBundle[authResp.LoginState]
 	if !found {
 		http.Error(w, "unconfigured login state: "+authResp.LoginState.String(), http.StatusInternalServerError)
 		return
 	}
+	elevated := authResp.Elevated(time.Now())
+	elevateBundle, canElevate := app.LoginBundle[api.LoginState_Elevate]
+	canElevate = canElevate && authResp.LoginState == api.LoginState_Granted
+	// A granted session re-authenticates in the elevate bundle. Once elevated
+	// the granted bundle consumes the redirect.
+	if canElevate && !elevated && strings.HasPrefix(r.URL.Path, elevateBundle.Prefix) {
+		lb = elevateBundle
+	}
+	if authResp.LoginState == api.LoginState_Granted && unsafeMethod(r.Method) {
+		if err := config.checkOrigin(r); err != nil {
+			log.Printf("router: %s %s%s: %v", r.Method, r.Host, r.URL.Path, err)
+			http.Error(w, "cross-origin request denied", http.StatusForbidden)
+			return
+		}
+	}
 	ctx := r.Context()
 
 	switch lb.ConsumeRedirect {
@@ -170,62 +312,86 @@ func (s *RouterServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	r.URL.Path = strings.TrimPrefix(r.URL.Path, lb.Prefix[:len(lb.Prefix)-1])
+	reqPath := strings.TrimPrefix(r.URL.Path, lb.Prefix[:len(lb.Prefix)-1])
 
-	ResURL, found := lb.URLRouter[r.URL.Path]
+	ResURL, found := lb.URLRouter.Match(reqPath)
 	if !found {
-		http.Error(w, fmt.Sprintf("path not found %q", r.URL.Path), http.StatusNotFound)
+		http.Error(w, fmt.Sprintf("path not found %q", reqPath), http.StatusNotFound)
 		return
 	}
-	cr := ResURL.Resource
-
-	const readLimit = 1024 * 1024 * 100 // 100 MB. In the future make this property part of the AppHandler interface or RouteHandler.
-	body, err := ioutil.ReadAll(io.LimitReader(r.Body, readLimit))
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if !ResURL.allowed(authResp.Roles) {
+		http.Error(w, "permission denied", httpStatusFromCode(codes.PermissionDenied))
 		return
 	}
-
-	var tls *api.TLSState
-	if r.TLS != nil {
-		tls = &api.TLSState{
-			Version:           uint32(r.TLS.Version),
-			HandshakeComplete: r.TLS.HandshakeComplete,
-			DidResume:         r.TLS.DidResume,
-			CipherSuite:       uint32(r.TLS.CipherSuite),
-			ServerName:        r.TLS.ServerName,
+	if ResURL.Elevate && !elevated {
+		if !canElevate || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+			http.Error(w, "elevated session required", http.StatusForbidden)
+			return
 		}
+		redirectQuery := url.Values{}
+		redirectQuery.Set(redirectQueryKey, r.URL.RequestURI())
+		nextURL := &url.URL{Path: elevateBundle.Prefix, RawQuery: redirectQuery.Encode()}
+		http.Redirect(w, r, nextURL.String(), http.StatusTemporaryRedirect)
+		return
 	}
+	cr := ResURL.Resource
 
 	appReq := &api.HTTPRequest{
 		Version: version,
 		Method:  r.Method,
 		URL: &api.URL{
-			Host:  cr.ParentRes.Service.sb.Name,
-			Path:  cr.ParentRes.Name[len(cr.ParentRes.Service.sb.Name)+1:],
-			Query: api.NewKeyValueList(r.URL.Query()),
+			Host:      cr.ParentRes.Service.sb.Name,
+			Path:      cr.ParentRes.Name[len(cr.ParentRes.Service.sb.Name)+1:],
+			Query:     api.NewKeyValueList(r.URL.Query()),
+			Remainder: ResURL.Remainder,
+			Param:     ResURL.Param,
 		},
 		ProtoMajor:  int32(r.ProtoMajor),
 		ProtoMinor:  int32(r.ProtoMinor),
-		Body:        body,
 		Header:      api.NewKeyValueList(r.Header),
 		ContentType: r.Header.Get("Content-Type"),
 		Host:        r.Host,
-		RemoteAddr:  r.RemoteAddr,
-		TLS:         tls,
+		RemoteAddr:  remoteAddr,
+		Secure:      secure,
+		TLS:         newTLSState(r.TLS),
 		Auth:        authResp,
+		AuthConfig:  app.AuthConfig,
 		Config:      ResURL.Configuration,
 	}
 
-	appResp, err := cr.ParentRes.Handler.ServeHTTP(ctx, appReq)
+	body := &requestBody{r: r.Body}
+	if limit := config.maxBodySize(r.Host); limit > 0 {
+		body.r = http.MaxBytesReader(w, r.Body, limit)
+		body.limit = limit
+	}
+
+	var appResp *api.HTTPResponse
+	var appStream *httpStream
+	switch ResURL.Configuration.Stream {
+	case true:
+		appResp, appStream, err = openHTTPStream(ctx, cr.ParentRes.Handler, appReq, body)
+		if appStream != nil {
+			// Stop streaming the request body when ServeHTTP returns.
+			defer appStream.close()
+		}
+	case false:
+		appReq.Body, err = ioutil.ReadAll(body)
+		if err == nil {
+			appResp, err = cr.ParentRes.Handler.ServeHTTP(ctx, appReq)
+		}
+	}
+	// The body is no longer read once an error is returned.
+	if berr := body.readErr(); err != nil && berr != nil {
+		status := http.StatusBadRequest
+		if body.tooLarge() {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, berr.Error(), status)
+		return
+	}
 	if err != nil {
 		if code := grpc.Code(err); code != codes.Unknown {
-			status := http.StatusInternalServerError
-			switch code {
-			case codes.PermissionDenied:
-				status = http.StatusForbidden
-			}
-			http.Error(w, grpc.ErrorDesc(err), status)
+			http.Error(w, grpc.ErrorDesc(err), httpStatusFromCode(code))
 			return
 		}
 		lb, found := app.LoginBundle[api.LoginState_Error]
@@ -233,7 +399,7 @@ func (s *RouterServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-		cr, found := lb.URLRouter["/"]
+		cr, found := lb.URLRouter.Match("/")
 		if !found {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -245,6 +411,10 @@ func (s *RouterServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "unable to render error page: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
+		// The error page is still an error unless it sets a status.
+		if appResp.Status == 0 {
+			appResp.Status = http.StatusInternalServerError
+		}
 	}
 	if len(appResp.ContentType) > 0 {
 		w.Header().Set("Content-Type", appResp.ContentType)
@@ -260,9 +430,27 @@ func (s *RouterServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 	}
-	w.WriteHeader(http.StatusOK)
+	status := int(appResp.Status)
+	if len(appResp.Redirect) > 0 {
+		if status == 0 {
+			status = http.StatusFound
+		}
+		http.Redirect(w, r, appResp.Redirect, status)
+		return
+	}
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if status < 100 || status > 999 {
+		http.Error(w, fmt.Sprintf("invalid response status %d", status), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(status)
 	w.Write(appResp.Body)
 
+	if appStream != nil {
+		copyHTTPStream(w, appStream)
+	}
 }
 
 var _ api.RouterConfigurationServer = &RouterServer{}
@@ -273,14 +461,34 @@ type serviceDef struct {
 	sb             *api.ServiceBundle
 }
 
+// serviceKey identifies a registered service. Services with the same name
+// at different addresses are both kept and reported as a conflict.
+type serviceKey struct {
+	name    string
+	address string
+}
+
 type RouterServer struct {
 	ctx context.Context
 
 	slk      sync.Mutex
-	services map[string]serviceDef
+	services map[serviceKey]serviceDef
 
 	rlk    sync.RWMutex
 	router *RouterRun
+	config *RouterConfig
+
+	// overlay of operator bindings, guarded by slk.
+	overlay     map[string]Binding
+	overlayFile string
+
+	// tokenKeys of session cookie names, guarded by slk.
+	tokenKeys    *TokenKeys
+	tokenKeyFile string
+
+	tls       *tlsManager
+	servers   *serverSet
+	authCache *authCache
 
 	updateRouter chan *RouterRun
 }
@@ -288,8 +496,11 @@ type RouterServer struct {
 func NewRouterServer(ctx context.Context) *RouterServer {
 	s := &RouterServer{
 		ctx:          ctx,
-		services:     make(map[string]serviceDef, 30),
+		services:     make(map[serviceKey]serviceDef, 30),
 		updateRouter: make(chan *RouterRun, 6),
+		overlay:      make(map[string]Binding),
+		tls:          newTLSManager(certCacheDir()),
+		authCache:    newAuthCache(),
 	}
 	go s.runUpdateRouter(ctx)
 
@@ -302,9 +513,15 @@ func (s *RouterServer) runUpdateRouter(ctx context.Context) {
 			return
 		case rr := <-s.updateRouter:
 			rr.resolveNames()
+			certs := s.tls.resolve(rr)
 			if len(rr.Errors) > 0 {
-				log.Printf("router: configuration errors:\n\t%s\n", strings.Join(rr.Errors, "\n\t"))
-				continue
+				// Components in error are disabled, the rest of the
+				// configuration is still applied.
+				msg := make([]string, len(rr.Errors))
+				for i, e := range rr.Errors {
+					msg[i] = e.Error()
+				}
+				log.Printf("router: configuration errors:\n\t%s\n", strings.Join(msg, "\n\t"))
 			}
 			// Version routes. Assign each new RouterRun a UUID.
 			// attach version to all requests.
@@ -317,6 +534,7 @@ func (s *RouterServer) runUpdateRouter(ctx context.Context) {
 			err := rr.updateServices(ctx, api.ServiceConfigAction_Add)
 			if err != nil {
 				log.Printf("router: failed to add new router %v", err)
+				close(rr.applied)
 				continue
 			}
 
@@ -324,6 +542,8 @@ func (s *RouterServer) runUpdateRouter(ctx context.Context) {
 			old := s.router
 			s.router = rr
 			s.rlk.Unlock()
+			s.tls.update(certs)
+			close(rr.applied)
 
 			if old != nil {
 				err = old.updateServices(ctx, api.ServiceConfigAction_Remove)
@@ -352,9 +572,11 @@ func (s *RouterServer) updateServiceAddress(serviceAddress string) {
 		log.Printf("failed to receive from %q %v", serviceAddress, err)
 		return
 	}
-	defer s.removeService(sb.Name)
+	defer s.removeService(sb.Name, serviceAddress)
 	s.updateService(serviceAddress, conn, sb)
 
+	go s.watchInvalidations(s.ctx, sb.Name, conn)
+
 	go func() {
 		for {
 			sb, err := sbc.Recv()
@@ -368,21 +590,55 @@ func (s *RouterServer) updateServiceAddress(serviceAddress string) {
 	conn.WaitForStateChange(s.ctx, grpc.Ready)
 }
 
-func (s *RouterServer) removeService(serviceName string) {
-	fmt.Printf("remove %q\n", serviceName)
+// removeService removes the service at serviceAddress.
+// A new instance of the service may have been started at a different
+// address before the old instance is removed.
+func (s *RouterServer) removeService(serviceName, serviceAddress string) {
 	s.slk.Lock()
 	defer s.slk.Unlock()
 
-	delete(s.services, serviceName)
+	key := serviceKey{name: serviceName, address: serviceAddress}
+	if _, found := s.services[key]; !found {
+		return
+	}
+	fmt.Printf("remove %q\n", serviceName)
+	delete(s.services, key)
 	s.updateCompleteSLocked()
 }
 
+// removeServiceAddress removes all services at serviceAddress.
+// The returned RouterRun is nil if no service was removed.
+func (s *RouterServer) removeServiceAddress(serviceAddress string) *RouterRun {
+	s.slk.Lock()
+	defer s.slk.Unlock()
+
+	removed := false
+	for key := range s.services {
+		if key.address != serviceAddress {
+			continue
+		}
+		fmt.Printf("remove %q\n", key.name)
+		delete(s.services, key)
+		removed = true
+	}
+	if !removed {
+		return nil
+	}
+	return s.updateCompleteSLocked()
+}
+
 func (s *RouterServer) updateService(serviceAddress string, conn *grpc.ClientConn, sb *api.ServiceBundle) {
 	fmt.Printf("update %q\n", sb.Name)
 	s.slk.Lock()
 	defer s.slk.Unlock()
 
-	s.services[sb.Name] = serviceDef{
+	// A service that changes its name is no longer under the old name.
+	for key := range s.services {
+		if key.address == serviceAddress && key.name != sb.Name {
+			delete(s.services, key)
+		}
+	}
+	s.services[serviceKey{name: sb.Name, address: serviceAddress}] = serviceDef{
 		serviceAddress: serviceAddress,
 		conn:           conn,
 		sb:             sb,
@@ -402,6 +658,7 @@ func NewRouterRun() *RouterRun {
 	rr := &RouterRun{
 		Resource: make(map[string]*Res),
 		App:      make(map[string]AppToken),
+		applied:  make(chan struct{}),
 	}
 
 	// Create a unique version of this router run.
@@ -626,6 +883,38 @@ func (rr *RouterRun) updateServices(ctx context.Context, action api.ServiceConfi
 type AppToken struct {
 	TokenKey string
 	App      *App
+
+	// PreviousTokenKey is the cookie name derived from the previous
+	// token key, accepted until PreviousUntil.
+	PreviousTokenKey string
+	PreviousUntil    time.Time
+}
+
+// moveSessionCookie sets the session cookie again under the current cookie
+// name and removes the cookie under the previous name. The new cookie
+// expires with the session, so sessions of an unknown lifetime are not moved.
+func moveSessionCookie(w http.ResponseWriter, appToken AppToken, token string, authResp *api.RequestAuthResp, secure bool) {
+	if authResp.LoginState == api.LoginState_None || authResp.ValidUntil == nil {
+		return
+	}
+	until, err := ptypes.Timestamp(authResp.ValidUntil)
+	if err != nil {
+		return
+	}
+	lifetime := time.Until(until)
+	if lifetime <= 0 {
+		return
+	}
+	req := &api.HTTPRequest{Auth: authResp, Secure: secure}
+	http.SetCookie(w, req.SessionCookie(token, lifetime))
+	old := req.SessionCookie("", -1)
+	old.Name = appToken.PreviousTokenKey
+	http.SetCookie(w, old)
+}
+
+// acceptPrevious reports if the previous cookie name is still accepted.
+func (at AppToken) acceptPrevious(now time.Time) bool {
+	return len(at.PreviousTokenKey) > 0 && now.Before(at.PreviousUntil)
 }
 
 type RouterRun struct {
@@ -633,7 +922,10 @@ type RouterRun struct {
 	Resource map[string]*Res
 	App      map[string]AppToken
 
-	Errors []string
+	Errors []ConfigError
+
+	// applied is closed once the router run is in use or failed to apply.
+	applied chan struct{}
 }
 
 type Res struct {
@@ -643,6 +935,8 @@ type Res struct {
 	Consume       api.ResourceType
 	Configuration []byte
 	Include       []string
+	Role          []string
+	Elevate       bool
 
 	ParentRes     *Res
 	IncludeRes    []*Res
@@ -659,38 +953,113 @@ type LoginBundle struct {
 	BundleName      string
 	Bundle          *Res
 
-	// TODO(kardianos): this should use some type of prefix tree to handle
-	// folder paths.
-	URLRouter map[string]URL
+	URLRouter *URLTree
 }
 
 type URL struct {
 	Configuration *api.ConfigureURL
 	Resource      *Res
+
+	// Role lists sets of role IDs. The user must have one role of each set.
+	Role [][]int64
+
+	// Elevate is true if the resource or a parent requires an elevated session.
+	Elevate bool
+}
+
+// allowed reports if a user with roles may use the URL.
+func (u URL) allowed(roles []int64) bool {
+	for _, set := range u.Role {
+		found := false
+		for _, id := range set {
+			for _, has := range roles {
+				if id == has {
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
 }
 
 type App struct {
 	Host        []string
+	Service     string
+	Bind        string // Operator binding that set Host, if any.
 	AuthName    string
+	AuthService string // Name of the authenticator service.
 	LoginBundle map[api.LoginState]*LoginBundle
 	Auth        api.AuthClient
 	AuthConfig  *api.ConfigureAuth
-}
+	TLS         *api.ApplicationTLS
 
-func (rr *RouterRun) AddError(f string, v ...interface{}) {
-	rr.Errors = append(rr.Errors, fmt.Sprintf(f, v...))
+	// Role maps role names to IDs from the catalog of the authenticator.
+	Role map[string]int64
 }
 
-func (rr *RouterRun) resolveNames() {
-	rr.Errors = rr.Errors[:0] // Clear errors first.
+// requiresElevate reports if r or a parent requires an elevated session.
+func requiresElevate(r *Res) bool {
+	for ; r != nil; r = r.ParentRes {
+		if r.Elevate {
+			return true
+		}
+	}
+	return false
+}
 
-	for _, r := range rr.Resource {
-		if len(r.Parent) > 0 {
-			fr, found := rr.Resource[r.Parent]
+// requiredRoles returns the role IDs required by r and its parents.
+func (a *App) requiredRoles(r *Res) ([][]int64, error) {
+	var list [][]int64
+	for ; r != nil; r = r.ParentRes {
+		if len(r.Role) == 0 {
+			continue
+		}
+		set := make([]int64, 0, len(r.Role))
+		for _, name := range r.Role {
+			id, found := a.Role[name]
 			if !found {
-				rr.AddError("missing potential resource %q required by %q", r.Parent, r.Name)
+				return nil, fmt.Errorf("unknown role %q required by %q", name, r.Name)
+			}
+			set = append(set, id)
+		}
+		list = append(list, set)
+	}
+	return list, nil
+}
+
+func (rr *RouterRun) AddError(e ConfigError) {
+	rr.Errors = append(rr.Errors, e)
+}
+
+func (rr *RouterRun) resolveNames() {
+	// Resolve parents first. A resource with a missing parent is disabled,
+	// which may in turn disable other resources that use it as a parent.
+	for changed := true; changed; {
+		changed = false
+		for name, r := range rr.Resource {
+			if len(r.Parent) == 0 {
+				continue
+			}
+			if _, found := rr.Resource[r.Parent]; found {
 				continue
 			}
+			rr.AddError(ConfigError{
+				Kind:     ConfigMissing,
+				Service:  []string{r.ServiceBundle.Name},
+				Resource: []string{r.Name, r.Parent},
+				Message:  fmt.Sprintf("missing potential resource %q required by %q", r.Parent, r.Name),
+			})
+			delete(rr.Resource, name)
+			changed = true
+		}
+	}
+	for _, r := range rr.Resource {
+		if len(r.Parent) > 0 {
+			fr := rr.Resource[r.Parent]
 			r.ParentRes = fr
 			if r.Type == api.ResourceNone {
 				r.Type = fr.Type
@@ -699,69 +1068,245 @@ func (rr *RouterRun) resolveNames() {
 		for _, iname := range r.Include {
 			fr, found := rr.Resource[iname]
 			if !found {
-				rr.AddError("missing configured resource %q required by %q", iname, r.Name)
+				rr.AddError(ConfigError{
+					Kind:     ConfigMissing,
+					Service:  []string{r.ServiceBundle.Name},
+					Resource: []string{r.Name, iname},
+					Message:  fmt.Sprintf("missing configured resource %q required by %q", iname, r.Name),
+				})
 				continue
 			}
 			r.IncludeRes = append(r.IncludeRes, fr)
 		}
 	}
+
+	disabledApps := map[*App]bool{}
+	catalogs := map[*api.ServiceBundle]map[string]int64{}
 	for _, at := range rr.App {
 		a := at.App
+		if disabledApps[a] {
+			continue
+		}
 		if len(a.AuthName) == 0 {
-			rr.AddError("app on %q missing authentication", a.Host)
-		} else {
-			if fr, found := rr.Resource[a.AuthName]; found && fr.ParentRes != nil && fr.ParentRes.Service != nil && fr.Type == api.ResourceAuth {
-				a.Auth = api.NewAuthClient(fr.ParentRes.Service.conn)
-				ac := &api.ConfigureAuth{}
-				err := ac.Decode(fr.Configuration)
-				if err != nil {
-					rr.AddError("invalid configuration for %q %v", fr.Name, err)
-				} else {
-					a.AuthConfig = ac
-				}
-			} else {
-				rr.AddError("app on %q unable to resolve authenticator %q", a.Host, a.AuthName)
-			}
+			rr.AddError(ConfigError{
+				Kind:    ConfigMissing,
+				Service: []string{a.Service},
+				Message: fmt.Sprintf("app on %q missing authentication", a.Host),
+			})
+			disabledApps[a] = true
+			continue
+		}
+		fr, found := rr.Resource[a.AuthName]
+		if !found || fr.ParentRes == nil || fr.ParentRes.Service == nil || fr.Type != api.ResourceAuth {
+			rr.AddError(ConfigError{
+				Kind:     ConfigMissing,
+				Service:  []string{a.Service},
+				Resource: []string{a.AuthName},
+				Message:  fmt.Sprintf("app on %q unable to resolve authenticator %q", a.Host, a.AuthName),
+			})
+			disabledApps[a] = true
+			continue
+		}
+		ac := &api.ConfigureAuth{}
+		err := ac.Decode(fr.Configuration)
+		if err != nil {
+			rr.AddError(ConfigError{
+				Kind:     ConfigInvalid,
+				Service:  []string{fr.ServiceBundle.Name},
+				Resource: []string{fr.Name},
+				Message:  fmt.Sprintf("invalid configuration for %q %v", fr.Name, err),
+			})
+			disabledApps[a] = true
+			continue
+		}
+		authBundle := fr.ParentRes.ServiceBundle
+		a.Auth = api.NewAuthClient(fr.ParentRes.Service.conn)
+		a.AuthService = authBundle.Name
+		a.AuthConfig = ac
+
+		if _, found := catalogs[authBundle]; !found {
+			catalogs[authBundle] = rr.roleCatalog(authBundle)
 		}
-		for _, lb := range a.LoginBundle {
+		a.Role = catalogs[authBundle]
+
+		for ls, lb := range a.LoginBundle {
 			r, found := rr.Resource[lb.BundleName]
 			if !found {
-				rr.AddError("missing bundle %q for app on %q for state %v", lb.BundleName, a.Host, lb.LoginState)
+				rr.AddError(ConfigError{
+					Kind:     ConfigMissing,
+					Service:  []string{a.Service},
+					Resource: []string{lb.BundleName},
+					Message:  fmt.Sprintf("missing bundle %q for app on %q for state %v", lb.BundleName, a.Host, lb.LoginState),
+				})
+				delete(a.LoginBundle, ls)
 				continue
 			}
 			lb.Bundle = r
-			// TODO(kardianos): process URL resources and create a per LoginBundle URL tree.
+			rr.resolveURLs(a, lb)
 			// TODO(kardianos): process SPA resources and create a per LoginBundle SPA lookup.
-			for _, ir := range r.IncludeRes {
-				switch ir.Type {
-				case api.ResourceURL:
-					rc := &api.ConfigureURL{}
-					err := rc.Decode(ir.Configuration)
-					if err != nil {
-						rr.AddError("invalid configuration for %q %v", ir.Name, err)
-						continue
-					}
-					lb.URLRouter[rc.MapTo] = URL{
-						Resource:      ir,
-						Configuration: rc,
-					}
-				}
+		}
+	}
+	for h, at := range rr.App {
+		if disabledApps[at.App] {
+			delete(rr.App, h)
+		}
+	}
+}
+
+// roleCatalog returns the role names of sb mapped to role IDs.
+// A role name defined more than once is left out.
+func (rr *RouterRun) roleCatalog(sb *api.ServiceBundle) map[string]int64 {
+	catalog := make(map[string]int64, len(sb.Role))
+	dup := map[string]bool{}
+	for _, r := range sb.Role {
+		if _, found := catalog[r.Name]; found {
+			dup[r.Name] = true
+			continue
+		}
+		catalog[r.Name] = r.ID
+	}
+	for name := range dup {
+		rr.AddError(ConfigError{
+			Kind:    ConfigInvalid,
+			Service: []string{sb.Name},
+			Message: fmt.Sprintf("role %q defined more than once", name),
+		})
+		delete(catalog, name)
+	}
+	return catalog
+}
+
+// resolveURLs adds the URL resources of the login bundle to the bundle URL
+// router. If more than one resource maps to the same pattern, all of them
+// are left out of the router.
+func (rr *RouterRun) resolveURLs(a *App, lb *LoginBundle) {
+	seen := make(map[*Res]bool, len(lb.Bundle.IncludeRes))
+	mapTo := make(map[string][]URL, len(lb.Bundle.IncludeRes))
+	order := make([]string, 0, len(lb.Bundle.IncludeRes))
+	for _, ir := range lb.Bundle.IncludeRes {
+		if ir.Type != api.ResourceURL || seen[ir] {
+			continue
+		}
+		seen[ir] = true
+		rc := &api.ConfigureURL{}
+		err := rc.Decode(ir.Configuration)
+		if err != nil {
+			rr.AddError(ConfigError{
+				Kind:     ConfigInvalid,
+				Service:  []string{ir.ServiceBundle.Name},
+				Resource: []string{ir.Name},
+				Message:  fmt.Sprintf("invalid configuration for %q %v", ir.Name, err),
+			})
+			continue
+		}
+		role, err := a.requiredRoles(ir)
+		if err != nil {
+			rr.AddError(ConfigError{
+				Kind:     ConfigMissing,
+				Service:  []string{ir.ServiceBundle.Name},
+				Resource: []string{ir.Name},
+				Message:  fmt.Sprintf("%v for app on %q", err, a.Host),
+			})
+			continue
+		}
+		if _, found := mapTo[rc.MapTo]; !found {
+			order = append(order, rc.MapTo)
+		}
+		mapTo[rc.MapTo] = append(mapTo[rc.MapTo], URL{
+			Resource:      ir,
+			Configuration: rc,
+			Role:          role,
+			Elevate:       requiresElevate(ir),
+		})
+	}
+	for _, pattern := range order {
+		list := mapTo[pattern]
+		if len(list) > 1 {
+			e := ConfigError{
+				Kind:    ConfigURLConflict,
+				URL:     pattern,
+				Message: fmt.Sprintf("url %q for app on %q in state %v mapped more than once", pattern, a.Host, lb.LoginState),
 			}
+			for _, u := range list {
+				e.Service = appendUnique(e.Service, u.Resource.ServiceBundle.Name)
+				e.Resource = append(e.Resource, u.Resource.Name)
+			}
+			rr.AddError(e)
+			continue
+		}
+		u := list[0]
+		err := lb.URLRouter.Add(pattern, u)
+		if err != nil {
+			rr.AddError(ConfigError{
+				Kind:     ConfigInvalid,
+				Service:  []string{u.Resource.ServiceBundle.Name},
+				Resource: []string{u.Resource.Name},
+				URL:      pattern,
+				Message:  fmt.Sprintf("unable to map %q for app on %q: %v", u.Resource.Name, a.Host, err),
+			})
 		}
 	}
 }
 
-func (s *RouterServer) updateCompleteSLocked() {
-	// TODO(kardianos): A better implementation should check for conflicts and
-	// deny both. It may also check for permissions or some other allowed
+func appendUnique(list []string, v string) []string {
+	for _, item := range list {
+		if item == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
+
+func (s *RouterServer) updateCompleteSLocked() *RouterRun {
+	rr := s.buildRouterRunSLocked(s.overlay)
+	s.updateRouter <- rr
+	return rr
+}
+
+// buildRouterRunSLocked composes a new RouterRun from the current services
+// and the operator bindings in overlay.
+func (s *RouterServer) buildRouterRunSLocked(overlay map[string]Binding) *RouterRun {
+	// TODO(kardianos): It may also check for permissions or some other allowed
 	// resource verification.
 	rr := NewRouterRun()
-	for _, s := range s.services {
+
+	// Conflicting resources and hosts are collected first, then disabled.
+	resources := map[string][]*Res{}
+	apps := []*App{}
+	hosts := map[string][]*App{}
+	hostOrder := []string{}
+
+	// A service name registered from more than one address disables
+	// every service with that name.
+	named := map[string][]serviceDef{}
+	for key, sd := range s.services {
+		named[key.name] = append(named[key.name], sd)
+	}
+	for name, list := range named {
+		if len(list) < 2 {
+			continue
+		}
+		addrs := make([]string, len(list))
+		for i, sd := range list {
+			addrs[i] = sd.serviceAddress
+		}
+		sort.Strings(addrs)
+		rr.AddError(ConfigError{
+			Kind:    ConfigResourceConflict,
+			Service: []string{name},
+			Message: fmt.Sprintf("service %q registered from more than one address: %s", name, strings.Join(addrs, ", ")),
+		})
+	}
+
+	for key, s := range s.services {
+		if len(named[key.name]) > 1 {
+			continue
+		}
 		locals := s
 		handler := api.NewHTTPClient(s.conn)
 		for _, r := range s.sb.Resource {
 			name := path.Join(s.sb.Name, r.Name)
-			rr.Resource[name] = &Res{
+			resources[name] = append(resources[name], &Res{
 				Name:          name,
 				Type:          api.ResourceType(r.Type),
 				Consume:       api.ResourceType(r.Consume),
@@ -771,23 +1316,19 @@ func (s *RouterServer) updateCompleteSLocked() {
 				Parent:        r.Parent,
 				Configuration: r.Configuration,
 				Include:       r.Include,
-			}
+				Role:          r.Role,
+				Elevate:       r.Elevate,
+			})
 		}
 		for _, a := range s.sb.Application {
 			app := &App{
 				Host:        a.Host,
+				Service:     s.sb.Name,
 				AuthName:    a.AuthConfiguredResource,
+				TLS:         a.TLS,
 				LoginBundle: make(map[api.LoginState]*LoginBundle, len(a.LoginBundle)),
 			}
-			for _, h := range a.Host {
-				rr.App[h] = AppToken{
-					App: app,
-
-					// Unique cookie key per host. Cookies are shared per hostname
-					// and ignore port differences.
-					TokenKey: tokenKeyName(h),
-				}
-			}
+			apps = append(apps, app)
 			for _, lb := range a.LoginBundle {
 				app.LoginBundle[lb.LoginState] = &LoginBundle{
 					LoginState:      lb.LoginState,
@@ -795,16 +1336,83 @@ func (s *RouterServer) updateCompleteSLocked() {
 					ConsumeRedirect: lb.ConsumeRedirect,
 					BundleName:      lb.Resource,
 
-					URLRouter: make(map[string]URL),
+					URLRouter: NewURLTree(),
 				}
 			}
 		}
 	}
+	applyOverlay(rr, apps, overlay)
+	for _, app := range apps {
+		for _, h := range app.Host {
+			if _, found := hosts[h]; !found {
+				hostOrder = append(hostOrder, h)
+			}
+			hosts[h] = append(hosts[h], app)
+		}
+	}
 
-	s.updateRouter <- rr
+	for name, list := range resources {
+		if len(list) > 1 {
+			e := ConfigError{
+				Kind:     ConfigResourceConflict,
+				Resource: []string{name},
+				Message:  fmt.Sprintf("resource %q defined more than once", name),
+			}
+			for _, r := range list {
+				e.Service = appendUnique(e.Service, r.ServiceBundle.Name)
+			}
+			rr.AddError(e)
+			continue
+		}
+		rr.Resource[name] = list[0]
+	}
+	now := time.Now()
+	for _, h := range hostOrder {
+		list := hosts[h]
+		if len(list) > 1 {
+			e := ConfigError{
+				Kind:    ConfigHostConflict,
+				Host:    h,
+				Message: fmt.Sprintf("host %q bound by more than one application", h),
+			}
+			for _, a := range list {
+				e.Service = appendUnique(e.Service, a.Service)
+				if len(a.Bind) > 0 {
+					e.Bind = a.Bind
+				}
+			}
+			rr.AddError(e)
+			continue
+		}
+		// Unique cookie key per host. Cookies are shared per hostname
+		// and ignore port differences.
+		name, previous := s.tokenKeys.names(h, now)
+		rr.App[h] = AppToken{
+			App: list[0],
+
+			TokenKey:         name,
+			PreviousTokenKey: previous,
+			PreviousUntil:    s.tokenKeys.PreviousUntil,
+		}
+	}
+
+	return rr
 }
 
 func (s *RouterServer) Notify(ctx context.Context, n *api.NotifyReq) (*google_protobuf1.Empty, error) {
+	if n.Remove {
+		fmt.Printf("service=%q remove\n", n.ServiceAddress)
+		rr := s.removeServiceAddress(n.ServiceAddress)
+		if rr == nil {
+			return &google_protobuf1.Empty{}, nil
+		}
+		select {
+		case <-rr.applied:
+		case <-ctx.Done():
+			return nil, grpc.Errorf(codes.DeadlineExceeded, "service removal not applied: %v", ctx.Err())
+		}
+		return &google_protobuf1.Empty{}, nil
+	}
 	// For testing attempt to hit the service right back to ensure the service
 	// address is good.
 	//
@@ -814,7 +1422,3 @@ func (s *RouterServer) Notify(ctx context.Context, n *api.NotifyReq) (*google_pr
 	go s.updateServiceAddress(n.ServiceAddress)
 	return &google_protobuf1.Empty{}, nil
 }
-func (s *RouterServer) Update(ctx context.Context, u *api.UpdateReq) (*api.UpdateResp, error) {
-	fmt.Printf("Update: action=%q bind=%q bundle=%q host=%q", u.Action, u.Bind, u.Bundle, u.Host)
-	return &api.UpdateResp{}, nil
-}
@@ -0,0 +1,250 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/solidcoredata/scd/api"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// Binding is an operator managed binding. The application that uses
+// the bundles is served on Host in place of the hosts its service provides.
+type Binding struct {
+	Host   []string
+	Bundle []string
+}
+
+// applyOverlay sets the hosts of the applications bound in overlay.
+// A binding must match exactly one application, otherwise it is not applied.
+func applyOverlay(rr *RouterRun, apps []*App, overlay map[string]Binding) {
+	names := make([]string, 0, len(overlay))
+	for name := range overlay {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		b := overlay[name]
+		var match []*App
+		for _, a := range apps {
+			if appUsesBundle(a, b.Bundle) {
+				match = append(match, a)
+			}
+		}
+		switch len(match) {
+		case 0:
+			rr.AddError(ConfigError{
+				Kind:     ConfigMissing,
+				Bind:     name,
+				Resource: b.Bundle,
+				Message:  fmt.Sprintf("no application uses bundles %q", b.Bundle),
+			})
+			continue
+		case 1:
+		default:
+			e := ConfigError{
+				Kind:     ConfigInvalid,
+				Bind:     name,
+				Resource: b.Bundle,
+				Message:  fmt.Sprintf("bundles %q used by more than one application", b.Bundle),
+			}
+			for _, a := range match {
+				e.Service = appendUnique(e.Service, a.Service)
+			}
+			rr.AddError(e)
+			continue
+		}
+		a := match[0]
+		if len(a.Bind) > 0 {
+			rr.AddError(ConfigError{
+				Kind:    ConfigHostConflict,
+				Service: []string{a.Service},
+				Bind:    name,
+				Message: fmt.Sprintf("application already bound by %q", a.Bind),
+			})
+			continue
+		}
+		a.Host = append([]string(nil), b.Host...)
+		a.Bind = name
+	}
+}
+
+func appUsesBundle(a *App, bundles []string) bool {
+	for _, lb := range a.LoginBundle {
+		for _, b := range bundles {
+			if lb.BundleName == b {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// defaultOverlayFile returns the file bindings are stored in if not configured.
+func defaultOverlayFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "scdrouter", "overlay.json")
+}
+
+// loadOverlay reads the bindings from fn. A missing file has no bindings.
+func loadOverlay(fn string) (map[string]Binding, error) {
+	overlay := map[string]Binding{}
+	if len(fn) == 0 {
+		return overlay, nil
+	}
+	b, err := ioutil.ReadFile(fn)
+	if os.IsNotExist(err) {
+		return overlay, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(b, &overlay)
+	if err != nil {
+		return nil, fmt.Errorf("decode overlay %q: %v", fn, err)
+	}
+	return overlay, nil
+}
+
+// saveOverlay writes the bindings to fn.
+func saveOverlay(fn string, overlay map[string]Binding) error {
+	if len(fn) == 0 {
+		return nil
+	}
+	b, err := json.MarshalIndent(overlay, "", "\t")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(fn, b)
+}
+
+// writeFileAtomic writes b to fn, readable only by the user, replacing
+// the file only after it is fully written.
+func writeFileAtomic(fn string, b []byte) error {
+	dir := filepath.Dir(fn)
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(fn))
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	err = os.Rename(f.Name(), fn)
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// bindingList returns the bindings sorted by name.
+func bindingList(overlay map[string]Binding) []*api.Binding {
+	list := make([]*api.Binding, 0, len(overlay))
+	for name, b := range overlay {
+		list = append(list, &api.Binding{
+			Bind:   name,
+			Host:   b.Host,
+			Bundle: b.Bundle,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Bind < list[j].Bind
+	})
+	return list
+}
+
+// Update changes the operator bindings. The change is validated by composing
+// a router run with it, and is rejected if the binding is in error.
+func (s *RouterServer) Update(ctx context.Context, u *api.UpdateReq) (*api.UpdateResp, error) {
+	s.slk.Lock()
+	defer s.slk.Unlock()
+
+	if u.Action == api.UpdateAction_UpdateNOOP {
+		return &api.UpdateResp{Binding: bindingList(s.overlay)}, nil
+	}
+	if len(u.Bind) == 0 {
+		return nil, grpc.Errorf(codes.InvalidArgument, "missing bind name")
+	}
+
+	next := make(map[string]Binding, len(s.overlay)+1)
+	for name, b := range s.overlay {
+		next[name] = b
+	}
+	current, found := next[u.Bind]
+	switch u.Action {
+	default:
+		return nil, grpc.Errorf(codes.InvalidArgument, "unknown action %v", u.Action)
+	case api.UpdateAction_UpdateInsert:
+		if found {
+			return nil, grpc.Errorf(codes.AlreadyExists, "bind %q already exists", u.Bind)
+		}
+		if len(u.Host) == 0 || len(u.Bundle) == 0 {
+			return nil, grpc.Errorf(codes.InvalidArgument, "bind %q requires at least one host and bundle", u.Bind)
+		}
+		next[u.Bind] = Binding{Host: u.Host, Bundle: u.Bundle}
+	case api.UpdateAction_UpdateAlter:
+		if !found {
+			return nil, grpc.Errorf(codes.NotFound, "bind %q not found", u.Bind)
+		}
+		if len(u.Host) > 0 {
+			current.Host = u.Host
+		}
+		if len(u.Bundle) > 0 {
+			current.Bundle = u.Bundle
+		}
+		next[u.Bind] = current
+	case api.UpdateAction_UpdateDelete:
+		if !found {
+			return nil, grpc.Errorf(codes.NotFound, "bind %q not found", u.Bind)
+		}
+		delete(next, u.Bind)
+	}
+	for _, h := range u.Host {
+		if len(strings.TrimSpace(h)) == 0 {
+			return nil, grpc.Errorf(codes.InvalidArgument, "empty host")
+		}
+	}
+
+	rr := s.buildRouterRunSLocked(next)
+	var msg []string
+	for _, e := range rr.Errors {
+		if e.Bind == u.Bind {
+			msg = append(msg, e.Error())
+		}
+	}
+	if len(msg) > 0 {
+		return nil, grpc.Errorf(codes.FailedPrecondition, "invalid bind %q: %s", u.Bind, strings.Join(msg, "; "))
+	}
+
+	err := saveOverlay(s.overlayFile, next)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Internal, "unable to save bindings: %v", err)
+	}
+	s.overlay = next
+	s.updateRouter <- rr
+	return &api.UpdateResp{Binding: bindingList(next)}, nil
+}
@@ -0,0 +1,188 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/solidcoredata/scd/api"
+)
+
+func (s *RouterServer) currentRouter() *RouterRun {
+	s.rlk.RLock()
+	rr := s.router
+	s.rlk.RUnlock()
+	return rr
+}
+
+func (s *RouterServer) Status(ctx context.Context, req *api.StatusReq) (*api.StatusResp, error) {
+	resp := &api.StatusResp{}
+
+	s.slk.Lock()
+	for key, sd := range s.services {
+		ss := &api.StatusService{
+			Name:    key.name,
+			Address: sd.serviceAddress,
+		}
+		if sd.conn != nil {
+			ss.State = sd.conn.GetState().String()
+		}
+		resp.Service = append(resp.Service, ss)
+	}
+	s.slk.Unlock()
+	sort.Slice(resp.Service, func(i, j int) bool {
+		a, b := resp.Service[i], resp.Service[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Address < b.Address
+	})
+
+	// Servers that failed to listen are reported even before the first
+	// router run.
+	resp.Error = statusErrors(s.servers.status())
+	rr := s.currentRouter()
+	if rr == nil {
+		return resp, nil
+	}
+	resp.Version = rr.Version
+
+	for _, a := range uniqueApps(rr) {
+		sa := &api.StatusApp{
+			Service: a.Service,
+			Host:    append([]string(nil), a.Host...),
+			Auth:    a.AuthName,
+			Bind:    a.Bind,
+		}
+		for _, lb := range sortedLoginBundles(a) {
+			slb := &api.StatusLoginBundle{
+				LoginState:      lb.LoginState,
+				Prefix:          lb.Prefix,
+				ConsumeRedirect: lb.ConsumeRedirect,
+				Bundle:          lb.BundleName,
+			}
+			lb.URLRouter.Walk(func(pattern string, u URL) {
+				slb.URL = append(slb.URL, statusURL(pattern, u))
+			})
+			sa.LoginBundle = append(sa.LoginBundle, slb)
+		}
+		resp.App = append(resp.App, sa)
+	}
+
+	resp.Error = append(resp.Error, statusErrors(rr.Errors)...)
+	return resp, nil
+}
+
+func statusErrors(errs []ConfigError) []*api.StatusError {
+	list := make([]*api.StatusError, 0, len(errs))
+	for _, e := range errs {
+		list = append(list, &api.StatusError{
+			Kind:     e.Kind.String(),
+			Service:  e.Service,
+			Host:     e.Host,
+			Resource: e.Resource,
+			URL:      e.URL,
+			Bind:     e.Bind,
+			Message:  e.Message,
+		})
+	}
+	return list
+}
+
+func (s *RouterServer) Resources(ctx context.Context, req *api.ResourcesReq) (*api.ResourcesResp, error) {
+	resp := &api.ResourcesResp{}
+	rr := s.currentRouter()
+	if rr == nil {
+		return resp, nil
+	}
+	resp.Version = rr.Version
+	resp.Resource = statusResources(rr)
+	return resp, nil
+}
+
+// statusResources returns the resources sorted by name.
+func statusResources(rr *RouterRun) []*api.StatusResource {
+	list := make([]*api.StatusResource, 0, len(rr.Resource))
+	for _, r := range rr.Resource {
+		sr := &api.StatusResource{
+			Name:    r.Name,
+			Type:    r.Type,
+			Consume: r.Consume,
+			Parent:  r.Parent,
+			Role:    r.Role,
+			Elevate: r.Elevate,
+		}
+		for _, ir := range r.IncludeRes {
+			sr.Include = append(sr.Include, ir.Name)
+		}
+		if r.ServiceBundle != nil {
+			sr.Service = r.ServiceBundle.Name
+		}
+		list = append(list, sr)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// uniqueApps returns each app in the router run once, ordered by service
+// and first host.
+func uniqueApps(rr *RouterRun) []*App {
+	seen := map[*App]bool{}
+	var list []*App
+	for _, at := range rr.App {
+		if seen[at.App] {
+			continue
+		}
+		seen[at.App] = true
+		list = append(list, at.App)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if len(a.Host) == 0 || len(b.Host) == 0 {
+			return len(a.Host) < len(b.Host)
+		}
+		return a.Host[0] < b.Host[0]
+	})
+	return list
+}
+
+// sortedLoginBundles returns the login bundles ordered by login state.
+func sortedLoginBundles(a *App) []*LoginBundle {
+	list := make([]*LoginBundle, 0, len(a.LoginBundle))
+	for _, lb := range a.LoginBundle {
+		list = append(list, lb)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].LoginState < list[j].LoginState
+	})
+	return list
+}
+
+// statusURL describes the URL routed by pattern.
+func statusURL(pattern string, u URL) *api.StatusURL {
+	su := &api.StatusURL{
+		Pattern: pattern,
+		Elevate: u.Elevate,
+	}
+	if u.Resource != nil {
+		su.Resource = u.Resource.Name
+		for r := u.Resource; r != nil; r = r.ParentRes {
+			if len(r.Role) > 0 {
+				su.Role = append(su.Role, strings.Join(r.Role, "|"))
+			}
+		}
+	}
+	if u.Configuration != nil {
+		su.Stream = u.Configuration.Stream
+	}
+	return su
+}
@@ -0,0 +1,165 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/solidcoredata/scd/api"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// requestBody reads the request body and records why reading it failed.
+// A streaming request reads the body from another goroutine.
+type requestBody struct {
+	r     io.Reader
+	limit int64 // Zero if the body size is not limited.
+
+	lk  sync.Mutex
+	n   int64
+	err error // First read error other than io.EOF.
+}
+
+func (b *requestBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	b.lk.Lock()
+	b.n += int64(n)
+	if err != nil && err != io.EOF && b.err == nil {
+		b.err = err
+	}
+	b.lk.Unlock()
+	return n, err
+}
+
+// readErr returns the first error reading the body, other than io.EOF.
+func (b *requestBody) readErr() error {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+	return b.err
+}
+
+// tooLarge reports if reading the body stopped at the size limit.
+func (b *requestBody) tooLarge() bool {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+	return b.err != nil && b.limit > 0 && b.n >= b.limit
+}
+
+// httpStream is an open streaming request to a service.
+type httpStream struct {
+	api.HTTP_ServeHTTPStreamClient
+
+	cancel context.CancelFunc
+}
+
+// close cancels the stream. It must be called before the HTTP handler
+// returns. A body read already in progress is not waited for: it ends at
+// the server read timeout or when the server closes the body, and the
+// body is not read further.
+func (hs *httpStream) close() {
+	hs.cancel()
+}
+
+// openHTTPStream sends the request to the service, streams the body to it,
+// and waits for the response headers. If reading the body fails the stream
+// is canceled so the service does not see a complete body.
+//
+// The returned stream is used to read the response body with copyHTTPStream
+// and must be closed.
+func openHTTPStream(ctx context.Context, handler api.HTTPClient, req *api.HTTPRequest, body io.Reader) (*api.HTTPResponse, *httpStream, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := handler.ServeHTTPStream(ctx)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	err = stream.Send(&api.HTTPStreamRequest{
+		Value: &api.HTTPStreamRequest_Request{Request: req},
+	})
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	hs := &httpStream{
+		HTTP_ServeHTTPStreamClient: stream,
+
+		cancel: cancel,
+	}
+	go func() {
+		buf := make([]byte, api.StreamChunkSize)
+		for {
+			n, err := body.Read(buf)
+			if n > 0 {
+				chunk := make([]byte, n)
+				copy(chunk, buf[:n])
+				serr := stream.Send(&api.HTTPStreamRequest{
+					Value: &api.HTTPStreamRequest_Chunk{Chunk: chunk},
+				})
+				if serr != nil {
+					// The service stopped reading, the error will be
+					// returned by Recv.
+					return
+				}
+			}
+			switch {
+			case err == io.EOF:
+				stream.CloseSend()
+				return
+			case err != nil:
+				// The body is incomplete.
+				cancel()
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+		}
+	}()
+
+	msg, err := stream.Recv()
+	if err != nil {
+		hs.close()
+		return nil, nil, err
+	}
+	resp := msg.GetResponse()
+	if resp == nil {
+		hs.close()
+		return nil, nil, grpc.Errorf(codes.Internal, "service stream did not start with a response")
+	}
+	return resp, hs, nil
+}
+
+// copyHTTPStream writes the response body chunks to w as they are received.
+// The response headers must already be written.
+func copyHTTPStream(w http.ResponseWriter, stream api.HTTP_ServeHTTPStreamClient) {
+	flusher, _ := w.(http.Flusher)
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			// Headers have already been sent, the best that can be done
+			// is to cut the response short.
+			log.Printf("router: response stream: %v", err)
+			return
+		}
+		_, err = w.Write(msg.GetChunk())
+		if err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
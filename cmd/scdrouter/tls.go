@@ -0,0 +1,303 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/solidcoredata/scd/api"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// certSource provides the certificate for a TLS handshake.
+type certSource interface {
+	GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error)
+}
+
+// hostName returns the host without the port.
+func hostName(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
+
+// certFile is a certificate loaded from a certificate and key file.
+// The files are checked for changes at most once every certFileCheck.
+type certFile struct {
+	certFile string
+	keyFile  string
+
+	lk        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+const certFileCheck = time.Second * 10
+
+func (cf *certFile) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cf.lk.Lock()
+	defer cf.lk.Unlock()
+
+	now := time.Now()
+	if cf.cert != nil && now.Sub(cf.lastCheck) < certFileCheck {
+		return cf.cert, nil
+	}
+	cf.lastCheck = now
+
+	modTime, err := cf.fileModTime()
+	if err != nil {
+		if cf.cert != nil {
+			// Keep serving the last good certificate.
+			return cf.cert, nil
+		}
+		return nil, err
+	}
+	if cf.cert != nil && !modTime.After(cf.modTime) {
+		return cf.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(cf.certFile, cf.keyFile)
+	if err != nil {
+		if cf.cert != nil {
+			// The files may be in the middle of being replaced.
+			return cf.cert, nil
+		}
+		return nil, fmt.Errorf("router: unable to load certificate %q: %v", cf.certFile, err)
+	}
+	cf.cert = &cert
+	cf.modTime = modTime
+	return cf.cert, nil
+}
+
+func (cf *certFile) fileModTime() (time.Time, error) {
+	var last time.Time
+	for _, fn := range []string{cf.certFile, cf.keyFile} {
+		fi, err := os.Stat(fn)
+		if err != nil {
+			return last, err
+		}
+		if fi.ModTime().After(last) {
+			last = fi.ModTime()
+		}
+	}
+	return last, nil
+}
+
+// tlsManager selects certificates for TLS connections from the current
+// RouterRun. Certificate files and ACME clients are kept between
+// router runs.
+type tlsManager struct {
+	cacheDir string
+
+	lk    sync.Mutex
+	files map[string]*certFile
+	acme  map[string]*autocert.Manager
+
+	rlk   sync.RWMutex
+	certs map[string]certSource
+}
+
+func newTLSManager(cacheDir string) *tlsManager {
+	return &tlsManager{
+		cacheDir: cacheDir,
+		files:    make(map[string]*certFile),
+		acme:     make(map[string]*autocert.Manager),
+		certs:    make(map[string]certSource),
+	}
+}
+
+func (tm *tlsManager) source(c *api.ApplicationTLS) (certSource, error) {
+	tm.lk.Lock()
+	defer tm.lk.Unlock()
+
+	switch {
+	case len(c.CertFile) > 0 || len(c.KeyFile) > 0:
+		if len(c.CertFile) == 0 || len(c.KeyFile) == 0 {
+			return nil, fmt.Errorf("both a certificate and key file must be set")
+		}
+		key := c.CertFile + "\x00" + c.KeyFile
+		cf, found := tm.files[key]
+		if !found {
+			cf = &certFile{certFile: c.CertFile, keyFile: c.KeyFile}
+			tm.files[key] = cf
+		}
+		return cf, nil
+	case c.ACME:
+		dir := c.ACMEDirectory
+		if len(dir) == 0 {
+			dir = autocert.DefaultACMEDirectory
+		}
+		key := dir + "\x00" + c.ACMEEmail
+		m, found := tm.acme[key]
+		if !found {
+			m = &autocert.Manager{
+				Prompt:     autocert.AcceptTOS,
+				Email:      c.ACMEEmail,
+				HostPolicy: tm.hostPolicy,
+				Client:     &acme.Client{DirectoryURL: dir},
+			}
+			if len(tm.cacheDir) > 0 {
+				m.Cache = autocert.DirCache(filepath.Join(tm.cacheDir, cacheDirName(dir)))
+			}
+			tm.acme[key] = m
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("no certificate source configured")
+}
+
+// certCacheDir returns the directory ACME certificates and account keys
+// are stored in. If empty, ACME certificates are only kept in memory.
+func certCacheDir() string {
+	if dir := os.Getenv("SCD_CERT_CACHE"); len(dir) > 0 {
+		return dir
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "scdrouter", "acme")
+}
+
+// cacheDirName returns a file name safe version of the ACME directory URL.
+func cacheDirName(dir string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		}
+		return '_'
+	}, strings.TrimPrefix(strings.TrimPrefix(dir, "https://"), "http://"))
+}
+
+// hostPolicy only allows ACME certificates for hosts in the current router.
+func (tm *tlsManager) hostPolicy(ctx context.Context, host string) error {
+	tm.rlk.RLock()
+	_, found := tm.certs[host]
+	tm.rlk.RUnlock()
+	if !found {
+		return fmt.Errorf("router: host %q not configured for TLS", host)
+	}
+	return nil
+}
+
+// resolve the certificate source of each TLS application host in the
+// router run. Hosts which share a name but have different certificate
+// configurations are disabled for TLS.
+func (tm *tlsManager) resolve(rr *RouterRun) map[string]certSource {
+	certs := make(map[string]certSource, len(rr.App))
+	configs := make(map[string]*api.ApplicationTLS, len(rr.App))
+	conflict := map[string]bool{}
+	for host, at := range rr.App {
+		a := at.App
+		if a.TLS == nil {
+			continue
+		}
+		name := hostName(host)
+		if conflict[name] {
+			continue
+		}
+		if prev, found := configs[name]; found {
+			if *prev == *a.TLS {
+				continue
+			}
+			rr.AddError(ConfigError{
+				Kind:    ConfigHostConflict,
+				Service: []string{a.Service},
+				Host:    name,
+				Message: fmt.Sprintf("TLS for host %q configured more than once", name),
+			})
+			delete(certs, name)
+			conflict[name] = true
+			continue
+		}
+		src, err := tm.source(a.TLS)
+		if err != nil {
+			rr.AddError(ConfigError{
+				Kind:    ConfigInvalid,
+				Service: []string{a.Service},
+				Host:    host,
+				Message: fmt.Sprintf("invalid TLS configuration for app on %q: %v", a.Host, err),
+			})
+			continue
+		}
+		configs[name] = a.TLS
+		certs[name] = src
+	}
+	return certs
+}
+
+// update sets the certificates to use for new connections.
+func (tm *tlsManager) update(certs map[string]certSource) {
+	tm.rlk.Lock()
+	tm.certs = certs
+	tm.rlk.Unlock()
+}
+
+// GetCertificate selects the certificate from the SNI server name.
+func (tm *tlsManager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	tm.rlk.RLock()
+	src, found := tm.certs[hello.ServerName]
+	tm.rlk.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("router: no certificate for %q", hello.ServerName)
+	}
+	return src.GetCertificate(hello)
+}
+
+// TLSConfig for the HTTPS listener.
+func (tm *tlsManager) TLSConfig() *tls.Config {
+	return &tls.Config{
+		GetCertificate: tm.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1", acme.ALPNProto},
+		MinVersion:     tls.VersionTLS12,
+	}
+}
+
+// HTTPHandler answers ACME HTTP challenges and passes all other requests
+// to next.
+func (tm *tlsManager) HTTPHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/.well-known/acme-challenge/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		tm.rlk.RLock()
+		src := tm.certs[hostName(r.Host)]
+		tm.rlk.RUnlock()
+		if m, is := src.(*autocert.Manager); is {
+			m.HTTPHandler(next).ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// newTLSState returns the TLS state of the connection sent to services.
+func newTLSState(cs *tls.ConnectionState) *api.TLSState {
+	if cs == nil {
+		return nil
+	}
+	return &api.TLSState{
+		Version:            uint32(cs.Version),
+		HandshakeComplete:  cs.HandshakeComplete,
+		DidResume:          cs.DidResume,
+		CipherSuite:        uint32(cs.CipherSuite),
+		ServerName:         cs.ServerName,
+		NegotiatedProtocol: cs.NegotiatedProtocol,
+	}
+}
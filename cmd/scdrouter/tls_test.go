@@ -0,0 +1,356 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/solidcoredata/scd/api"
+
+	"golang.org/x/crypto/acme"
+)
+
+// acmeStandin is a minimal RFC 8555 ACME server for a single order. It does
+// not verify request signatures but does validate the tls-alpn-01 challenge
+// against the TLS listener at validateAddr.
+type acmeStandin struct {
+	t            *testing.T
+	url          string
+	validateAddr string
+
+	caKey  *ecdsa.PrivateKey
+	caCert *x509.Certificate
+
+	lk         sync.Mutex
+	nonce      int
+	thumbprint string
+	domain     string
+	status     string // Order status.
+	authz      string // Authorization status.
+	token      string
+	cert       []byte // PEM chain once issued.
+}
+
+func newACMEStandin(t *testing.T) *acmeStandin {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ACME stand-in CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &acmeStandin{t: t, caKey: key, caCert: ca, token: "standin-token"}
+}
+
+// read returns the protected header and payload of a JWS request.
+func (s *acmeStandin) read(r *http.Request) (map[string]json.RawMessage, []byte, error) {
+	msg := struct {
+		Protected string `json:"protected"`
+		Payload   string `json:"payload"`
+	}{}
+	err := json.NewDecoder(r.Body).Decode(&msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := base64.RawURLEncoding.DecodeString(msg.Protected)
+	if err != nil {
+		return nil, nil, err
+	}
+	header := map[string]json.RawMessage{}
+	err = json.Unmarshal(b, &header)
+	if err != nil {
+		return nil, nil, err
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(msg.Payload)
+	return header, payload, err
+}
+
+func (s *acmeStandin) write(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (s *acmeStandin) orderLocked() interface{} {
+	o := map[string]interface{}{
+		"status":         s.status,
+		"expires":        time.Now().Add(time.Hour).Format(time.RFC3339),
+		"identifiers":    []interface{}{map[string]string{"type": "dns", "value": s.domain}},
+		"authorizations": []string{s.url + "/authz/1"},
+		"finalize":       s.url + "/finalize/1",
+	}
+	if s.cert != nil {
+		o["certificate"] = s.url + "/cert/1"
+	}
+	return o
+}
+
+func (s *acmeStandin) challengeLocked() interface{} {
+	return map[string]string{
+		"type":   "tls-alpn-01",
+		"url":    s.url + "/chal/1",
+		"token":  s.token,
+		"status": s.authz,
+	}
+}
+
+func (s *acmeStandin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	s.nonce++
+	w.Header().Set("Replay-Nonce", fmt.Sprintf("nonce-%d", s.nonce))
+	if r.URL.Path == "/directory" {
+		s.write(w, http.StatusOK, map[string]string{
+			"newNonce":   s.url + "/nonce",
+			"newAccount": s.url + "/account",
+			"newOrder":   s.url + "/order",
+			"revokeCert": s.url + "/revoke",
+			"keyChange":  s.url + "/key-change",
+		})
+		return
+	}
+	if r.URL.Path == "/nonce" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	header, payload, err := s.read(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.URL.Path {
+	default:
+		http.NotFound(w, r)
+	case "/account":
+		jwk := struct {
+			X, Y string
+		}{}
+		err = json.Unmarshal(header["jwk"], &jwk)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		x, _ := base64.RawURLEncoding.DecodeString(jwk.X)
+		y, _ := base64.RawURLEncoding.DecodeString(jwk.Y)
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		s.thumbprint, err = acme.JWKThumbprint(pub)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Location", s.url+"/account/1")
+		s.write(w, http.StatusCreated, map[string]string{"status": "valid"})
+	case "/order":
+		req := struct {
+			Identifiers []struct{ Value string }
+		}{}
+		json.Unmarshal(payload, &req)
+		if len(req.Identifiers) != 1 {
+			http.Error(w, "one identifier expected", http.StatusBadRequest)
+			return
+		}
+		s.domain = req.Identifiers[0].Value
+		s.status, s.authz = "pending", "pending"
+		w.Header().Set("Location", s.url+"/order/1")
+		s.write(w, http.StatusCreated, s.orderLocked())
+	case "/order/1":
+		w.Header().Set("Location", s.url+"/order/1")
+		s.write(w, http.StatusOK, s.orderLocked())
+	case "/authz/1":
+		s.write(w, http.StatusOK, map[string]interface{}{
+			"status":     s.authz,
+			"identifier": map[string]string{"type": "dns", "value": s.domain},
+			"challenges": []interface{}{s.challengeLocked()},
+		})
+	case "/chal/1":
+		err = s.validate()
+		if err != nil {
+			s.t.Errorf("tls-alpn-01 validation: %v", err)
+			s.status, s.authz = "invalid", "invalid"
+		} else {
+			s.status, s.authz = "ready", "valid"
+		}
+		s.write(w, http.StatusOK, s.challengeLocked())
+	case "/finalize/1":
+		req := struct {
+			CSR string
+		}{}
+		json.Unmarshal(payload, &req)
+		s.cert, err = s.issue(req.CSR)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.status = "valid"
+		w.Header().Set("Location", s.url+"/order/1")
+		s.write(w, http.StatusOK, s.orderLocked())
+	case "/cert/1":
+		w.Header().Set("Content-Type", "application/pem-certificate-chain")
+		w.Write(s.cert)
+	}
+}
+
+// validate connects to the TLS listener as an ACME server would and checks
+// the challenge certificate.
+func (s *acmeStandin) validate() error {
+	conn, err := tls.Dial("tcp", s.validateAddr, &tls.Config{
+		ServerName:         s.domain,
+		NextProtos:         []string{acme.ALPNProto},
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	cs := conn.ConnectionState()
+	if cs.NegotiatedProtocol != acme.ALPNProto {
+		return fmt.Errorf("negotiated protocol %q", cs.NegotiatedProtocol)
+	}
+	want := sha256.Sum256([]byte(s.token + "." + s.thumbprint))
+	idPeACMEIdentifier := asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 31}
+	for _, ext := range cs.PeerCertificates[0].Extensions {
+		if !ext.Id.Equal(idPeACMEIdentifier) {
+			continue
+		}
+		var got []byte
+		_, err = asn1.Unmarshal(ext.Value, &got)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(got, want[:]) {
+			return fmt.Errorf("wrong key authorization")
+		}
+		return nil
+	}
+	return fmt.Errorf("challenge certificate missing acmeIdentifier")
+}
+
+func (s *acmeStandin) issue(csr64 string) ([]byte, error) {
+	der, err := base64.RawURLEncoding.DecodeString(csr64)
+	if err != nil {
+		return nil, err
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: s.domain},
+		DNSNames:     csr.DNSNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	leaf, err := x509.CreateCertificate(rand.Reader, tmpl, s.caCert, csr.PublicKey, s.caKey)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	pem.Encode(buf, &pem.Block{Type: "CERTIFICATE", Bytes: leaf})
+	pem.Encode(buf, &pem.Block{Type: "CERTIFICATE", Bytes: s.caCert.Raw})
+	return buf.Bytes(), nil
+}
+
+func TestACMECertificate(t *testing.T) {
+	const host = "app.example.test"
+
+	standin := newACMEStandin(t)
+	acmeServer := httptest.NewServer(standin)
+	defer acmeServer.Close()
+	standin.url = acmeServer.URL
+
+	tm := newTLSManager("")
+	src, err := tm.source(&api.ApplicationTLS{
+		ACME:          true,
+		ACMEDirectory: acmeServer.URL + "/directory",
+		ACMEEmail:     "admin@example.test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm.update(map[string]certSource{host: src})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	standin.validateAddr = l.Addr().String()
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		}),
+		TLSConfig: tm.TLSConfig(),
+		ErrorLog:  log.New(ioutil.Discard, "", 0),
+	}
+	go srv.ServeTLS(l, "", "")
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(standin.caCert)
+	conn, err := tls.Dial("tcp", l.Addr().String(), &tls.Config{
+		ServerName: host,
+		RootCAs:    roots,
+	})
+	if err != nil {
+		t.Fatalf("handshake: %v", err)
+	}
+	defer conn.Close()
+	leaf := conn.ConnectionState().PeerCertificates[0]
+	if err := leaf.VerifyHostname(host); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(leaf.Issuer.CommonName, "stand-in") {
+		t.Fatalf("certificate issued by %q", leaf.Issuer.CommonName)
+	}
+
+	// Hosts not in the router are refused.
+	_, err = tls.Dial("tcp", l.Addr().String(), &tls.Config{
+		ServerName: "other.example.test",
+		RootCAs:    roots,
+	})
+	if err == nil {
+		t.Fatal("expected a handshake error for an unknown host")
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// URLTree routes request paths to URL resources.
+//
+// A pattern is a "/" separated path that may take one of the forms:
+//
+//	"/api/login"       matches "/api/login" exactly.
+//	"/lib/"            matches "/lib", "/lib/" and every path under it.
+//	"/api/files/{id}"  matches "/api/files/42" and captures id=42.
+//
+// The root pattern "/" only matches the root path.
+//
+// When more than one pattern matches a path, the longest matching prefix
+// wins. At each segment a static match is preferred over a parameter, and a
+// parameter is preferred over a subtree.
+type URLTree struct {
+	root *urlNode
+}
+
+type urlNode struct {
+	static map[string]*urlNode
+
+	param     *urlNode
+	paramName string
+
+	exact   *URL
+	subtree *URL
+}
+
+// URLMatch is the result of a successful URLTree lookup.
+type URLMatch struct {
+	URL
+
+	// Remainder of the path below a subtree pattern, without a leading "/".
+	Remainder string
+
+	// Param values captured from the path.
+	Param map[string]string
+}
+
+func NewURLTree() *URLTree {
+	return &URLTree{
+		root: &urlNode{},
+	}
+}
+
+func splitURLPattern(p string) []string {
+	return strings.Split(strings.TrimPrefix(p, "/"), "/")
+}
+
+func paramName(seg string) (string, bool) {
+	if len(seg) > 2 && seg[0] == '{' && seg[len(seg)-1] == '}' {
+		return seg[1 : len(seg)-1], true
+	}
+	return "", false
+}
+
+// Add the URL to the tree under the given pattern.
+// An error is returned if the pattern is invalid or is already mapped.
+func (t *URLTree) Add(pattern string, u URL) error {
+	if !strings.HasPrefix(pattern, "/") {
+		return fmt.Errorf("url pattern %q must start with a \"/\"", pattern)
+	}
+	segs := splitURLPattern(pattern)
+	subtree := false
+	if len(segs) > 1 && segs[len(segs)-1] == "" {
+		segs = segs[:len(segs)-1]
+		subtree = true
+	}
+
+	n := t.root
+	for _, seg := range segs {
+		if name, is := paramName(seg); is {
+			if n.param == nil {
+				n.param = &urlNode{}
+				n.paramName = name
+			}
+			if n.paramName != name {
+				return fmt.Errorf("url pattern %q parameter %q conflicts with parameter %q", pattern, name, n.paramName)
+			}
+			n = n.param
+			continue
+		}
+		if strings.ContainsAny(seg, "{}") {
+			return fmt.Errorf("url pattern %q has an invalid segment %q", pattern, seg)
+		}
+		if n.static == nil {
+			n.static = make(map[string]*urlNode)
+		}
+		next, found := n.static[seg]
+		if !found {
+			next = &urlNode{}
+			n.static[seg] = next
+		}
+		n = next
+	}
+
+	uc := u
+	switch subtree {
+	case true:
+		if n.subtree != nil {
+			return fmt.Errorf("url pattern %q already mapped", pattern)
+		}
+		n.subtree = &uc
+	case false:
+		if n.exact != nil {
+			return fmt.Errorf("url pattern %q already mapped", pattern)
+		}
+		n.exact = &uc
+	}
+	return nil
+}
+
+// Match looks up the request path p. Paths with "." or ".." segments, or
+// with empty segments other than a trailing slash, never match so they
+// are not passed on to services in a Remainder or Param.
+func (t *URLTree) Match(p string) (URLMatch, bool) {
+	if t == nil || !strings.HasPrefix(p, "/") {
+		return URLMatch{}, false
+	}
+	segs := splitURLPattern(p)
+	for i, seg := range segs {
+		switch {
+		case seg == "." || seg == "..":
+			return URLMatch{}, false
+		case len(seg) == 0 && i < len(segs)-1:
+			return URLMatch{}, false
+		}
+	}
+	m := URLMatch{}
+	found := t.root.match(segs, 0, &m)
+	if !found {
+		return URLMatch{}, false
+	}
+	return m, true
+}
+
+func (n *urlNode) match(segs []string, i int, m *URLMatch) bool {
+	if i == len(segs) {
+		switch {
+		case n.exact != nil:
+			m.URL = *n.exact
+			return true
+		case n.subtree != nil:
+			m.URL = *n.subtree
+			return true
+		}
+		return false
+	}
+	seg := segs[i]
+	if next, found := n.static[seg]; found && next.match(segs, i+1, m) {
+		return true
+	}
+	if n.param != nil && len(seg) > 0 && n.param.match(segs, i+1, m) {
+		if m.Param == nil {
+			m.Param = make(map[string]string, 2)
+		}
+		m.Param[n.paramName] = seg
+		return true
+	}
+	if n.subtree != nil {
+		m.URL = *n.subtree
+		m.Remainder = strings.Join(segs[i:], "/")
+		return true
+	}
+	return false
+}
+
+// Walk calls f for each pattern in the tree, in pattern order.
+func (t *URLTree) Walk(f func(pattern string, u URL)) {
+	if t == nil {
+		return
+	}
+	t.root.walk("", f)
+}
+
+func (n *urlNode) walk(prefix string, f func(pattern string, u URL)) {
+	if n.exact != nil {
+		f(prefix, *n.exact)
+	}
+	if n.subtree != nil {
+		f(prefix+"/", *n.subtree)
+	}
+	keys := make([]string, 0, len(n.static))
+	for k := range n.static {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		n.static[k].walk(prefix+"/"+k, f)
+	}
+	if n.param != nil {
+		n.param.walk(prefix+"/{"+n.paramName+"}", f)
+	}
+}
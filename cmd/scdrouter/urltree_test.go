@@ -0,0 +1,94 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/solidcoredata/scd/api"
+)
+
+func TestURLTreeMatch(t *testing.T) {
+	tree := NewURLTree()
+	for _, pattern := range []string{
+		"/",
+		"/api/login",
+		"/api/files/{id}",
+		"/api/files/{id}/raw",
+		"/api/files/list",
+		"/lib/",
+		"/lib/special/",
+		"/lib/special/exact",
+		"/{page}",
+	} {
+		err := tree.Add(pattern, URL{Configuration: &api.ConfigureURL{MapTo: pattern}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list := []struct {
+		Path      string
+		Pattern   string // Empty if no match.
+		Remainder string
+		Param     map[string]string
+	}{
+		// Exact.
+		{Path: "/", Pattern: "/"},
+		{Path: "/api/login", Pattern: "/api/login"},
+		{Path: "/api/login/", Pattern: ""},
+		{Path: "/api/logout", Pattern: ""},
+
+		// Parameter.
+		{Path: "/api/files/42", Pattern: "/api/files/{id}", Param: map[string]string{"id": "42"}},
+		{Path: "/api/files/42/raw", Pattern: "/api/files/{id}/raw", Param: map[string]string{"id": "42"}},
+		{Path: "/api/files/", Pattern: ""},
+		{Path: "/about", Pattern: "/{page}", Param: map[string]string{"page": "about"}},
+
+		// Static is preferred over a parameter.
+		{Path: "/api/files/list", Pattern: "/api/files/list"},
+
+		// Subtree.
+		{Path: "/lib", Pattern: "/lib/"},
+		{Path: "/lib/", Pattern: "/lib/"},
+		{Path: "/lib/a/b.js", Pattern: "/lib/", Remainder: "a/b.js"},
+
+		// Longest prefix wins.
+		{Path: "/lib/special/x.js", Pattern: "/lib/special/", Remainder: "x.js"},
+		{Path: "/lib/special/exact", Pattern: "/lib/special/exact"},
+		{Path: "/lib/special/exact/more", Pattern: "/lib/special/", Remainder: "exact/more"},
+
+		// Dot and empty segments never match.
+		{Path: "/lib/../../x", Pattern: ""},
+		{Path: "/lib/./a.js", Pattern: ""},
+		{Path: "/lib//a.js", Pattern: ""},
+		{Path: "/api/files/../login", Pattern: ""},
+		{Path: "/..", Pattern: ""},
+		{Path: "lib/a.js", Pattern: ""},
+	}
+	for _, item := range list {
+		m, found := tree.Match(item.Path)
+		if len(item.Pattern) == 0 {
+			if found {
+				t.Errorf("%q: unexpected match %q", item.Path, m.Configuration.MapTo)
+			}
+			continue
+		}
+		if !found {
+			t.Errorf("%q: not found, want %q", item.Path, item.Pattern)
+			continue
+		}
+		if got := m.Configuration.MapTo; got != item.Pattern {
+			t.Errorf("%q: matched %q, want %q", item.Path, got, item.Pattern)
+		}
+		if m.Remainder != item.Remainder {
+			t.Errorf("%q: remainder %q, want %q", item.Path, m.Remainder, item.Remainder)
+		}
+		if !reflect.DeepEqual(m.Param, item.Param) {
+			t.Errorf("%q: param %v, want %v", item.Path, m.Param, item.Param)
+		}
+	}
+}
@@ -69,6 +69,15 @@ func (s *ServiceConfig) BundleUpdate(sb *api.ServiceBundle) {
 	}
 }
 
+// loginPage maps a page resource to its file, relative to the service name.
+var loginPage = map[string]string{
+	"login":           "/login/none",
+	"u2f":             "/login/u2f",
+	"change-password": "/login/change-password",
+	"elevate":         "/login/elevate",
+	"security-key":    "/login/security-key",
+}
+
 // Return an array of items:
 type ReturnItem struct {
 	Name    string
@@ -115,6 +124,10 @@ func JSON(v interface{}) string {
 // How do I know if the required resource is a code or configuration? Probably
 // have two different Required field, one for config, one for code.
 
+func (s *ServiceConfig) ServeHTTPStream(stream api.HTTP_ServeHTTPStreamServer) error {
+	return api.ServeStreamUnary(stream, s)
+}
+
 func (s *ServiceConfig) ServeHTTP(ctx context.Context, r *api.HTTPRequest) (*api.HTTPResponse, error) {
 	resp := &api.HTTPResponse{}
 	switch r.URL.Path {
@@ -124,12 +137,21 @@ func (s *ServiceConfig) ServeHTTP(ctx context.Context, r *api.HTTPRequest) (*api
 		buf := &bytes.Buffer{}
 		c := struct {
 			Next string
+
+			// Identity is the user the session acts as and Impersonator
+			// the signed in user, empty if not impersonating.
+			Identity     string
+			Impersonator string
 		}{}
 		fmt.Println("loader;Config", r.Config.Config)
 		err := json.Unmarshal([]byte(r.Config.Config), &c)
 		if err != nil {
 			return nil, err
 		}
+		if r.Auth != nil && r.Auth.Secondary != nil {
+			c.Identity = r.Auth.Identity
+			c.Impersonator = r.Auth.Secondary.Identity
+		}
 		s.mu.RLock()
 		err = s.loginTemplate.Execute(buf, c)
 		s.mu.RUnlock()
@@ -139,8 +161,8 @@ func (s *ServiceConfig) ServeHTTP(ctx context.Context, r *api.HTTPRequest) (*api
 		}
 		resp.ContentType = "text/html"
 		resp.Body = buf.Bytes()
-	case "login":
-		resName := r.URL.Host + "/login/none"
+	case "login", "u2f", "change-password", "elevate", "security-key":
+		resName := r.URL.Host + loginPage[r.URL.Path]
 		body, found := s.service.SPA(resName)
 		if !found {
 			return nil, grpc.Errorf(codes.NotFound, "path %q not found", resName)
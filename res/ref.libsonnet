@@ -15,6 +15,9 @@
 	LoginState: {
 		None: "None",
 		Granted: "Granted",
+		U2F: "U2F",
+		ChangePassword: "ChangePassword",
+		Elevate: "Elevate",
 	},
 	
 	C: {
@@ -1,18 +1,12 @@
 package service
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
-	"strings"
-	"time"
 
 	"github.com/solidcoredata/scd/api"
-
-	"github.com/cortesi/moddwatch"
-	"github.com/google/go-jsonnet"
 )
 
 /*
@@ -38,9 +32,17 @@ type Resource struct {
 	Consume string // api.ResourceType
 	Parent  string
 	Include []string
+	Role    []string
+	Elevate bool
 	C       map[string]interface{}
 }
 
+// Role names a role ID of an authentication service.
+type Role struct {
+	ID   int64
+	Name string
+}
+
 type LoginBundle struct {
 	ConsumeRedirect bool
 	Resource        string
@@ -48,10 +50,22 @@ type LoginBundle struct {
 	LoginState      string // api.LoginState_value
 }
 
+// ApplicationTLS terminates TLS for the application hosts with either
+// the certificate and key files or an ACME issued certificate.
+type ApplicationTLS struct {
+	CertFile      string
+	KeyFile       string
+	ACME          bool
+	ACMEDirectory string
+	ACMEEmail     string
+	RedirectHTTP  bool
+}
+
 type Application struct {
 	AuthResource string
 	Host         []string
 	Login        []LoginBundle
+	TLS          *ApplicationTLS
 }
 
 // ResourceFile represents a donwloadable ll
@@ -65,66 +79,27 @@ type ServiceConfiguration struct {
 	Name        string
 	Application []Application
 	Resource    []Resource
+	Role        []Role
 	Files       []*ResourceFile
 }
 
+// NewSCReader reads the service configuration file p.
 func NewSCReader(p string) (*SCReader, error) {
-	pabs, err := filepath.Abs(p)
-	if err != nil {
-		return nil, fmt.Errorf("unable to get ABS file path of %q: %v", p, err)
-	}
-	dir, _ := filepath.Split(pabs)
-
-	ch := make(chan *moddwatch.Mod, 6)
-	watcher, err := moddwatch.Watch(dir, []string{"**/*.*"}, nil, time.Millisecond*500, ch)
+	cr, err := NewConfigReader(p)
 	if err != nil {
 		return nil, err
 	}
-
-	r := &SCReader{
-		vm:         jsonnet.MakeVM(),
-		configPath: pabs,
-		dir:        dir,
-		watcher:    watcher,
-		changes:    ch,
-	}
-	r.vm.Importer(&jsonnet.FileImporter{JPaths: []string{dir}})
-
-	return r, nil
+	return &SCReader{ConfigReader: cr}, nil
 }
 
+// SCReader reads a service configuration into a ServiceBundle.
 type SCReader struct {
-	ctx context.Context
-	vm  *jsonnet.VM
-
-	configPath string
-	dir        string
-
-	watcher *moddwatch.Watcher
-	changes chan *moddwatch.Mod
-}
-
-func (r *SCReader) Close() {
-	r.watcher.Stop()
-	return
+	*ConfigReader
 }
 
 func (r *SCReader) open() (*api.ServiceBundle, map[string]*ResourceFile, error) {
-	bfile, err := ioutil.ReadFile(r.configPath)
-	if err != nil {
-		return nil, nil, err
-	}
-	v, err := r.vm.EvaluateSnippet(r.configPath, string(bfile))
-	if err != nil {
-		return nil, nil, fmt.Errorf("jsonnet eval %q: %v", r.configPath, err)
-	}
-
-	decode := json.NewDecoder(strings.NewReader(v))
-	decode.DisallowUnknownFields()
-	decode.UseNumber()
-
 	sc := ServiceConfiguration{}
-	err = decode.Decode(&sc)
+	err := r.Decode(&sc)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -132,6 +107,10 @@ func (r *SCReader) open() (*api.ServiceBundle, map[string]*ResourceFile, error)
 		Name:        sc.Name,
 		Application: make([]*api.ApplicationBundle, 0, len(sc.Application)),
 		Resource:    make([]*api.Resource, 0, len(sc.Resource)),
+		Role:        make([]*api.Role, 0, len(sc.Role)),
+	}
+	for _, rc := range sc.Role {
+		sb.Role = append(sb.Role, &api.Role{ID: rc.ID, Name: rc.Name})
 	}
 	// Copy over Application and Resource.
 	for _, rc := range sc.Resource {
@@ -141,6 +120,8 @@ func (r *SCReader) open() (*api.ServiceBundle, map[string]*ResourceFile, error)
 			Include: rc.Include,
 			Type:    rc.Type,
 			Consume: rc.Consume,
+			Role:    rc.Role,
+			Elevate: rc.Elevate,
 		}
 
 		c := rc.C
@@ -170,6 +151,9 @@ func (r *SCReader) open() (*api.ServiceBundle, map[string]*ResourceFile, error)
 		case "url":
 			o := &api.ConfigureURL{}
 			o.MapTo = c["MapTo"].(string)
+			if stream, ok := c["Stream"].(bool); ok {
+				o.Stream = stream
+			}
 			switch cc := c["Config"].(type) {
 			case map[string]interface{}:
 				cbyte, err := json.Marshal(cc)
@@ -199,6 +183,16 @@ func (r *SCReader) open() (*api.ServiceBundle, map[string]*ResourceFile, error)
 			LoginBundle: make([]*api.LoginBundle, 0, len(ac.Login)),
 		}
 		sb.Application = append(sb.Application, a)
+		if t := ac.TLS; t != nil {
+			a.TLS = &api.ApplicationTLS{
+				CertFile:      t.CertFile,
+				KeyFile:       t.KeyFile,
+				ACME:          t.ACME,
+				ACMEDirectory: t.ACMEDirectory,
+				ACMEEmail:     t.ACMEEmail,
+				RedirectHTTP:  t.RedirectHTTP,
+			}
+		}
 
 		for _, lc := range ac.Login {
 			l := &api.LoginBundle{
@@ -221,7 +215,7 @@ func (r *SCReader) open() (*api.ServiceBundle, map[string]*ResourceFile, error)
 		if len(f.Content) > 0 {
 			continue
 		}
-		b, err := ioutil.ReadFile(filepath.Join(r.dir, f.File))
+		b, err := ioutil.ReadFile(filepath.Join(r.Dir(), f.File))
 		if err != nil {
 			return nil, nil, fmt.Errorf("unable to read %q: %v", f.Name, err)
 		}
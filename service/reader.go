@@ -0,0 +1,97 @@
+// Copyright 2018 The Solid Core Data Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cortesi/moddwatch"
+	"github.com/google/go-jsonnet"
+)
+
+// ConfigReader evaluates a jsonnet configuration file and reports when
+// files in its directory change. Imports are resolved from the same
+// directory.
+type ConfigReader struct {
+	vm *jsonnet.VM
+
+	configPath string
+	dir        string
+
+	watcher *moddwatch.Watcher
+
+	// Changes receives a value when a file in the directory changes.
+	Changes chan *moddwatch.Mod
+}
+
+// NewConfigReader watches the directory of the configuration file p.
+func NewConfigReader(p string) (*ConfigReader, error) {
+	pabs, err := filepath.Abs(p)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get ABS file path of %q: %v", p, err)
+	}
+	dir, _ := filepath.Split(pabs)
+
+	ch := make(chan *moddwatch.Mod, 6)
+	watcher, err := moddwatch.Watch(dir, []string{"**/*.*"}, nil, time.Millisecond*500, ch)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &ConfigReader{
+		vm:         jsonnet.MakeVM(),
+		configPath: pabs,
+		dir:        dir,
+		watcher:    watcher,
+		Changes:    ch,
+	}
+	r.vm.Importer(&jsonnet.FileImporter{JPaths: []string{dir}})
+
+	return r, nil
+}
+
+// Close stops watching the directory.
+func (r *ConfigReader) Close() {
+	r.watcher.Stop()
+}
+
+// Path is the absolute path of the configuration file.
+func (r *ConfigReader) Path() string {
+	return r.configPath
+}
+
+// Dir is the directory of the configuration file.
+func (r *ConfigReader) Dir() string {
+	return r.dir
+}
+
+// Decode evaluates the configuration file and decodes the result into v.
+// Unknown fields are an error and numbers decoded into an interface
+// are a json.Number.
+func (r *ConfigReader) Decode(v interface{}) error {
+	bfile, err := ioutil.ReadFile(r.configPath)
+	if err != nil {
+		return err
+	}
+	out, err := r.vm.EvaluateSnippet(r.configPath, string(bfile))
+	if err != nil {
+		return fmt.Errorf("jsonnet eval %q: %v", r.configPath, err)
+	}
+
+	decode := json.NewDecoder(strings.NewReader(out))
+	decode.DisallowUnknownFields()
+	decode.UseNumber()
+
+	err = decode.Decode(v)
+	if err != nil {
+		return fmt.Errorf("decode %q: %v", r.configPath, err)
+	}
+	return nil
+}
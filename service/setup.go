@@ -12,9 +12,11 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/solidcoredata/scd/api"
@@ -53,6 +55,19 @@ type Configration interface {
 	BundleUpdate(*api.ServiceBundle)
 }
 
+// Initer is optionally implemented by a Configration to finish setting up
+// after the command line flags are parsed, before the service starts.
+type Initer interface {
+	Init(ctx context.Context) error
+}
+
+// Stopper is optionally implemented by a Configration that serves long
+// running streams. Stop is called when the service shuts down so the
+// streams end before the server stops gracefully.
+type Stopper interface {
+	Stop()
+}
+
 type RemoteService struct {
 	Conn    *grpc.ClientConn
 	Address string
@@ -86,10 +101,19 @@ func New() *Service {
 
 func (s *Service) Setup(ctx context.Context, sc Configration) {
 	var bindAddress, routerAddress string
+	var shutdownTimeout time.Duration
 	flag.StringVar(&bindAddress, "bind", "localhost:0", "address and port to bind to")
 	flag.StringVar(&routerAddress, "router", "", "optionally notify specified router")
+	flag.DurationVar(&shutdownTimeout, "shutdown-timeout", time.Second*30, "time to finish open requests when stopped")
 	flag.Parse()
 
+	if i, is := sc.(Initer); is {
+		err := i.Init(ctx)
+		if err != nil {
+			onErrf(printMessage, "unable to initialize service: %v", err)
+		}
+	}
+
 	server := grpc.NewServer()
 	r, err := newRoutes(ctx, sc)
 	if err != nil {
@@ -115,8 +139,12 @@ func (s *Service) Setup(ctx context.Context, sc Configration) {
 		onErrf(printMessage, `unable to listen on %q: %v`, bindAddress, err)
 	}
 	defer l.Close()
+
+	registerCtx, registerCancel := context.WithCancel(ctx)
+	defer registerCancel()
+	serviceAddress := ""
 	if len(routerAddress) > 0 {
-		serviceAddress, err := resolveServiceAddress(l.Addr(), routerAddress)
+		serviceAddress, err = resolveServiceAddress(l.Addr(), routerAddress)
 		fmt.Printf("address=%s\n", serviceAddress)
 		if err != nil {
 			// Error with an exit because this service won't register
@@ -124,13 +152,64 @@ func (s *Service) Setup(ctx context.Context, sc Configration) {
 			// without some type of fatal configuration error.
 			onErrf(printMessage, `unable to register with router %v`, err)
 		}
-		go registerOnRouter(ctx, routerAddress, serviceAddress)
+		go registerOnRouter(registerCtx, routerAddress, serviceAddress)
 	}
 
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		select {
+		case <-sig:
+		case <-ctx.Done():
+		}
+		go func() {
+			<-sig
+			onErr(printMessage, "service: forced stop")
+		}()
+		fmt.Println("shutting down")
+
+		sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		// Remove the service from the router before closing the listener
+		// so the router stops sending new requests first.
+		registerCancel()
+		if len(routerAddress) > 0 {
+			err := unregisterOnRouter(sctx, routerAddress, serviceAddress)
+			if err != nil {
+				fmt.Printf("unable to remove from router: %v\n", err)
+			}
+		}
+		r.stop()
+		if st, is := sc.(Stopper); is {
+			st.Stop()
+		}
+		gracefulStop(sctx, server)
+	}()
+
 	err = server.Serve(l)
 	if err != nil {
 		onErrf(printMessage, `failed to serve on %q: %v`, bindAddress, err)
 	}
+	<-stopped
+}
+
+// gracefulStop stops the server after open requests finish, or closes
+// them when ctx is done.
+func gracefulStop(ctx context.Context, server *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		server.Stop()
+	}
 }
 
 // resolveServiceAddress attempts to return the routable IP:port address
@@ -184,6 +263,19 @@ func resolveServiceAddress(laddr net.Addr, routerAddress string) (string, error)
 	return "", fmt.Errorf("service: unable to find external service address for %q", laddr.String())
 }
 
+// unregisterOnRouter asks the router at routerAddress to stop routing requests
+// to this service at serviceAddress.
+func unregisterOnRouter(ctx context.Context, routerAddress, serviceAddress string) error {
+	conn, err := grpc.DialContext(ctx, routerAddress, grpc.WithInsecure())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	client := api.NewRouterConfigurationClient(conn)
+	_, err = client.Notify(ctx, &api.NotifyReq{ServiceAddress: serviceAddress, Remove: true})
+	return err
+}
+
 // registerOnRouter informs the router at routerAddress this service at serviceAddress.
 func registerOnRouter(ctx context.Context, routerAddress, serviceAddress string) {
 	conn, err := grpc.DialContext(ctx, routerAddress, grpc.WithInsecure(), grpc.WithBackoffConfig(grpc.BackoffConfig{MaxDelay: time.Second * 5}))
@@ -234,6 +326,11 @@ type routesService struct {
 	bundle chan *api.ServiceBundle
 	config chan *api.ServiceConfig
 
+	// stopped is closed when the service is shutting down to end
+	// long running streams.
+	stopped  chan struct{}
+	stopOnce sync.Once
+
 	setupLock    sync.RWMutex
 	setupVersion map[string]*setup
 	conns        map[string]*grpc.ClientConn // All rpc connections created the server.
@@ -245,6 +342,7 @@ func newRoutes(ctx context.Context, sc Configration) (*routesService, error) {
 
 		bundle:       make(chan *api.ServiceBundle, 5),
 		config:       make(chan *api.ServiceConfig, 5),
+		stopped:      make(chan struct{}),
 		setupVersion: make(map[string]*setup, 7),
 		conns:        make(map[string]*grpc.ClientConn, 7),
 	}
@@ -259,7 +357,7 @@ func newRoutes(ctx context.Context, sc Configration) (*routesService, error) {
 	if err != nil {
 		return nil, err
 	}
-	scr.changes <- nil
+	scr.Changes <- nil
 
 	go r.run(ctx, scr)
 	return r, nil
@@ -268,7 +366,7 @@ func newRoutes(ctx context.Context, sc Configration) (*routesService, error) {
 func (r *routesService) run(ctx context.Context, scr *SCReader) {
 	for {
 		select {
-		case <-scr.changes:
+		case <-scr.Changes:
 			sb, spa, err := scr.open()
 			if err != nil {
 				fmt.Printf("failed to read file config: %v\n", err)
@@ -345,6 +443,13 @@ func (r *routesService) run(ctx context.Context, scr *SCReader) {
 	}
 }
 
+// stop ends the service bundle streams so the server can stop gracefully.
+func (r *routesService) stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopped)
+	})
+}
+
 // Update connected services information, such as other service locations and SPA code and configs.
 func (r *routesService) UpdateServiceBundle(arg0 *google_protobuf1.Empty, server api.Routes_UpdateServiceBundleServer) error {
 	// TODO(kardianos): This will only work for a single service connected currently.
@@ -362,6 +467,8 @@ func (r *routesService) UpdateServiceBundle(arg0 *google_protobuf1.Empty, server
 			}
 		case <-server.Context().Done():
 			return grpc.ErrServerStopped
+		case <-r.stopped:
+			return nil
 		}
 	}
 }